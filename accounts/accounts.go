@@ -25,7 +25,6 @@ import (
 	"github.com/XunleiBlockchain/tc-libs/crypto"
 	"github.com/XunleiBlockchain/tc-libs/event"
 	"github.com/XunleiBlockchain/tc-libs/types"
-	"golang.org/x/crypto/sha3"
 )
 
 // Account represents an Ethereum account located at a specific location defined
@@ -146,7 +145,8 @@ type Backend interface {
 // safely used to calculate a signature from.
 //
 // The hash is calulcated as
-//   keccak256("\x19Ethereum Signed Message:\n"${message length}${message}).
+//   hash("\x19Ethereum Signed Message:\n"${message length}${message})
+// with the local hash suite, keccak256 by default.
 //
 // This gives context to the signed message and prevents signing of transactions.
 func TextHash(data []byte) []byte {
@@ -158,12 +158,13 @@ func TextHash(data []byte) []byte {
 // safely used to calculate a signature from.
 //
 // The hash is calulcated as
-//   keccak256("\x19Ethereum Signed Message:\n"${message length}${message}).
+//   hash("\x19Ethereum Signed Message:\n"${message length}${message})
+// with the local hash suite, keccak256 by default.
 //
 // This gives context to the signed message and prevents signing of transactions.
 func TextAndHash(data []byte) ([]byte, string) {
 	msg := fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(data), string(data))
-	hasher := sha3.NewLegacyKeccak256()
+	hasher := crypto.LocalHashSuite().New()
 	hasher.Write([]byte(msg))
 	return hasher.Sum(nil), msg
 }
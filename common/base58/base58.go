@@ -0,0 +1,106 @@
+// Package base58 implements the base58 and base58check encodings used by
+// bitcoin, for addresses and extended keys.
+package base58
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	// ErrInvalidCharacter is returned when the input has a character out of
+	// the base58 alphabet.
+	ErrInvalidCharacter = errors.New("base58: invalid character")
+	// ErrChecksum is returned when the checksum of a base58check input does
+	// not match.
+	ErrChecksum = errors.New("base58: invalid checksum")
+
+	bigRadix = big.NewInt(58)
+	indexes  [256]int8
+)
+
+func init() {
+	for i := range indexes {
+		indexes[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		indexes[alphabet[i]] = int8(i)
+	}
+}
+
+// Encode returns the base58 encoding of b, each leading zero byte is
+// encoded as a leading '1'.
+func Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Decode returns the bytes encoded by s.
+func Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		v := indexes[s[i]]
+		if v < 0 {
+			return nil, ErrInvalidCharacter
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
+
+func checksum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return h[:4]
+}
+
+// CheckEncode returns the base58 encoding of b followed by the first four
+// bytes of its double SHA256.
+func CheckEncode(b []byte) string {
+	buf := make([]byte, 0, len(b)+4)
+	buf = append(buf, b...)
+	buf = append(buf, checksum(b)...)
+	return Encode(buf)
+}
+
+// CheckDecode decodes a base58check string and verifies its checksum.
+func CheckDecode(s string) ([]byte, error) {
+	buf, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 4 {
+		return nil, ErrChecksum
+	}
+	payload := buf[:len(buf)-4]
+	sum := checksum(payload)
+	for i := range sum {
+		if sum[i] != buf[len(payload)+i] {
+			return nil, ErrChecksum
+		}
+	}
+	return payload, nil
+}
@@ -0,0 +1,55 @@
+package base58
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		hex, encoded string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+	}
+	for _, test := range tests {
+		b, err := hex.DecodeString(test.hex)
+		require.Nil(t, err)
+		assert.Equal(t, test.encoded, Encode(b))
+		decoded, err := Decode(test.encoded)
+		require.Nil(t, err)
+		assert.Equal(t, test.hex, hex.EncodeToString(decoded))
+	}
+
+	_, err := Decode("0OIl")
+	assert.Equal(t, ErrInvalidCharacter, err)
+}
+
+func TestCheckEncodeDecode(t *testing.T) {
+	payload, err := hex.DecodeString("00f54a5851e9372b87810a8e60cdd2e7cfd80b6e31")
+	require.Nil(t, err)
+	encoded := CheckEncode(payload)
+	assert.Equal(t, "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs", encoded)
+
+	decoded, err := CheckDecode(encoded)
+	require.Nil(t, err)
+	assert.Equal(t, payload, decoded)
+
+	_, err = CheckDecode("1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAt")
+	assert.Equal(t, ErrChecksum, err)
+	_, err = CheckDecode("1")
+	assert.Equal(t, ErrChecksum, err)
+}
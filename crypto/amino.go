@@ -16,17 +16,18 @@ func init() {
 // RegisterAmino registers all crypto related types in the given (amino) codec.
 func RegisterAmino() {
 	bal.RegisterInterface((*PubKey)(nil), nil)
-	bal.RegisterConcrete(PubKeyEd25519{}, "PubKeyEd25519", nil)
-	bal.RegisterConcrete(&PubKeySecp256k1{}, "PubKeySecp256k1", nil)
-	bal.RegisterConcrete(&PubKeyGM{}, "PubKeyGM", nil)
-
 	bal.RegisterInterface((*PrivKey)(nil), nil)
-	bal.RegisterConcrete(PrivKeyEd25519{}, "PrivKeyEd25519", nil)
-	bal.RegisterConcrete(&PrivKeySecp256k1{}, "PrivKeySecp256k1", nil)
-	bal.RegisterConcrete(&PrivKeyGM{}, "PrivKeyGM", nil)
-
 	bal.RegisterInterface((*Signature)(nil), nil)
-	bal.RegisterConcrete(SignatureEd25519{}, "SignEd25519", nil)
-	bal.RegisterConcrete(SignatureSecp256k1{}, "SignSecp256k1", nil)
-	bal.RegisterConcrete(SignatureGM{}, "SignGM", nil)
+
+	// Builtin algorithms, their concrete types are registered by RegisterAlgorithm.
+	RegisterAlgorithm(algorithmEd25519)
+	RegisterAlgorithm(algorithmSecp256K1)
+	RegisterAlgorithm(algorithmGM)
+	RegisterAlgorithm(algorithmP256)
+	RegisterAlgorithm(algorithmBLS)
+	RegisterAlgorithm(algorithmRistretto)
+
+	// Multisig keys have no private key of their own.
+	bal.RegisterConcrete(&PubKeyMultisigThreshold{}, "PubKeyMultisigThreshold", nil)
+	bal.RegisterConcrete(&Multisignature{}, "Multisignature", nil)
 }
@@ -4,8 +4,7 @@ import (
 	"crypto/elliptic"
 	"errors"
 	"fmt"
-
-	"github.com/tjfoc/gmsm/sm2"
+	"math/big"
 
 	"github.com/XunleiBlockchain/tc-libs/common"
 	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
@@ -15,6 +14,9 @@ const (
 	CryptoTypeGM        = "gm"
 	CryptoTypeEd25519   = "ed25519"
 	CryptoTypeSecp256K1 = "secp256k1"
+	CryptoTypeP256      = "p256"
+	CryptoTypeBLS       = "bls"
+	CryptoTypeRistretto = "ristretto"
 )
 
 var (
@@ -33,7 +35,7 @@ var (
 
 // SetLocalAccountType --
 func SetLocalAccountType(t string) {
-	if t == CryptoTypeGM || t == CryptoTypeSecp256K1 {
+	if algo := algorithmByName(t); algo != nil && algo.Account {
 		localAccountType = t
 		return
 	}
@@ -45,7 +47,7 @@ func LocalAccountType() string { return localAccountType }
 
 // SetLocalNodeType --
 func SetLocalNodeType(t string) {
-	if t == CryptoTypeEd25519 {
+	if algo := algorithmByName(t); algo != nil && algo.Node {
 		localNodeType = t
 		return
 	}
@@ -63,22 +65,22 @@ func S256() elliptic.Curve {
 
 // GenerateAccountKey --
 func GenerateAccountKey() (PrivKey, error) {
-	plugin := cryptos[LocalAccountType()]
-	if plugin == nil {
+	algo := algorithmByName(LocalAccountType())
+	if algo == nil {
 		return nil, ErrInvalidCryptoType
 	}
 
-	return plugin.GenerateKey()
+	return algo.GenerateKey()
 }
 
 // GenerateNodeKey --
 func GenerateNodeKey() (PrivKey, error) {
-	plugin := cryptos[LocalNodeType()]
-	if plugin == nil {
+	algo := algorithmByName(LocalNodeType())
+	if algo == nil {
 		return nil, ErrInvalidCryptoType
 	}
 
-	return plugin.GenerateKey()
+	return algo.GenerateKey()
 }
 
 // PubkeyToAddress --
@@ -88,38 +90,35 @@ func PubkeyToAddress(pubKey PubKey) common.Address {
 
 // GeneratePrivKeyFromSecret --
 func GeneratePrivKeyFromSecret(secret []byte, t string) (PrivKey, error) {
-	plugin := cryptos[t]
-	if plugin == nil {
+	algo := algorithmByName(t)
+	if algo == nil {
+		return nil, ErrInvalidCryptoType
+	}
+	return algo.GenerateKeyFromSecret(secret)
+}
+
+// PrivKeyFromRaw returns the private key of type t whose Raw method returns
+// raw, it is the inverse of Raw.
+func PrivKeyFromRaw(raw []byte, t string) (PrivKey, error) {
+	algo := algorithmByName(t)
+	if algo == nil {
 		return nil, ErrInvalidCryptoType
 	}
-	return plugin.GenerateKeyFromSecret(secret)
+	if algo.PrivKeyFromRaw == nil {
+		return nil, fmt.Errorf("%s does not support raw private keys", algo.Name)
+	}
+	return algo.PrivKeyFromRaw(append([]byte{}, raw...))
 }
 
 // VerifySignature checks that the given pubkey created signature over message.
-// The signature should be in [R || S || V] format.
+// The signature is dispatched to the registered algorithm that recognizes it,
+// e.g. [R || S || V] for secp256k1 and GM, [R || S] for ed25519.
 func VerifySignature(pubkey, hash, signature []byte) bool {
-	if len(signature) == 65 {
-		v := signature[64]
-		if v == 0 || v == 1 {
-			// The signature should be in [R || S] format.
-			return secp256k1.VerifySignature(pubkey, hash, signature[:64])
-		}
-
-		if v == SM2Magic || v == SM2Magic+1 {
-			pk := PubKeyGMFromBytes(pubkey)
-			sig, ok := NewSignatureGM(signature)
-			if !ok {
-				return false
-			}
-			return pk.VerifyBytes(hash, sig)
-		}
+	algo := algorithmBySignature(signature)
+	if algo == nil {
 		return false
 	}
-
-	sig := NewSignatureEd25519(signature)
-	pk := PubKeyEd25519{}
-	copy(pk[:], pubkey)
-	return pk.VerifyBytes(hash, sig)
+	return algo.Verify(pubkey, hash, signature)
 }
 
 // Ecrecover returns the uncompressed public key that created the given signature.
@@ -142,30 +141,22 @@ func Sender(hash, sig []byte) (common.Address, error) {
 
 // ------------------------------------------------
 
-func ecrecover(hash, sig []byte) (PubKey, error) {
-	// For GM
-	if sig[64] == SM2Magic || sig[64] == SM2Magic+1 {
-		r, s, v, ok := ParseSignatureGM(sig)
-		if !ok {
-			return nil, fmt.Errorf("ParseSignatureGM fail")
-		}
-		pk, ok := sm2.Ecrecover(hash, r, s, v)
-		if !ok {
-			return nil, fmt.Errorf("sm2.Recover fail")
-		}
-
-		pubKey := makePubKeyGM(pk)
-		return pubKey, nil
+// checkRawScalar checks that raw is a 32 bytes big endian scalar in [1, N-1].
+func checkRawScalar(raw []byte, N *big.Int) error {
+	k := new(big.Int).SetBytes(raw)
+	if len(raw) != 32 || k.Sign() == 0 || k.Cmp(N) >= 0 {
+		return errors.New("invalid raw private key")
 	}
+	return nil
+}
 
-	// For secp256k1
-	if sig[64] == 0 || sig[64] == 1 {
-		raw, err := secp256k1.RecoverPubkey(hash, sig)
-		if err != nil {
-			return nil, err
-		}
-		return &PubKeySecp256k1{Data: raw}, nil
+func ecrecover(hash, sig []byte) (PubKey, error) {
+	algo := algorithmBySignature(sig)
+	if algo == nil {
+		return nil, fmt.Errorf("unknown signature format, sig=%X", sig)
 	}
-
-	panic(fmt.Sprintf("Unknow V=%d, sig=%X", sig[64], sig))
+	if algo.Recover == nil {
+		return nil, fmt.Errorf("%s does not support public key recovery", algo.Name)
+	}
+	return algo.Recover(hash, sig)
 }
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"runtime"
+	"sync"
+)
+
+// BatchVerifier verifies many signatures at once. Ed25519 signatures are
+// checked together with a single multi-scalar multiplication, signatures of
+// the other algorithms are spread over a pool of workers.
+//
+//	bv := crypto.NewBatchVerifier()
+//	for _, vote := range votes {
+//	    bv.Add(vote.PubKey, vote.SignBytes, vote.Signature)
+//	}
+//	ok, results := bv.Verify()
+type BatchVerifier struct {
+	entries []batchEntry
+}
+
+type batchEntry struct {
+	pubKey PubKey
+	msg    []byte
+	sig    Signature
+}
+
+// NewBatchVerifier --
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add queues the verification of sig over msg by pubKey.
+func (bv *BatchVerifier) Add(pubKey PubKey, msg []byte, sig Signature) {
+	bv.entries = append(bv.entries, batchEntry{pubKey: pubKey, msg: msg, sig: sig})
+}
+
+// Len returns the number of queued signatures.
+func (bv *BatchVerifier) Len() int {
+	return len(bv.entries)
+}
+
+// Reset drops all queued signatures.
+func (bv *BatchVerifier) Reset() {
+	bv.entries = bv.entries[:0]
+}
+
+// Verify checks all queued signatures. It returns whether all of them are
+// valid, and the result of each one in the order they were added, which is
+// the result of its PubKey.VerifyBytes.
+func (bv *BatchVerifier) Verify() (bool, []bool) {
+	results := make([]bool, len(bv.entries))
+
+	var edIdx, otherIdx []int
+	for i, e := range bv.entries {
+		if e.pubKey == nil || e.sig == nil {
+			continue
+		}
+		_, edPub := e.pubKey.(PubKeyEd25519)
+		_, edSig := e.sig.(SignatureEd25519)
+		if edPub && edSig {
+			edIdx = append(edIdx, i)
+		} else {
+			otherIdx = append(otherIdx, i)
+		}
+	}
+
+	if len(edIdx) > 1 {
+		otherIdx = append(otherIdx, bv.verifyEd25519(edIdx, results)...)
+	} else {
+		otherIdx = append(otherIdx, edIdx...)
+	}
+	bv.verifyParallel(otherIdx, results)
+
+	for _, ok := range results {
+		if !ok {
+			return false, results
+		}
+	}
+	return true, results
+}
+
+// verifyEd25519 batch verifies the given ed25519 entries, it returns the
+// entries which must be verified one by one.
+func (bv *BatchVerifier) verifyEd25519(idx []int, results []bool) []int {
+	batch := make([]*ed25519BatchEntry, 0, len(idx))
+	batchIdx := make([]int, 0, len(idx))
+	var single []int
+	for _, i := range idx {
+		e := bv.entries[i]
+		be, ok := newEd25519BatchEntry(e.pubKey.(PubKeyEd25519), e.msg, e.sig.(SignatureEd25519))
+		if !ok {
+			single = append(single, i)
+			continue
+		}
+		batch = append(batch, be)
+		batchIdx = append(batchIdx, i)
+	}
+
+	if len(batch) > 0 && verifyEd25519Batch(batch) {
+		for _, i := range batchIdx {
+			results[i] = true
+		}
+		return single
+	}
+	// At least one signature is invalid, find out which.
+	return append(single, batchIdx...)
+}
+
+func (bv *BatchVerifier) verifyParallel(idx []int, results []bool) {
+	if len(idx) == 0 {
+		return
+	}
+
+	workers := runtime.NumCPU()
+	if workers > len(idx) {
+		workers = len(idx)
+	}
+	ch := make(chan int, len(idx))
+	for _, i := range idx {
+		ch <- i
+	}
+	close(ch)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				e := bv.entries[i]
+				results[i] = e.pubKey.VerifyBytes(e.msg, e.sig)
+			}
+		}()
+	}
+	wg.Wait()
+}
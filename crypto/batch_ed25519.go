@@ -0,0 +1,275 @@
+package crypto
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+)
+
+// Ed25519 batch verification checks
+//
+//     8 * (sum(z_i * R_i) + sum(z_i * k_i * A_i) - sum(z_i * s_i) * B) == 0
+//
+// for random 128 bits z_i, where k_i = SHA512(R_i || A_i || M_i).
+//
+// The single verification is not cofactored, so signatures whose R or A
+// carries a small order component could be accepted here and rejected by
+// VerifyBytes. Entries with an R or A out of the prime order subgroup
+// (l * P != 0), or a non canonical R or s are therefore left out of the batch
+// and checked one by one. For the remaining entries both equations agree, so
+// the batch gives the result of VerifyBytes for every signature.
+
+var (
+	edD      edwards25519.FieldElement
+	edSqrtM1 edwards25519.FieldElement
+	edBase   edwards25519.ExtendedPoint
+
+	// group order l = 2^252 + 27742317777372353535851937790883648493, little endian
+	edL = [32]byte{
+		0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+		0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	}
+)
+
+func init() {
+	// d = -121665/121666
+	d, _ := new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+	edD.SetBigInt(d)
+	// sqrt(-1) = 2^((p-1)/4)
+	sqrtM1, _ := new(big.Int).SetString("19681161376707505956807079304988542015446066515923890162744021073123829784752", 10)
+	edSqrtM1.SetBigInt(sqrtM1)
+	// The base point of edwards25519.SetBase is only the same ristretto
+	// point, it differs from the ed25519 one by a torsion point.
+	baseBuf := [32]byte{0x58}
+	for i := 1; i < 32; i++ {
+		baseBuf[i] = 0x66
+	}
+	decodeEdPoint(&edBase, &baseBuf)
+}
+
+type ed25519BatchEntry struct {
+	a, r edwards25519.ExtendedPoint
+	k, s ristretto.Scalar
+}
+
+// newEd25519BatchEntry decodes a signature for the batch, ok is false if it
+// must be verified alone.
+func newEd25519BatchEntry(pubKey PubKeyEd25519, msg []byte, sig SignatureEd25519) (*ed25519BatchEntry, bool) {
+	var e ed25519BatchEntry
+	var rBuf, sBuf [32]byte
+	copy(rBuf[:], sig[:32])
+	copy(sBuf[:], sig[32:])
+
+	if !scalarIsCanonical(&sBuf) {
+		return nil, false
+	}
+	if !decodeEdPoint(&e.a, (*[32]byte)(&pubKey)) || !decodeEdPoint(&e.r, &rBuf) {
+		return nil, false
+	}
+	if hasEdTorsion(&e.a) || hasEdTorsion(&e.r) {
+		return nil, false
+	}
+	// R is compared by its encoding in the single verification.
+	var enc [32]byte
+	e.r.Y.BytesInto(&enc)
+	enc[31] |= rBuf[31] & 0x80
+	if enc != rBuf {
+		return nil, false
+	}
+
+	var h [64]byte
+	d := sha512.New()
+	d.Write(rBuf[:])
+	d.Write(pubKey[:])
+	d.Write(msg)
+	d.Sum(h[:0])
+	e.k.SetReduced(&h)
+	e.s.SetBytes(&sBuf)
+	return &e, true
+}
+
+func verifyEd25519Batch(entries []*ed25519BatchEntry) bool {
+	points := make([]*edwards25519.ExtendedPoint, 0, 2*len(entries)+1)
+	scalars := make([]ristretto.Scalar, 2*len(entries)+1)
+
+	var sumS, z, zk ristretto.Scalar
+	var zBuf [32]byte
+	for i, e := range entries {
+		copy(zBuf[:16], CRandBytes(16))
+		z.SetBytes(&zBuf)
+
+		scalars[2*i].Set(&z)
+		points = append(points, &e.r)
+		zk.Mul(&z, &e.k)
+		scalars[2*i+1].Set(&zk)
+		points = append(points, &e.a)
+		sumS.MulAdd(&z, &e.s, &sumS)
+	}
+	scalars[2*len(entries)].Neg(&sumS)
+	points = append(points, &edBase)
+
+	var acc edwards25519.ExtendedPoint
+	edVarTimeMultiScalarMult(&acc, points, scalars)
+	acc.Double(&acc)
+	acc.Double(&acc)
+	acc.Double(&acc)
+	return isEdIdentity(&acc)
+}
+
+// edVarTimeMultiScalarMult sets p to sum(scalars[i] * points[i]) with the
+// interleaved width-5 NAF method (Straus).
+func edVarTimeMultiScalarMult(p *edwards25519.ExtendedPoint, points []*edwards25519.ExtendedPoint, scalars []ristretto.Scalar) {
+	nafs := make([][256]int8, len(points))
+	luts := make([][8]edwards25519.ExtendedPoint, len(points))
+	top := -1
+	for i := range points {
+		var buf [32]byte
+		scalars[i].BytesInto(&buf)
+		if t := computeNAF5(&buf, &nafs[i]); t > top {
+			top = t
+		}
+
+		// lut[j] = (2j+1) * P
+		var dbl edwards25519.ExtendedPoint
+		dbl.Double(points[i])
+		luts[i][0].Set(points[i])
+		for j := 1; j < 8; j++ {
+			luts[i][j].Add(&luts[i][j-1], &dbl)
+		}
+	}
+
+	p.SetZero()
+	for bit := top; bit >= 0; bit-- {
+		p.Double(p)
+		for i := range points {
+			n := nafs[i][bit]
+			if n > 0 {
+				p.Add(p, &luts[i][n/2])
+			} else if n < 0 {
+				p.Sub(p, &luts[i][-n/2])
+			}
+		}
+	}
+}
+
+// computeNAF5 computes the width-5 NAF of the little endian scalar s < 2^253,
+// it returns the index of the highest non zero digit or -1.
+func computeNAF5(s *[32]byte, naf *[256]int8) int {
+	var x [5]uint64
+	for i := 0; i < 4; i++ {
+		x[i] = binary.LittleEndian.Uint64(s[i*8:])
+	}
+
+	top := -1
+	carry := uint64(0)
+	for pos := 0; pos < 256; {
+		idx := pos / 64
+		bit := uint(pos % 64)
+		var buf uint64
+		if bit < 59 {
+			buf = x[idx] >> bit
+		} else {
+			buf = (x[idx] >> bit) | (x[idx+1] << (64 - bit))
+		}
+
+		window := carry + (buf & 31)
+		if window&1 == 0 {
+			pos++
+			continue
+		}
+		if window < 16 {
+			carry = 0
+			naf[pos] = int8(window)
+		} else {
+			carry = 1
+			naf[pos] = int8(window) - 32
+		}
+		top = pos
+		pos += 5
+	}
+	return top
+}
+
+// decodeEdPoint decodes a point in the RFC 8032 format.
+func decodeEdPoint(p *edwards25519.ExtendedPoint, buf *[32]byte) bool {
+	var y, yy, u, v, v3, x, vxx, t edwards25519.FieldElement
+	var one edwards25519.FieldElement
+	one.SetOne()
+
+	sign := int32(buf[31] >> 7)
+	y.SetBytes(buf)
+
+	// x^2 = u / v = (y^2 - 1) / (d*y^2 + 1)
+	yy.Square(&y)
+	u.Sub(&yy, &one)
+	v.Mul(&yy, &edD)
+	v.Add(&v, &one)
+
+	// x = u * v^3 * (u * v^7)^((p-5)/8)
+	v3.Square(&v)
+	v3.Mul(&v3, &v)
+	t.Square(&v3)
+	t.Mul(&t, &v)
+	t.Mul(&t, &u)
+	t.Exp22523(&t)
+	x.Mul(&u, &v3)
+	x.Mul(&x, &t)
+
+	vxx.Square(&x)
+	vxx.Mul(&vxx, &v)
+	if !vxx.Equals(&u) {
+		t.Neg(&u)
+		if !vxx.Equals(&t) {
+			return false
+		}
+		x.Mul(&x, &edSqrtM1)
+	}
+
+	if x.IsNonZeroI() == 0 && sign == 1 {
+		return false
+	}
+	if x.IsNegativeI() != sign {
+		x.Neg(&x)
+	}
+
+	p.X.Set(&x)
+	p.Y.Set(&y)
+	p.Z.SetOne()
+	p.T.Mul(&x, &y)
+	return true
+}
+
+func isEdIdentity(p *edwards25519.ExtendedPoint) bool {
+	return p.X.IsNonZeroI() == 0 && p.Y.Equals(&p.Z)
+}
+
+func isSmallOrderEdPoint(p *edwards25519.ExtendedPoint) bool {
+	var q edwards25519.ExtendedPoint
+	q.Double(p)
+	q.Double(&q)
+	q.Double(&q)
+	return isEdIdentity(&q)
+}
+
+// hasEdTorsion reports whether p has a small order component, that is
+// l * p != 0.
+func hasEdTorsion(p *edwards25519.ExtendedPoint) bool {
+	var q edwards25519.ExtendedPoint
+	q.VarTimeScalarMult(p, &edL)
+	return !isEdIdentity(&q)
+}
+
+// scalarIsCanonical reports whether the little endian s is lower than l.
+func scalarIsCanonical(s *[32]byte) bool {
+	for i := 31; i >= 0; i-- {
+		if s[i] != edL[i] {
+			return s[i] < edL[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package crypto
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"testing"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func genBatch(t testing.TB, algo string, n int) (pubKeys []PubKey, msgs [][]byte, sigs []Signature) {
+	for i := 0; i < n; i++ {
+		privKey, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+		msg := Keccak256([]byte(fmt.Sprintf("%s message %d", algo, i)))
+		sig, err := privKey.Sign(msg)
+		require.Nil(t, err)
+
+		pubKeys = append(pubKeys, privKey.PubKey())
+		msgs = append(msgs, msg)
+		sigs = append(sigs, sig)
+	}
+	return
+}
+
+func TestBatchVerifierEd25519(t *testing.T) {
+	pubKeys, msgs, sigs := genBatch(t, CryptoTypeEd25519, 32)
+
+	bv := NewBatchVerifier()
+	for i := range pubKeys {
+		bv.Add(pubKeys[i], msgs[i], sigs[i])
+	}
+	ok, results := bv.Verify()
+	assert.True(t, ok)
+	assert.Len(t, results, 32)
+
+	// Corrupt a signature and a message.
+	sig := sigs[3].(SignatureEd25519)
+	sig[40] ^= 0x01
+	sigs[3] = sig
+	msgs[17] = Keccak256(msgs[17])
+
+	bv.Reset()
+	for i := range pubKeys {
+		bv.Add(pubKeys[i], msgs[i], sigs[i])
+	}
+	ok, results = bv.Verify()
+	assert.False(t, ok)
+	for i := range results {
+		assert.Equal(t, i != 3 && i != 17, results[i], "entry %d", i)
+	}
+}
+
+func TestBatchVerifierEd25519NonCanonical(t *testing.T) {
+	pubKeys, msgs, sigs := genBatch(t, CryptoTypeEd25519, 4)
+
+	// s + l is rejected by the single verification.
+	sig := sigs[1].(SignatureEd25519)
+	var carry uint16
+	for i := 0; i < 32; i++ {
+		v := uint16(sig[32+i]) + uint16(edL[i]) + carry
+		sig[32+i] = byte(v)
+		carry = v >> 8
+	}
+	sigs[1] = sig
+
+	// Small order public key.
+	var smallOrder PubKeyEd25519
+	smallOrder[0] = 1
+	pubKeys[2] = smallOrder
+
+	bv := NewBatchVerifier()
+	for i := range pubKeys {
+		bv.Add(pubKeys[i], msgs[i], sigs[i])
+	}
+	ok, results := bv.Verify()
+	assert.False(t, ok)
+	for i := range results {
+		assert.Equal(t, pubKeys[i].VerifyBytes(msgs[i], sigs[i]), results[i], "entry %d", i)
+	}
+	assert.True(t, results[0])
+	assert.True(t, results[3])
+}
+
+func TestBatchVerifierEd25519Torsion(t *testing.T) {
+	pubKeys, msgs, sigs := genBatch(t, CryptoTypeEd25519, 4)
+
+	// R = r*B + T with T of order 2 and s = r + k*a: the cofactored batch
+	// equation holds, the single verification does not.
+	privKey, err := GenPrivKeyEd25519()
+	require.Nil(t, err)
+	digest := sha512.Sum512(privKey[:32])
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+	var a, r, k, s ristretto.Scalar
+	reduceScalar(&a, digest[:32])
+	r.Rand()
+
+	torsionBuf := [32]byte{0xec}
+	for i := 1; i < 31; i++ {
+		torsionBuf[i] = 0xff
+	}
+	torsionBuf[31] = 0x7f
+	var torsion, R edwards25519.ExtendedPoint
+	require.True(t, decodeEdPoint(&torsion, &torsionBuf))
+	var rBuf [32]byte
+	r.BytesInto(&rBuf)
+	R.VarTimeScalarMult(&edBase, &rBuf)
+	R.Add(&R, &torsion)
+
+	pubKey := privKey.PubKey().(PubKeyEd25519)
+	var sig SignatureEd25519
+	var encR [32]byte
+	encodeEdPoint(&encR, &R)
+	copy(sig[:32], encR[:])
+	var h [64]byte
+	d := sha512.New()
+	d.Write(encR[:])
+	d.Write(pubKey[:])
+	d.Write(msgs[0])
+	d.Sum(h[:0])
+	k.SetReduced(&h)
+	s.MulAdd(&k, &a, &r)
+	var sBuf [32]byte
+	s.BytesInto(&sBuf)
+	copy(sig[32:], sBuf[:])
+	require.False(t, pubKey.VerifyBytes(msgs[0], sig))
+	pubKeys[1], msgs[1], sigs[1] = pubKey, msgs[0], sig
+
+	bv := NewBatchVerifier()
+	for i := range pubKeys {
+		bv.Add(pubKeys[i], msgs[i], sigs[i])
+	}
+	ok, results := bv.Verify()
+	assert.False(t, ok)
+	assert.Equal(t, []bool{true, false, true, true}, results)
+}
+
+func TestBatchVerifierMixed(t *testing.T) {
+	bv := NewBatchVerifier()
+	var expect []bool
+	for _, algo := range []string{CryptoTypeEd25519, CryptoTypeSecp256K1, CryptoTypeGM, CryptoTypeP256} {
+		pubKeys, msgs, sigs := genBatch(t, algo, 6)
+		for i := range pubKeys {
+			msg := msgs[i]
+			if i == 2 {
+				msg = msgs[0]
+			}
+			bv.Add(pubKeys[i], msg, sigs[i])
+			expect = append(expect, i != 2)
+		}
+	}
+	ok, results := bv.Verify()
+	assert.False(t, ok)
+	assert.Equal(t, expect, results)
+
+	ok, results = NewBatchVerifier().Verify()
+	assert.True(t, ok)
+	assert.Len(t, results, 0)
+}
+
+func benchmarkBatchVerifier(b *testing.B, algo string, n int) {
+	pubKeys, msgs, sigs := genBatch(b, algo, n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv := NewBatchVerifier()
+		for j := range pubKeys {
+			bv.Add(pubKeys[j], msgs[j], sigs[j])
+		}
+		if ok, _ := bv.Verify(); !ok {
+			b.Fatal("verify error")
+		}
+	}
+}
+
+func benchmarkVerifySignatureLoop(b *testing.B, algo string, n int) {
+	pubKeys, msgs, sigs := genBatch(b, algo, n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range pubKeys {
+			if !VerifySignature(pubKeys[j].Raw(), msgs[j], sigs[j].Raw()) {
+				b.Fatal("verify error")
+			}
+		}
+	}
+}
+
+func BenchmarkBatchVerifierEd25519(b *testing.B) { benchmarkBatchVerifier(b, CryptoTypeEd25519, 256) }
+func BenchmarkBatchVerifierSecp256k1(b *testing.B) {
+	benchmarkBatchVerifier(b, CryptoTypeSecp256K1, 256)
+}
+func BenchmarkBatchVerifierGM(b *testing.B) { benchmarkBatchVerifier(b, CryptoTypeGM, 256) }
+
+func BenchmarkVerifySignatureLoopEd25519(b *testing.B) {
+	benchmarkVerifySignatureLoop(b, CryptoTypeEd25519, 256)
+}
+func BenchmarkVerifySignatureLoopSecp256k1(b *testing.B) {
+	benchmarkVerifySignatureLoop(b, CryptoTypeSecp256K1, 256)
+}
+func BenchmarkVerifySignatureLoopGM(b *testing.B) {
+	benchmarkVerifySignatureLoop(b, CryptoTypeGM, 256)
+}
+
+func TestVerifyEd25519Batch(t *testing.T) {
+	pubKeys, msgs, sigs := genBatch(t, CryptoTypeEd25519, 64)
+	entries := make([]*ed25519BatchEntry, len(pubKeys))
+	for i := range pubKeys {
+		e, ok := newEd25519BatchEntry(pubKeys[i].(PubKeyEd25519), msgs[i], sigs[i].(SignatureEd25519))
+		require.True(t, ok, "entry %d", i)
+		entries[i] = e
+	}
+	assert.True(t, verifyEd25519Batch(entries))
+
+	entries[5], _ = newEd25519BatchEntry(pubKeys[5].(PubKeyEd25519), msgs[6], sigs[5].(SignatureEd25519))
+	assert.False(t, verifyEd25519Batch(entries))
+}
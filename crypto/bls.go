@@ -0,0 +1,445 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/common"
+	bn256 "github.com/XunleiBlockchain/tc-libs/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BLS signatures over the bn256 curve: public keys live in G2, signatures
+// and message hashes in G1, so a signature is checked with one multi-pairing.
+
+const (
+	PrivKeyBLSSize   = 32
+	PubKeyBLSSize    = 128
+	SignatureBLSSize = 64
+)
+
+var (
+	blsSigDomain = []byte("TC_BLS_SIG_BN256G1_SHA256_")
+	blsPopDomain = []byte("TC_BLS_POP_BN256G1_SHA256_")
+
+	blsOrderMinus1 = new(big.Int).Sub(bn256.Order, common.Big1)
+	g2Generator    = new(bn256.G2).ScalarBaseMult(common.Big1)
+
+	errBLSEmptyInput = errors.New("bls: empty input")
+)
+
+// hashToG1 maps msg onto G1 with the try-and-increment method.
+// bn256 G1 has a cofactor of 1, so every point on the curve is in G1.
+func hashToG1(domain, msg []byte) *bn256.G1 {
+	var ctr [4]byte
+	buf := make([]byte, 64)
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h := sha512.New()
+		h.Write(domain)
+		h.Write(ctr[:])
+		h.Write(msg)
+		digest := h.Sum(nil)
+
+		// y^2 = x^3 + 3
+		x := new(big.Int).SetBytes(digest)
+		x.Mod(x, bn256.P)
+		y2 := new(big.Int).Mul(x, x)
+		y2.Mul(y2, x)
+		y2.Add(y2, big.NewInt(3))
+		y2.Mod(y2, bn256.P)
+		y := new(big.Int).ModSqrt(y2, bn256.P)
+		if y == nil {
+			continue
+		}
+		if y.Bit(0) != uint(digest[63]&1) {
+			y.Sub(bn256.P, y)
+		}
+
+		for j := range buf {
+			buf[j] = 0
+		}
+		xb, yb := x.Bytes(), y.Bytes()
+		copy(buf[32-len(xb):32], xb)
+		copy(buf[64-len(yb):], yb)
+		p := new(bn256.G1)
+		if _, err := p.Unmarshal(buf); err != nil {
+			continue
+		}
+		return p
+	}
+}
+
+func isZeroBytes(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//-------------------------------------
+
+var _ PrivKey = PrivKeyBLS{}
+
+// PrivKeyBLS is a big-endian scalar modulo the bn256 group order.
+type PrivKeyBLS [PrivKeyBLSSize]byte
+
+// Type --
+func (privKey PrivKeyBLS) Type() string {
+	return CryptoTypeBLS
+}
+
+// Bytes --
+func (privKey PrivKeyBLS) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(privKey)
+}
+
+// Raw --
+func (privKey PrivKeyBLS) Raw() []byte {
+	return privKey[:]
+}
+
+func (privKey PrivKeyBLS) scalar() *big.Int {
+	return new(big.Int).SetBytes(privKey[:])
+}
+
+// Sign returns H(msg)*x, msg can be of any length.
+func (privKey PrivKeyBLS) Sign(msg []byte) (Signature, error) {
+	return privKey.sign(blsSigDomain, msg), nil
+}
+
+func (privKey PrivKeyBLS) sign(domain, msg []byte) SignatureBLS {
+	sig := new(bn256.G1).ScalarMult(hashToG1(domain, msg), privKey.scalar())
+	var ret SignatureBLS
+	copy(ret[:], sig.Marshal())
+	return ret
+}
+
+// ProvePossession signs the public key itself, the proof must be checked
+// with PubKeyBLS.VerifyPossession before the key takes part in an aggregate
+// of signatures over the same message.
+func (privKey PrivKeyBLS) ProvePossession() SignatureBLS {
+	pubKey := privKey.PubKey().(PubKeyBLS)
+	return privKey.sign(blsPopDomain, pubKey[:])
+}
+
+// PubKey --
+func (privKey PrivKeyBLS) PubKey() PubKey {
+	var pubKey PubKeyBLS
+	copy(pubKey[:], new(bn256.G2).ScalarBaseMult(privKey.scalar()).Marshal())
+	return pubKey
+}
+
+// Equals - runs in constant time based on length of the keys.
+func (privKey PrivKeyBLS) Equals(other PrivKey) bool {
+	if otherBLS, ok := other.(PrivKeyBLS); ok {
+		return subtle.ConstantTimeCompare(privKey[:], otherBLS[:]) == 1
+	}
+	return false
+}
+
+// Reset does nothing: PrivKeyBLS is an array and Reset zeroes a copy of it,
+// like for PrivKeyEd25519. Clear the key itself with *privKey = PrivKeyBLS{}.
+func (privKey PrivKeyBLS) Reset() {}
+
+func (privKey PrivKeyBLS) MarshalJSON() ([]byte, error) {
+	return serEncodeFroJSON(privKey)
+}
+
+func (privKey *PrivKeyBLS) UnmarshalJSON(input []byte) error {
+	return serDecodeForJSON(privKey, input)
+}
+
+func makePrivKeyBLS(seed []byte) PrivKeyBLS {
+	// x = seed mod (N-1) + 1, the seed is 64 bytes so the bias is negligible.
+	x := new(big.Int).SetBytes(seed)
+	x.Mod(x, blsOrderMinus1)
+	x.Add(x, common.Big1)
+
+	var privKey PrivKeyBLS
+	xb := x.Bytes()
+	copy(privKey[PrivKeyBLSSize-len(xb):], xb)
+	return privKey
+}
+
+func privKeyBLSFromRaw(raw []byte) (PrivKeyBLS, error) {
+	if err := checkRawScalar(raw, bn256.Order); err != nil {
+		return PrivKeyBLS{}, err
+	}
+	var privKey PrivKeyBLS
+	copy(privKey[:], raw)
+	return privKey, nil
+}
+
+// GenPrivKeyBLS --
+func GenPrivKeyBLS() (PrivKeyBLS, error) {
+	return makePrivKeyBLS(CRandBytes(64)), nil
+}
+
+// GenPrivKeyBLSFromSecret --
+// NOTE: secret should be the output of a KDF like bcrypt,
+// if it's derived from user input.
+func GenPrivKeyBLSFromSecret(secret []byte) (PrivKeyBLS, error) {
+	seed := sha512.Sum512(secret)
+	return makePrivKeyBLS(seed[:]), nil
+}
+
+//-------------------------------------
+
+var _ PubKey = PubKeyBLS{}
+
+// PubKeyBLS is a marshaled G2 point.
+type PubKeyBLS [PubKeyBLSSize]byte
+
+// Address is the Ripemd160 of the raw pubkey bytes.
+func (pubKey PubKeyBLS) Address() Address {
+	return Address(Ripemd160(pubKey[:]))
+}
+
+// Bytes --
+func (pubKey PubKeyBLS) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(pubKey)
+}
+
+// Raw --
+func (pubKey PubKeyBLS) Raw() []byte {
+	return pubKey[:]
+}
+
+// point decodes the public key, it rejects the identity and points outside of G2.
+func (pubKey PubKeyBLS) point() (*bn256.G2, error) {
+	if isZeroBytes(pubKey[:]) {
+		return nil, errors.New("bls: public key is infinity")
+	}
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(pubKey[:]); err != nil {
+		return nil, err
+	}
+	if !isZeroBytes(new(bn256.G2).ScalarMult(p, bn256.Order).Marshal()) {
+		return nil, errors.New("bls: public key is not in G2")
+	}
+	return p, nil
+}
+
+// VerifyBytes checks e(sig, g2) == e(H(msg), pubKey).
+func (pubKey PubKeyBLS) VerifyBytes(msg []byte, sig_ Signature) bool {
+	sig, ok := sig_.(SignatureBLS)
+	if !ok {
+		return false
+	}
+	return pubKey.verify(blsSigDomain, msg, sig)
+}
+
+// VerifyPossession checks a proof made by PrivKeyBLS.ProvePossession.
+func (pubKey PubKeyBLS) VerifyPossession(pop Signature) bool {
+	sig, ok := pop.(SignatureBLS)
+	if !ok {
+		return false
+	}
+	return pubKey.verify(blsPopDomain, pubKey[:], sig)
+}
+
+func (pubKey PubKeyBLS) verify(domain, msg []byte, sig SignatureBLS) bool {
+	pk, err := pubKey.point()
+	if err != nil {
+		return false
+	}
+	s, err := sig.point()
+	if err != nil {
+		return false
+	}
+	return bn256.PairingCheck(
+		[]*bn256.G1{new(bn256.G1).Neg(s), hashToG1(domain, msg)},
+		[]*bn256.G2{g2Generator, pk},
+	)
+}
+
+func (pubKey PubKeyBLS) String() string {
+	return fmt.Sprintf("PubKeyBLS{%v}", hexutil.Encode(pubKey[:]))
+}
+
+// Equals --
+func (pubKey PubKeyBLS) Equals(other PubKey) bool {
+	if otherBLS, ok := other.(PubKeyBLS); ok {
+		return bytes.Equal(pubKey[:], otherBLS[:])
+	}
+	return false
+}
+
+func (pubKey PubKeyBLS) MarshalJSON() ([]byte, error) {
+	return serEncodeFroJSON(pubKey)
+}
+
+func (pubKey *PubKeyBLS) UnmarshalJSON(input []byte) error {
+	return serDecodeForJSON(pubKey, input)
+}
+
+//-------------------------------------
+
+var _ Signature = SignatureBLS{}
+
+// SignatureBLS is a marshaled G1 point.
+type SignatureBLS [SignatureBLSSize]byte
+
+// Bytes --
+func (sig SignatureBLS) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(sig)
+}
+
+// Raw --
+func (sig SignatureBLS) Raw() []byte {
+	return sig[:]
+}
+
+// IsZero --
+func (sig SignatureBLS) IsZero() bool { return isZeroBytes(sig[:]) }
+
+func (sig SignatureBLS) String() string {
+	return fmt.Sprintf("/%X.../", common.Fingerprint(sig[:]))
+}
+
+// Equals --
+func (sig SignatureBLS) Equals(other Signature) bool {
+	if otherBLS, ok := other.(SignatureBLS); ok {
+		return subtle.ConstantTimeCompare(sig[:], otherBLS[:]) == 1
+	}
+	return false
+}
+
+func (sig SignatureBLS) point() (*bn256.G1, error) {
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(sig[:]); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewSignatureBLS --
+func NewSignatureBLS(data []byte) (Signature, bool) {
+	if len(data) != SignatureBLSSize {
+		return SignatureBLS{}, false
+	}
+	var sig SignatureBLS
+	copy(sig[:], data)
+	return sig, true
+}
+
+//-------------------------------------
+
+// AggregateSignatures adds up BLS signatures into a single one.
+func AggregateSignatures(sigs []Signature) (SignatureBLS, error) {
+	if len(sigs) == 0 {
+		return SignatureBLS{}, errBLSEmptyInput
+	}
+	var acc *bn256.G1
+	for i, sig := range sigs {
+		sigBLS, ok := sig.(SignatureBLS)
+		if !ok {
+			return SignatureBLS{}, fmt.Errorf("bls: signature %d is not a SignatureBLS", i)
+		}
+		p, err := sigBLS.point()
+		if err != nil {
+			return SignatureBLS{}, fmt.Errorf("bls: signature %d: %v", i, err)
+		}
+		if acc == nil {
+			acc = p
+		} else {
+			acc.Add(acc, p)
+		}
+	}
+	var ret SignatureBLS
+	copy(ret[:], acc.Marshal())
+	return ret, nil
+}
+
+// AggregatePubKeys adds up BLS public keys into a single one, which verifies
+// the aggregate of signatures of the same message.
+func AggregatePubKeys(pubKeys []PubKey) (PubKeyBLS, error) {
+	if len(pubKeys) == 0 {
+		return PubKeyBLS{}, errBLSEmptyInput
+	}
+	var acc *bn256.G2
+	for i, pubKey := range pubKeys {
+		pubKeyBLS, ok := pubKey.(PubKeyBLS)
+		if !ok {
+			return PubKeyBLS{}, fmt.Errorf("bls: public key %d is not a PubKeyBLS", i)
+		}
+		p, err := pubKeyBLS.point()
+		if err != nil {
+			return PubKeyBLS{}, fmt.Errorf("bls: public key %d: %v", i, err)
+		}
+		if acc == nil {
+			acc = p
+		} else {
+			acc.Add(acc, p)
+		}
+	}
+	var ret PubKeyBLS
+	copy(ret[:], acc.Marshal())
+	return ret, nil
+}
+
+// VerifyAggregate checks an aggregated signature with a single multi-pairing.
+//
+// If msgs holds one message, every key signed that same message. The keys
+// must then have been checked with VerifyPossession, otherwise a rogue key
+// can forge the aggregate.
+//
+// Otherwise msgs[i] is the message signed by pubKeys[i], and the messages
+// must be distinct.
+func VerifyAggregate(pubKeys []PubKey, msgs [][]byte, sig Signature) bool {
+	sigBLS, ok := sig.(SignatureBLS)
+	if !ok || len(pubKeys) == 0 {
+		return false
+	}
+
+	if len(msgs) == 1 {
+		pubKey, err := AggregatePubKeys(pubKeys)
+		if err != nil {
+			return false
+		}
+		return pubKey.verify(blsSigDomain, msgs[0], sigBLS)
+	}
+
+	if len(msgs) != len(pubKeys) {
+		return false
+	}
+	s, err := sigBLS.point()
+	if err != nil {
+		return false
+	}
+
+	seen := make(map[[sha256.Size]byte]struct{}, len(msgs))
+	g1s := make([]*bn256.G1, 0, len(msgs)+1)
+	g2s := make([]*bn256.G2, 0, len(msgs)+1)
+	g1s = append(g1s, new(bn256.G1).Neg(s))
+	g2s = append(g2s, g2Generator)
+	for i, msg := range msgs {
+		h := sha256.Sum256(msg)
+		if _, ok := seen[h]; ok {
+			return false
+		}
+		seen[h] = struct{}{}
+
+		pubKeyBLS, ok := pubKeys[i].(PubKeyBLS)
+		if !ok {
+			return false
+		}
+		pk, err := pubKeyBLS.point()
+		if err != nil {
+			return false
+		}
+		g1s = append(g1s, hashToG1(blsSigDomain, msg))
+		g2s = append(g2s, pk)
+	}
+	return bn256.PairingCheck(g1s, g2s)
+}
@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func genBLSKeys(t testing.TB, n int) ([]PrivKeyBLS, []PubKey) {
+	privKeys := make([]PrivKeyBLS, n)
+	pubKeys := make([]PubKey, n)
+	for i := 0; i < n; i++ {
+		privKey, err := GenPrivKeyBLS()
+		require.Nil(t, err)
+		privKeys[i] = privKey
+		pubKeys[i] = privKey.PubKey()
+	}
+	return privKeys, pubKeys
+}
+
+func TestSignAndValidateBLS(t *testing.T) {
+	privKey, err := GenPrivKeyBLS()
+	require.Nil(t, err)
+	pubKey := privKey.PubKey()
+
+	msg := CRandBytes(128)
+	sig, err := privKey.Sign(msg)
+	require.Nil(t, err)
+	assert.True(t, pubKey.VerifyBytes(msg, sig))
+	assert.False(t, pubKey.VerifyBytes(CRandBytes(128), sig))
+
+	// Mutate the signature, just one bit.
+	sigBLS := sig.(SignatureBLS)
+	sigBLS[7] ^= byte(0x01)
+	assert.False(t, pubKey.VerifyBytes(msg, sigBLS))
+
+	// The identity is neither a valid key nor a valid signature.
+	assert.False(t, PubKeyBLS{}.VerifyBytes(msg, SignatureBLS{}))
+
+	bz := pubKey.Bytes()
+	pubKey2, err := PubKeyFromBytes(bz)
+	require.Nil(t, err)
+	assert.True(t, pubKey.Equals(pubKey2))
+
+	privKey2, err := PrivKeyFromBytes(privKey.Bytes())
+	require.Nil(t, err)
+	assert.True(t, privKey.Equals(privKey2))
+}
+
+func TestGenPrivKeyBLSFromSecret(t *testing.T) {
+	privKey, err := GeneratePrivKeyFromSecret([]byte("hello"), CryptoTypeBLS)
+	require.Nil(t, err)
+	privKey2, err := GenPrivKeyBLSFromSecret([]byte("hello"))
+	require.Nil(t, err)
+	assert.True(t, privKey.Equals(privKey2))
+
+	msg := []byte("hello")
+	sig, err := privKey.Sign(msg)
+	require.Nil(t, err)
+	sig2, err := privKey2.Sign(msg)
+	require.Nil(t, err)
+	assert.True(t, sig.Equals(sig2), "BLS signatures are deterministic")
+}
+
+func TestBLSPossession(t *testing.T) {
+	privKeys, pubKeys := genBLSKeys(t, 2)
+	assert.True(t, pubKeys[0].(PubKeyBLS).VerifyPossession(privKeys[0].ProvePossession()))
+	assert.False(t, pubKeys[0].(PubKeyBLS).VerifyPossession(privKeys[1].ProvePossession()))
+
+	// A proof of possession is not a signature of the public key.
+	sig, _ := privKeys[0].Sign(pubKeys[0].Raw())
+	assert.False(t, pubKeys[0].(PubKeyBLS).VerifyPossession(sig))
+}
+
+func TestAggregateSameMessage(t *testing.T) {
+	privKeys, pubKeys := genBLSKeys(t, 8)
+	msg := []byte("block vote")
+
+	sigs := make([]Signature, len(privKeys))
+	for i, privKey := range privKeys {
+		sigs[i], _ = privKey.Sign(msg)
+	}
+	aggSig, err := AggregateSignatures(sigs)
+	require.Nil(t, err)
+	assert.True(t, VerifyAggregate(pubKeys, [][]byte{msg}, aggSig))
+
+	aggPub, err := AggregatePubKeys(pubKeys)
+	require.Nil(t, err)
+	assert.True(t, aggPub.VerifyBytes(msg, aggSig))
+
+	assert.False(t, VerifyAggregate(pubKeys[1:], [][]byte{msg}, aggSig))
+	assert.False(t, VerifyAggregate(pubKeys, [][]byte{[]byte("other vote")}, aggSig))
+
+	_, err = AggregateSignatures(nil)
+	assert.NotNil(t, err)
+	_, err = AggregatePubKeys([]PubKey{PubKeyEd25519{}})
+	assert.NotNil(t, err)
+}
+
+func TestAggregateDistinctMessages(t *testing.T) {
+	privKeys, pubKeys := genBLSKeys(t, 5)
+
+	msgs := make([][]byte, len(privKeys))
+	sigs := make([]Signature, len(privKeys))
+	for i, privKey := range privKeys {
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+		sigs[i], _ = privKey.Sign(msgs[i])
+	}
+	aggSig, err := AggregateSignatures(sigs)
+	require.Nil(t, err)
+	assert.True(t, VerifyAggregate(pubKeys, msgs, aggSig))
+
+	// Swap two messages.
+	msgs[0], msgs[1] = msgs[1], msgs[0]
+	assert.False(t, VerifyAggregate(pubKeys, msgs, aggSig))
+	msgs[0], msgs[1] = msgs[1], msgs[0]
+
+	// Messages must be distinct.
+	dup := append([][]byte{}, msgs...)
+	dup[1] = dup[0]
+	assert.False(t, VerifyAggregate(pubKeys, dup, aggSig))
+
+	assert.False(t, VerifyAggregate(pubKeys, msgs[:3], aggSig))
+}
+
+func BenchmarkBLSVerifyBytes(b *testing.B) {
+	privKey, _ := GenPrivKeyBLS()
+	pubKey := privKey.PubKey()
+	msg := []byte("hello")
+	sig, _ := privKey.Sign(msg)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pubKey.VerifyBytes(msg, sig)
+	}
+}
+
+func BenchmarkBLSVerifyAggregate(b *testing.B) {
+	privKeys, pubKeys := genBLSKeys(b, 64)
+	msg := []byte("block vote")
+	sigs := make([]Signature, len(privKeys))
+	for i, privKey := range privKeys {
+		sigs[i], _ = privKey.Sign(msg)
+	}
+	aggSig, _ := AggregateSignatures(sigs)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyAggregate(pubKeys, [][]byte{msg}, aggSig)
+	}
+}
@@ -0,0 +1,45 @@
+package confidential
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+var (
+	// B is the value generator, the ristretto255 base point.
+	pedersenB ristretto.Point
+	// B~ is the blinding generator, nobody knows its discrete log to B.
+	pedersenBlinding ristretto.Point
+
+	gensMu sync.Mutex
+	gensG  []ristretto.Point
+	gensH  []ristretto.Point
+)
+
+func init() {
+	pedersenB.SetBase()
+	pedersenBlinding.Derive([]byte("TC_PEDERSEN_BLINDING_GENERATOR"))
+}
+
+func deriveGenerator(label string, i int) ristretto.Point {
+	buf := make([]byte, len(label)+4)
+	copy(buf, label)
+	binary.BigEndian.PutUint32(buf[len(label):], uint32(i))
+	var p ristretto.Point
+	p.Derive(buf)
+	return p
+}
+
+// bulletproofGens returns the first n vector generators G and H, they are
+// derived once and shared by all proofs.
+func bulletproofGens(n int) (G, H []ristretto.Point) {
+	gensMu.Lock()
+	defer gensMu.Unlock()
+	for i := len(gensG); i < n; i++ {
+		gensG = append(gensG, deriveGenerator("TC_BULLETPROOFS_G", i))
+		gensH = append(gensH, deriveGenerator("TC_BULLETPROOFS_H", i))
+	}
+	return gensG[:n:n], gensH[:n:n]
+}
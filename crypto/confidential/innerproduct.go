@@ -0,0 +1,110 @@
+package confidential
+
+import (
+	"github.com/bwesterb/go-ristretto"
+)
+
+// InnerProductProof shows knowledge of vectors a and b such that
+//
+//	P = <a, G> + <b, H> + <a, b> * Q
+//
+// in 2*log2(n) points and two scalars. Each round halves the vectors with a
+// challenge u:
+//
+//	a' = u * a_lo + u^-1 * a_hi,  G' = u^-1 * G_lo + u * G_hi
+//	b' = u^-1 * b_lo + u * b_hi,  H' = u * H_lo + u^-1 * H_hi
+type InnerProductProof struct {
+	L [][32]byte
+	R [][32]byte
+	A [32]byte
+	B [32]byte
+}
+
+// proveInnerProduct builds the proof for a and b, of the same power of two
+// length as G and H. G and H are not modified.
+func proveInnerProduct(t *transcript, Q *ristretto.Point, G, H []ristretto.Point, a, b []ristretto.Scalar) *InnerProductProof {
+	n := len(a)
+	G = append([]ristretto.Point(nil), G...)
+	H = append([]ristretto.Point(nil), H...)
+	a = append([]ristretto.Scalar(nil), a...)
+	b = append([]ristretto.Scalar(nil), b...)
+
+	proof := &InnerProductProof{}
+	scalars := make([]ristretto.Scalar, 0, n+1)
+	points := make([]ristretto.Point, 0, n+1)
+	for n > 1 {
+		n /= 2
+		aLo, aHi := a[:n], a[n:]
+		bLo, bHi := b[:n], b[n:]
+		gLo, gHi := G[:n], G[n:]
+		hLo, hHi := H[:n], H[n:]
+
+		// L = <a_lo, G_hi> + <b_hi, H_lo> + <a_lo, b_hi> * Q
+		scalars = append(append(append(scalars[:0], aLo...), bHi...), *innerProduct(aLo, bHi))
+		points = append(append(append(points[:0], gHi...), hLo...), *Q)
+		var L ristretto.Point
+		multiScalarMult(&L, scalars, points)
+
+		// R = <a_hi, G_lo> + <b_lo, H_hi> + <a_hi, b_lo> * Q
+		scalars = append(append(append(scalars[:0], aHi...), bLo...), *innerProduct(aHi, bLo))
+		points = append(append(append(points[:0], gLo...), hHi...), *Q)
+		var R ristretto.Point
+		multiScalarMult(&R, scalars, points)
+
+		lBuf, rBuf := pointBytes(&L), pointBytes(&R)
+		proof.L = append(proof.L, lBuf)
+		proof.R = append(proof.R, rBuf)
+		t.appendBytes32("L", &lBuf)
+		t.appendBytes32("R", &rBuf)
+		u := t.challenge("u")
+		var uInv ristretto.Scalar
+		uInv.Inverse(u)
+
+		var s1, s2 ristretto.Scalar
+		var p1, p2 ristretto.Point
+		for i := 0; i < n; i++ {
+			aLo[i].Add(s1.Mul(&aLo[i], u), s2.Mul(&aHi[i], &uInv))
+			bLo[i].Add(s1.Mul(&bLo[i], &uInv), s2.Mul(&bHi[i], u))
+			gLo[i].Add(p1.PublicScalarMult(&gLo[i], &uInv), p2.PublicScalarMult(&gHi[i], u))
+			hLo[i].Add(p1.PublicScalarMult(&hLo[i], u), p2.PublicScalarMult(&hHi[i], &uInv))
+		}
+		a, b, G, H = aLo, bLo, gLo, hLo
+	}
+
+	proof.A = scalarBytes(&a[0])
+	proof.B = scalarBytes(&b[0])
+	return proof
+}
+
+// verificationScalars replays the rounds of the proof in the transcript. It
+// returns the challenges u, their inverses and the scalars s such that
+// the folded G is <s, G> and the folded H is <s^-1, H>, s_i is the product
+// of u_k or u_k^-1 whether the k-th most significant bit of i is set.
+func (proof *InnerProductProof) verificationScalars(t *transcript, n int) (u, uInv, s []ristretto.Scalar, ok bool) {
+	rounds := len(proof.L)
+	if n != 1<<uint(rounds) || len(proof.R) != rounds {
+		return nil, nil, nil, false
+	}
+
+	u = make([]ristretto.Scalar, rounds)
+	uInv = make([]ristretto.Scalar, rounds)
+	for k := 0; k < rounds; k++ {
+		t.appendBytes32("L", &proof.L[k])
+		t.appendBytes32("R", &proof.R[k])
+		u[k].Set(t.challenge("u"))
+		uInv[k].Inverse(&u[k])
+	}
+
+	s = make([]ristretto.Scalar, n)
+	for i := 0; i < n; i++ {
+		s[i].SetOne()
+		for k := 0; k < rounds; k++ {
+			if (i>>uint(rounds-1-k))&1 == 1 {
+				s[i].Mul(&s[i], &u[k])
+			} else {
+				s[i].Mul(&s[i], &uInv[k])
+			}
+		}
+	}
+	return u, uInv, s, true
+}
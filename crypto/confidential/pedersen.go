@@ -0,0 +1,158 @@
+// Package confidential hides amounts behind Pedersen commitments and proves
+// they are in range with Bulletproofs, over the ristretto255 group.
+//
+//	V = v*B + gamma*B~
+//
+// B is the ristretto255 base point and B~ a point derived by hashing, whose
+// discrete log to B is unknown. Commitments are additive: the sum of the
+// commitments to the inputs of a transfer minus the sum of the commitments to
+// its outputs is a commitment to zero under the difference of the blindings,
+// and a range proof shows that no output wraps around the group order.
+package confidential
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/bwesterb/go-ristretto"
+)
+
+var (
+	// ErrInvalidCommitment is returned when a commitment is not a valid point.
+	ErrInvalidCommitment = errors.New("invalid commitment")
+)
+
+// Blinding is the little endian blinding factor of a commitment.
+type Blinding [32]byte
+
+// NewBlinding returns a random blinding factor.
+func NewBlinding() Blinding {
+	var s ristretto.Scalar
+	s.Rand()
+	var b Blinding
+	s.BytesInto((*[32]byte)(&b))
+	return b
+}
+
+func (b Blinding) scalar() *ristretto.Scalar {
+	buf := [32]byte(b)
+	return new(ristretto.Scalar).SetBytes(&buf)
+}
+
+func makeBlinding(s *ristretto.Scalar) Blinding {
+	var b Blinding
+	s.BytesInto((*[32]byte)(&b))
+	return b
+}
+
+// Add returns the blinding of the sum of two commitments.
+func (b Blinding) Add(other Blinding) Blinding {
+	return makeBlinding(new(ristretto.Scalar).Add(b.scalar(), other.scalar()))
+}
+
+// Sub returns the blinding of the difference of two commitments.
+func (b Blinding) Sub(other Blinding) Blinding {
+	return makeBlinding(new(ristretto.Scalar).Sub(b.scalar(), other.scalar()))
+}
+
+//-------------------------------------
+
+// Commitment is a compressed Pedersen commitment.
+type Commitment [32]byte
+
+// Commit returns the commitment to value under blinding.
+func Commit(value uint64, blinding Blinding) Commitment {
+	var p ristretto.Point
+	commit(&p, scalarFromUint64(value), blinding.scalar())
+	var c Commitment
+	p.BytesInto((*[32]byte)(&c))
+	return c
+}
+
+func commit(p *ristretto.Point, v, gamma *ristretto.Scalar) *ristretto.Point {
+	var vB, gB ristretto.Point
+	vB.ScalarMultBase(v)
+	gB.ScalarMult(&pedersenBlinding, gamma)
+	return p.Add(&vB, &gB)
+}
+
+func (c Commitment) point() (*ristretto.Point, error) {
+	var p ristretto.Point
+	buf := [32]byte(c)
+	if !p.SetBytes(&buf) {
+		return nil, ErrInvalidCommitment
+	}
+	return &p, nil
+}
+
+func makeCommitment(p *ristretto.Point) Commitment {
+	var c Commitment
+	p.BytesInto((*[32]byte)(&c))
+	return c
+}
+
+// Add returns the commitment to the sum of the values, under the sum of the
+// blindings.
+func (c Commitment) Add(other Commitment) (Commitment, error) {
+	p, err := c.point()
+	if err != nil {
+		return Commitment{}, err
+	}
+	q, err := other.point()
+	if err != nil {
+		return Commitment{}, err
+	}
+	return makeCommitment(p.Add(p, q)), nil
+}
+
+// Sub returns the commitment to the difference of the values, under the
+// difference of the blindings.
+func (c Commitment) Sub(other Commitment) (Commitment, error) {
+	p, err := c.point()
+	if err != nil {
+		return Commitment{}, err
+	}
+	q, err := other.point()
+	if err != nil {
+		return Commitment{}, err
+	}
+	return makeCommitment(p.Sub(p, q)), nil
+}
+
+// Open reports whether c is the commitment to value under blinding.
+func (c Commitment) Open(value uint64, blinding Blinding) bool {
+	return Commit(value, blinding) == c
+}
+
+// IsValid reports whether c decodes to a point.
+func (c Commitment) IsValid() bool {
+	_, err := c.point()
+	return err == nil
+}
+
+func (c Commitment) String() string {
+	return fmt.Sprintf("Commitment{%X}", common.Fingerprint(c[:]))
+}
+
+// SumCommitments returns the sum of the commitments, the commitment to zero
+// under a zero blinding if there are none.
+func SumCommitments(commitments []Commitment) (Commitment, error) {
+	var sum ristretto.Point
+	sum.SetZero()
+	for _, c := range commitments {
+		p, err := c.point()
+		if err != nil {
+			return Commitment{}, err
+		}
+		sum.Add(&sum, p)
+	}
+	return makeCommitment(&sum), nil
+}
+
+func scalarFromUint64(v uint64) *ristretto.Scalar {
+	var buf [32]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return new(ristretto.Scalar).SetBytes(&buf)
+}
@@ -0,0 +1,51 @@
+package confidential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitmentHomomorphic(t *testing.T) {
+	g1, g2 := NewBlinding(), NewBlinding()
+	c1 := Commit(1000, g1)
+	c2 := Commit(250, g2)
+	assert.True(t, c1.Open(1000, g1))
+	assert.False(t, c1.Open(1001, g1))
+	assert.False(t, c1.Open(1000, g2))
+
+	sum, err := c1.Add(c2)
+	require.Nil(t, err)
+	assert.True(t, sum.Open(1250, g1.Add(g2)))
+
+	diff, err := c1.Sub(c2)
+	require.Nil(t, err)
+	assert.True(t, diff.Open(750, g1.Sub(g2)))
+
+	total, err := SumCommitments([]Commitment{c1, c2})
+	require.Nil(t, err)
+	assert.Equal(t, sum, total)
+
+	// inputs - outputs is a commitment to zero
+	g3 := g1.Add(g2).Sub(NewBlinding())
+	out1 := Commit(900, g3)
+	out2 := Commit(350, g1.Add(g2).Sub(g3))
+	outs, err := SumCommitments([]Commitment{out1, out2})
+	require.Nil(t, err)
+	balance, err := total.Sub(outs)
+	require.Nil(t, err)
+	assert.True(t, balance.Open(0, Blinding{}))
+}
+
+func TestCommitmentInvalid(t *testing.T) {
+	var bad Commitment
+	for i := range bad {
+		bad[i] = 0xff
+	}
+	assert.False(t, bad.IsValid())
+	_, err := Commit(1, NewBlinding()).Add(bad)
+	assert.Equal(t, ErrInvalidCommitment, err)
+	_, err = bad.Sub(Commit(1, NewBlinding()))
+	assert.Equal(t, ErrInvalidCommitment, err)
+}
@@ -0,0 +1,370 @@
+package confidential
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/bwesterb/go-ristretto"
+)
+
+const (
+	// RangeProofBits is the size of the range, values are proven in [0, 2^64).
+	RangeProofBits = 64
+	// MaxAggregation is the maximum number of values in one range proof.
+	MaxAggregation = 16
+
+	rangeProofDomain = "TC_BULLETPROOFS_RANGE_PROOF"
+)
+
+var (
+	// ErrInvalidRangeProof is returned when a range proof does not verify.
+	ErrInvalidRangeProof = errors.New("invalid range proof")
+	// ErrInvalidAggregation is returned when the number of values of a range
+	// proof is not a power of two up to MaxAggregation.
+	ErrInvalidAggregation = errors.New("invalid range proof aggregation size")
+)
+
+// RangeProof is an aggregated Bulletproofs range proof, it shows that each of
+// m commitments opens to a value in [0, 2^64), m is a power of two. The size
+// of the proof is logarithmic in m.
+//
+// With n = 64 and bit i of the concatenated values being the bit i%n of the
+// value i/n, the prover commits to the bits aL and aR = aL - 1:
+//
+//	A = alpha*B~ + <aL, G> + <aR, H>
+//	S = rho*B~ + <sL, G> + <sR, H>
+//
+// and to the coefficients of t(X) = <l(X), r(X)> where
+//
+//	l(X) = aL - z + sL*X
+//	r(X) = y^N o (aR + z + sR*X) + sum_j z^(2+j) * 2^n shifted to value j
+//
+// The verifier checks t(x) against the commitments, and <l(x), r(x)> with an
+// inner product proof.
+type RangeProof struct {
+	A    [32]byte
+	S    [32]byte
+	T1   [32]byte
+	T2   [32]byte
+	TauX [32]byte
+	Mu   [32]byte
+	THat [32]byte
+	IPP  InnerProductProof
+}
+
+// Bytes returns the bal encoding of the proof.
+func (proof *RangeProof) Bytes() []byte {
+	return bal.MustEncodeToBytes(proof)
+}
+
+// RangeProofFromBytes decodes a proof encoded by Bytes.
+func RangeProofFromBytes(bz []byte) (*RangeProof, error) {
+	proof := new(RangeProof)
+	if err := bal.DecodeBytes(bz, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+func checkAggregation(m int) error {
+	if m <= 0 || m > MaxAggregation || m&(m-1) != 0 {
+		return ErrInvalidAggregation
+	}
+	return nil
+}
+
+func newRangeProofTranscript(commitments []Commitment) *transcript {
+	t := newTranscript(rangeProofDomain)
+	t.appendUint64("n", RangeProofBits)
+	t.appendUint64("m", uint64(len(commitments)))
+	for i := range commitments {
+		t.appendBytes32("V", (*[32]byte)(&commitments[i]))
+	}
+	return t
+}
+
+// zetas returns z^(2+j) * 2^k for the bit k of the value j.
+func zetas(z *ristretto.Scalar, m int) []ristretto.Scalar {
+	two := scalarFromUint64(2)
+	pow2 := scalarPowers(two, RangeProofBits)
+	zs := make([]ristretto.Scalar, m*RangeProofBits)
+	var zj ristretto.Scalar
+	zj.Mul(z, z)
+	for j := 0; j < m; j++ {
+		for k := 0; k < RangeProofBits; k++ {
+			zs[j*RangeProofBits+k].Mul(&zj, &pow2[k])
+		}
+		zj.Mul(&zj, z)
+	}
+	return zs
+}
+
+// ProveRange returns the commitments to the values under the blindings, and a
+// proof that all of them are in range.
+func ProveRange(values []uint64, blindings []Blinding) (*RangeProof, []Commitment, error) {
+	m := len(values)
+	if err := checkAggregation(m); err != nil {
+		return nil, nil, err
+	}
+	if len(blindings) != m {
+		return nil, nil, fmt.Errorf("got %d values and %d blindings", m, len(blindings))
+	}
+	N := m * RangeProofBits
+	G, H := bulletproofGens(N)
+
+	gammas := make([]ristretto.Scalar, m)
+	commitments := make([]Commitment, m)
+	for j := range values {
+		gammas[j].Set(blindings[j].scalar())
+		var V ristretto.Point
+		commit(&V, scalarFromUint64(values[j]), &gammas[j])
+		commitments[j] = makeCommitment(&V)
+	}
+	t := newRangeProofTranscript(commitments)
+
+	var one ristretto.Scalar
+	one.SetOne()
+	aL := make([]ristretto.Scalar, N)
+	aR := make([]ristretto.Scalar, N)
+	for j, v := range values {
+		for k := 0; k < RangeProofBits; k++ {
+			i := j*RangeProofBits + k
+			aL[i].Set(scalarFromUint64((v >> uint(k)) & 1))
+			aR[i].Sub(&aL[i], &one)
+		}
+	}
+
+	scalars := make([]ristretto.Scalar, 0, 2*N+1)
+	points := make([]ristretto.Point, 0, 2*N+1)
+	points = append(append(append(points, G...), H...), pedersenBlinding)
+
+	var alpha, rho ristretto.Scalar
+	alpha.Rand()
+	rho.Rand()
+	var A, S ristretto.Point
+	scalars = append(append(append(scalars[:0], aL...), aR...), alpha)
+	multiScalarMult(&A, scalars, points)
+	sL := randomScalars(N)
+	sR := randomScalars(N)
+	scalars = append(append(append(scalars[:0], sL...), sR...), rho)
+	multiScalarMult(&S, scalars, points)
+
+	proof := &RangeProof{A: pointBytes(&A), S: pointBytes(&S)}
+	t.appendBytes32("A", &proof.A)
+	t.appendBytes32("S", &proof.S)
+	y := t.challenge("y")
+	z := t.challenge("z")
+
+	yPows := scalarPowers(y, N)
+	zs := zetas(z, m)
+	l0 := make([]ristretto.Scalar, N)
+	r0 := make([]ristretto.Scalar, N)
+	r1 := make([]ristretto.Scalar, N)
+	for i := 0; i < N; i++ {
+		l0[i].Sub(&aL[i], z)
+		r0[i].Add(&aR[i], z)
+		r0[i].MulAdd(&yPows[i], &r0[i], &zs[i])
+		r1[i].Mul(&yPows[i], &sR[i])
+	}
+
+	// t(X) = t0 + t1*X + t2*X^2
+	var t1, t2 ristretto.Scalar
+	t1.Add(innerProduct(l0, r1), innerProduct(sL, r0))
+	t2.Set(innerProduct(sL, r1))
+	var tau1, tau2 ristretto.Scalar
+	tau1.Rand()
+	tau2.Rand()
+	var T1, T2 ristretto.Point
+	commit(&T1, &t1, &tau1)
+	commit(&T2, &t2, &tau2)
+	proof.T1 = pointBytes(&T1)
+	proof.T2 = pointBytes(&T2)
+	t.appendBytes32("T1", &proof.T1)
+	t.appendBytes32("T2", &proof.T2)
+	x := t.challenge("x")
+
+	// tauX = tau2*x^2 + tau1*x + sum_j z^(2+j) * gamma_j
+	var tauX, zj ristretto.Scalar
+	tauX.Mul(&tau2, x)
+	tauX.Add(&tauX, &tau1)
+	tauX.Mul(&tauX, x)
+	zj.Mul(z, z)
+	for j := range gammas {
+		tauX.MulAdd(&zj, &gammas[j], &tauX)
+		zj.Mul(&zj, z)
+	}
+	// mu = alpha + rho*x
+	var mu ristretto.Scalar
+	mu.MulAdd(&rho, x, &alpha)
+
+	l := make([]ristretto.Scalar, N)
+	r := make([]ristretto.Scalar, N)
+	for i := 0; i < N; i++ {
+		l[i].MulAdd(&sL[i], x, &l0[i])
+		r[i].MulAdd(&r1[i], x, &r0[i])
+	}
+	tHat := innerProduct(l, r)
+
+	proof.TauX = scalarBytes(&tauX)
+	proof.Mu = scalarBytes(&mu)
+	proof.THat = scalarBytes(tHat)
+	t.appendBytes32("tau_x", &proof.TauX)
+	t.appendBytes32("mu", &proof.Mu)
+	t.appendBytes32("t_hat", &proof.THat)
+	w := t.challenge("w")
+
+	// The inner product proof is over H' = y^-i * H_i, and Q = w*B.
+	var Q ristretto.Point
+	Q.ScalarMultBase(w)
+	var yInv ristretto.Scalar
+	yInv.Inverse(y)
+	yInvPows := scalarPowers(&yInv, N)
+	hPrime := make([]ristretto.Point, N)
+	for i := range hPrime {
+		hPrime[i].PublicScalarMult(&H[i], &yInvPows[i])
+	}
+	proof.IPP = *proveInnerProduct(t, &Q, G, hPrime, l, r)
+	return proof, commitments, nil
+}
+
+// Verify checks that each commitment opens to a value in [0, 2^64).
+func (proof *RangeProof) Verify(commitments []Commitment) error {
+	m := len(commitments)
+	if err := checkAggregation(m); err != nil {
+		return err
+	}
+	N := m * RangeProofBits
+	G, H := bulletproofGens(N)
+
+	var A, S, T1, T2 ristretto.Point
+	for _, p := range []struct {
+		point *ristretto.Point
+		buf   [32]byte
+	}{{&A, proof.A}, {&S, proof.S}, {&T1, proof.T1}, {&T2, proof.T2}} {
+		if !p.point.SetBytes(&p.buf) {
+			return ErrInvalidRangeProof
+		}
+	}
+	V := make([]ristretto.Point, m)
+	for j := range commitments {
+		buf := [32]byte(commitments[j])
+		if !V[j].SetBytes(&buf) {
+			return ErrInvalidCommitment
+		}
+	}
+	tauX, ok1 := scalarFromCanonical(&proof.TauX)
+	mu, ok2 := scalarFromCanonical(&proof.Mu)
+	tHat, ok3 := scalarFromCanonical(&proof.THat)
+	a, ok4 := scalarFromCanonical(&proof.IPP.A)
+	b, ok5 := scalarFromCanonical(&proof.IPP.B)
+	if !(ok1 && ok2 && ok3 && ok4 && ok5) {
+		return ErrInvalidRangeProof
+	}
+
+	t := newRangeProofTranscript(commitments)
+	t.appendBytes32("A", &proof.A)
+	t.appendBytes32("S", &proof.S)
+	y := t.challenge("y")
+	z := t.challenge("z")
+	t.appendBytes32("T1", &proof.T1)
+	t.appendBytes32("T2", &proof.T2)
+	x := t.challenge("x")
+	t.appendBytes32("tau_x", &proof.TauX)
+	t.appendBytes32("mu", &proof.Mu)
+	t.appendBytes32("t_hat", &proof.THat)
+	w := t.challenge("w")
+	u, uInv, s, ok := proof.IPP.verificationScalars(t, N)
+	if !ok {
+		return ErrInvalidRangeProof
+	}
+
+	var zz, xx ristretto.Scalar
+	zz.Mul(z, z)
+	xx.Mul(x, x)
+	yPows := scalarPowers(y, N)
+
+	// delta(y, z) = (z - z^2) * sum(y^i) - sum_j z^(3+j) * (2^n - 1)
+	var delta, sumY, sumZ, zj, tmp ristretto.Scalar
+	sumY.SetZero()
+	for i := range yPows {
+		sumY.Add(&sumY, &yPows[i])
+	}
+	delta.Sub(z, &zz)
+	delta.Mul(&delta, &sumY)
+	sumZ.SetZero()
+	zj.Mul(&zz, z)
+	for j := 0; j < m; j++ {
+		sumZ.Add(&sumZ, &zj)
+		zj.Mul(&zj, z)
+	}
+	tmp.Mul(&sumZ, scalarFromUint64(^uint64(0)))
+	delta.Sub(&delta, &tmp)
+
+	// t_hat*B + tau_x*B~ == sum_j z^(2+j)*V_j + delta*B + x*T1 + x^2*T2
+	scalars := make([]ristretto.Scalar, 0, 2*N+2*len(u)+5)
+	points := make([]ristretto.Point, 0, 2*N+2*len(u)+5)
+	var c ristretto.Scalar
+	zj.Set(&zz)
+	for j := range V {
+		scalars = append(scalars, *c.Neg(&zj))
+		points = append(points, V[j])
+		zj.Mul(&zj, z)
+	}
+	var bCoef, xNeg, xxNeg ristretto.Scalar
+	bCoef.Sub(tHat, &delta)
+	xNeg.Neg(x)
+	xxNeg.Neg(&xx)
+	scalars = append(scalars, bCoef, *tauX, xNeg, xxNeg)
+	points = append(points, pedersenB, pedersenBlinding, T1, T2)
+	var check ristretto.Point
+	publicMultiScalarMult(&check, scalars, points)
+	if !check.Equals(new(ristretto.Point).SetZero()) {
+		return ErrInvalidRangeProof
+	}
+
+	// A + x*S - mu*B~ + t_hat*w*B - <z, G> + <z + zeta*y^-i, H>
+	//   + sum(u_k^2*L_k + u_k^-2*R_k) == a*<s, G> + b*<s^-1, y^-i * H> + a*b*w*B
+	zs := zetas(z, m)
+	var yInv ristretto.Scalar
+	yInv.Inverse(y)
+	yInvPows := scalarPowers(&yInv, N)
+	scalars = scalars[:0]
+	points = points[:0]
+	for i := 0; i < N; i++ {
+		// -z - a*s_i
+		c.MulAdd(a, &s[i], z)
+		scalars = append(scalars, *c.Neg(&c))
+		points = append(points, G[i])
+	}
+	for i := 0; i < N; i++ {
+		// z + (zeta_i - b*s_i^-1) * y^-i, s_i^-1 is s_(N-1-i)
+		tmp.Mul(b, &s[N-1-i])
+		tmp.Sub(&zs[i], &tmp)
+		c.MulAdd(&tmp, &yInvPows[i], z)
+		scalars = append(scalars, c)
+		points = append(points, H[i])
+	}
+	for k := range u {
+		var L, R ristretto.Point
+		if !L.SetBytes(&proof.IPP.L[k]) || !R.SetBytes(&proof.IPP.R[k]) {
+			return ErrInvalidRangeProof
+		}
+		scalars = append(scalars, *c.Square(&u[k]), *tmp.Square(&uInv[k]))
+		points = append(points, L, R)
+	}
+	var one ristretto.Scalar
+	one.SetOne()
+	var muNeg ristretto.Scalar
+	bCoef.Mul(a, b)
+	bCoef.Sub(tHat, &bCoef)
+	bCoef.Mul(&bCoef, w)
+	muNeg.Neg(mu)
+	scalars = append(scalars, one, *x, muNeg, bCoef)
+	points = append(points, A, S, pedersenBlinding, pedersenB)
+	publicMultiScalarMult(&check, scalars, points)
+	if !check.Equals(new(ristretto.Point).SetZero()) {
+		return ErrInvalidRangeProof
+	}
+	return nil
+}
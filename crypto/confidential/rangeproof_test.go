@@ -0,0 +1,106 @@
+package confidential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proveRange(t testing.TB, values []uint64) (*RangeProof, []Commitment, []Blinding) {
+	blindings := make([]Blinding, len(values))
+	for i := range blindings {
+		blindings[i] = NewBlinding()
+	}
+	proof, commitments, err := ProveRange(values, blindings)
+	require.Nil(t, err)
+	for i := range values {
+		require.True(t, commitments[i].Open(values[i], blindings[i]))
+	}
+	return proof, commitments, blindings
+}
+
+func TestRangeProof(t *testing.T) {
+	for _, values := range [][]uint64{
+		{0},
+		{^uint64(0)},
+		{1, 1 << 63},
+		{7, 42, 1000000, ^uint64(0) - 1},
+	} {
+		proof, commitments, _ := proveRange(t, values)
+		assert.Nil(t, proof.Verify(commitments), "values %v", values)
+		assert.Len(t, proof.IPP.L, 6+len(values)/2)
+	}
+}
+
+func TestRangeProofInvalid(t *testing.T) {
+	proof, commitments, _ := proveRange(t, []uint64{5, 6})
+
+	// wrong commitment
+	other := append([]Commitment(nil), commitments...)
+	other[1] = Commit(6, NewBlinding())
+	assert.Equal(t, ErrInvalidRangeProof, proof.Verify(other))
+	// swapped commitments
+	assert.Equal(t, ErrInvalidRangeProof, proof.Verify([]Commitment{commitments[1], commitments[0]}))
+	// wrong number of commitments
+	assert.Equal(t, ErrInvalidRangeProof, proof.Verify(commitments[:1]))
+	assert.Equal(t, ErrInvalidAggregation, proof.Verify(append(commitments, commitments[0])))
+
+	tampered := *proof
+	tampered.THat[0] ^= 1
+	assert.Equal(t, ErrInvalidRangeProof, tampered.Verify(commitments))
+	tampered = *proof
+	tampered.IPP.A[0] ^= 1
+	assert.Equal(t, ErrInvalidRangeProof, tampered.Verify(commitments))
+	tampered = *proof
+	tampered.IPP.L = append([][32]byte{proof.IPP.R[0]}, proof.IPP.L[1:]...)
+	assert.Equal(t, ErrInvalidRangeProof, tampered.Verify(commitments))
+	tampered = *proof
+	tampered.IPP.L = tampered.IPP.L[1:]
+	assert.Equal(t, ErrInvalidRangeProof, tampered.Verify(commitments))
+}
+
+func TestRangeProofOutOfRange(t *testing.T) {
+	// A commitment to -1 is a commitment to l-1, which does not fit in 64
+	// bits, a proof for another commitment does not verify for it.
+	g := NewBlinding()
+	proof, commitments, err := ProveRange([]uint64{1}, []Blinding{g})
+	require.Nil(t, err)
+	minusOne, err := Commit(0, g).Sub(Commit(1, Blinding{}))
+	require.Nil(t, err)
+	assert.Nil(t, proof.Verify(commitments))
+	assert.NotNil(t, proof.Verify([]Commitment{minusOne}))
+
+	_, _, err = ProveRange([]uint64{1, 2, 3}, make([]Blinding, 3))
+	assert.Equal(t, ErrInvalidAggregation, err)
+	_, _, err = ProveRange([]uint64{1, 2}, make([]Blinding, 1))
+	assert.NotNil(t, err)
+}
+
+func TestRangeProofBytes(t *testing.T) {
+	proof, commitments, _ := proveRange(t, []uint64{100, 200})
+	bz := proof.Bytes()
+	decoded, err := RangeProofFromBytes(bz)
+	require.Nil(t, err)
+	assert.Equal(t, proof, decoded)
+	assert.Nil(t, decoded.Verify(commitments))
+}
+
+func BenchmarkProveRange(b *testing.B) {
+	blindings := []Blinding{NewBlinding()}
+	for i := 0; i < b.N; i++ {
+		if _, _, err := ProveRange([]uint64{uint64(i)}, blindings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyRange(b *testing.B) {
+	proof, commitments, _ := proveRange(b, []uint64{12345})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := proof.Verify(commitments); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
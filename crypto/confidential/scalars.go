@@ -0,0 +1,78 @@
+package confidential
+
+import (
+	"github.com/bwesterb/go-ristretto"
+)
+
+func scalarPowers(x *ristretto.Scalar, n int) []ristretto.Scalar {
+	pows := make([]ristretto.Scalar, n)
+	if n == 0 {
+		return pows
+	}
+	pows[0].SetOne()
+	for i := 1; i < n; i++ {
+		pows[i].Mul(&pows[i-1], x)
+	}
+	return pows
+}
+
+func innerProduct(a, b []ristretto.Scalar) *ristretto.Scalar {
+	var sum ristretto.Scalar
+	sum.SetZero()
+	for i := range a {
+		sum.MulAdd(&a[i], &b[i], &sum)
+	}
+	return &sum
+}
+
+func randomScalars(n int) []ristretto.Scalar {
+	s := make([]ristretto.Scalar, n)
+	for i := range s {
+		s[i].Rand()
+	}
+	return s
+}
+
+// scalarFromCanonical decodes s, ok is false if it is not reduced.
+func scalarFromCanonical(buf *[32]byte) (*ristretto.Scalar, bool) {
+	var s ristretto.Scalar
+	s.SetBytes(buf)
+	var enc [32]byte
+	s.BytesInto(&enc)
+	return &s, enc == *buf
+}
+
+func scalarBytes(s *ristretto.Scalar) [32]byte {
+	var buf [32]byte
+	s.BytesInto(&buf)
+	return buf
+}
+
+func pointBytes(p *ristretto.Point) [32]byte {
+	var buf [32]byte
+	p.BytesInto(&buf)
+	return buf
+}
+
+// multiScalarMult sets p to sum(scalars[i] * points[i]), in constant time.
+func multiScalarMult(p *ristretto.Point, scalars []ristretto.Scalar, points []ristretto.Point) *ristretto.Point {
+	var acc, t ristretto.Point
+	acc.SetZero()
+	for i := range points {
+		t.ScalarMult(&points[i], &scalars[i])
+		acc.Add(&acc, &t)
+	}
+	return p.Set(&acc)
+}
+
+// publicMultiScalarMult is the variable time multiScalarMult, for public
+// scalars only.
+func publicMultiScalarMult(p *ristretto.Point, scalars []ristretto.Scalar, points []ristretto.Point) *ristretto.Point {
+	var acc, t ristretto.Point
+	acc.SetZero()
+	for i := range points {
+		t.PublicScalarMult(&points[i], &scalars[i])
+		acc.Add(&acc, &t)
+	}
+	return p.Set(&acc)
+}
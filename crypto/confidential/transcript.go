@@ -0,0 +1,51 @@
+package confidential
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// transcript turns the interactive protocols into non interactive ones
+// (Fiat-Shamir). Every message of the prover is absorbed in a running hash,
+// challenges are derived from it.
+type transcript struct {
+	state [64]byte
+}
+
+func newTranscript(label string) *transcript {
+	t := &transcript{}
+	t.append("domain", []byte(label))
+	return t
+}
+
+func (t *transcript) append(label string, data []byte) {
+	var l [4]byte
+	h := sha512.New()
+	h.Write(t.state[:])
+	binary.BigEndian.PutUint32(l[:], uint32(len(label)))
+	h.Write(l[:])
+	h.Write([]byte(label))
+	binary.BigEndian.PutUint32(l[:], uint32(len(data)))
+	h.Write(l[:])
+	h.Write(data)
+	h.Sum(t.state[:0])
+}
+
+func (t *transcript) appendUint64(label string, v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	t.append(label, buf[:])
+}
+
+func (t *transcript) appendBytes32(label string, b *[32]byte) {
+	t.append(label, b[:])
+}
+
+// challenge returns a scalar derived from everything absorbed so far.
+func (t *transcript) challenge(label string) *ristretto.Scalar {
+	t.append(label, nil)
+	buf := t.state
+	return new(ristretto.Scalar).SetReduced(&buf)
+}
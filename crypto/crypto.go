@@ -45,22 +45,24 @@ func Keccak512(data ...[]byte) []byte {
 	return d.Sum(nil)
 }
 
-// CreateAddress creates an ethereum address given the bytes and the nonce
+// CreateAddress creates an ethereum address given the bytes and the nonce,
+// hashed with the hash suite of the chain.
 func CreateAddress(b common.Address, nonce uint64, payload []byte) common.Address {
 	data, _ := bal.EncodeToBytes([]interface{}{b, nonce, payload})
-	return common.BytesToAddress(Keccak256(data)[12:])
+	return common.BytesToAddress(LocalHash(data)[12:])
 }
 
 // CreateAddress2 creates an ethereum address given the address bytes, initial
-// contract code hash and a salt.
+// contract code hash and a salt, hashed with the hash suite of the chain.
 func CreateAddress2(b common.Address, salt [32]byte, inithash []byte) common.Address {
-	return common.BytesToAddress(Keccak256([]byte{0xff}, b.Bytes(), salt[:], inithash)[12:])
+	return common.BytesToAddress(LocalHash([]byte{0xff}, b.Bytes(), salt[:], inithash)[12:])
 }
 
-// CreateContractAddress creates an ethereum address given the bytes and the nonce and the random number
+// CreateContractAddress creates an ethereum address given the bytes and the nonce and the random number,
+// hashed with the hash suite of the chain.
 func CreateContractAddress(b common.Address, nonce uint64, payload []byte, rand int) common.Address {
 	data, _ := bal.EncodeToBytes([]interface{}{b, nonce, payload, rand})
-	return common.BytesToAddress(Keccak256(data)[12:])
+	return common.BytesToAddress(LocalHash(data)[12:])
 }
 
 // ValidateSignatureValues verifies whether the signature values are valid with
@@ -80,6 +82,14 @@ func ValidateSignatureValues(v byte, r, s *big.Int, homestead bool) bool {
 		return r.Cmp(secp256k1N) < 0 && s.Cmp(secp256k1N) < 0 && (v == 0 || v == 1)
 	}
 
+	if v == P256Magic || v == P256Magic+1 {
+		// P256 signatures are always produced with low S values
+		if homestead && s.Cmp(p256halfN) > 0 {
+			return false
+		}
+		return r.Cmp(p256N) < 0 && s.Cmp(p256N) < 0
+	}
+
 	return (v == SM2Magic || v == SM2Magic+1)
 }
 
@@ -0,0 +1,145 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm3"
+	"golang.org/x/crypto/curve25519"
+)
+
+// ErrKeyAgreementNotSupported is returned when the key type has no
+// Diffie-Hellman key agreement.
+var ErrKeyAgreementNotSupported = errors.New("key agreement not supported")
+
+// SharedSecret returns the 32 bytes secret shared by the owners of two key
+// pairs of the same type: SharedSecret(privA, pubB) == SharedSecret(privB, pubA).
+//
+//	secp256k1: SHA256 of the compressed shared point (libsecp256k1 ecdh module)
+//	gm:        KDF(xU || yU || Z1 || Z2) of GB/T 32918.3 with U = dA*PB
+//	ed25519:   X25519 of the keys converted to the Montgomery form
+//
+// The secret is static, peers should derive their session keys from it
+// together with fresh nonces.
+func SharedSecret(privKey PrivKey, pubKey PubKey) ([]byte, error) {
+	algo := algorithmByName(privKey.Type())
+	if algo == nil || algo.SharedSecret == nil {
+		return nil, ErrKeyAgreementNotSupported
+	}
+	if algorithmByPubKey(pubKey) != algo {
+		return nil, fmt.Errorf("public key is not a %s key", algo.Name)
+	}
+	return algo.SharedSecret(privKey, pubKey)
+}
+
+func sharedSecretSecp256k1(privKey PrivKey, pubKey PubKey) ([]byte, error) {
+	return secp256k1.ECDH(pubKey.Raw(), privKey.Raw())
+}
+
+//-------------------------------------
+
+// sm2DefaultUID is the default user identity of GB/T 32918.
+var sm2DefaultUID = []byte("1234567812345678")
+
+// sharedSecretGM is the key derivation of the SM2 key exchange with the long
+// term keys only, as there are no ephemeral keys here. Z1 and Z2 are the
+// identity hashes of the two public keys, sorted so that both sides agree.
+func sharedSecretGM(privKey PrivKey, pubKey PubKey) ([]byte, error) {
+	priv := privKey.(*PrivKeyGM).key()
+	pub := pubKey.(*PubKeyGM).key()
+	curve := sm2.P256Sm2()
+	if pub.X == nil || !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("invalid public key")
+	}
+
+	ux, uy := curve.ScalarMult(pub.X, pub.Y, priv.D.Bytes())
+	if ux.Sign() == 0 && uy.Sign() == 0 {
+		return nil, errors.New("invalid shared point")
+	}
+	za, err := sm2.ZA(&priv.PublicKey, sm2DefaultUID)
+	if err != nil {
+		return nil, err
+	}
+	zb, err := sm2.ZA(pub, sm2DefaultUID)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Compare(za, zb) > 0 {
+		za, zb = zb, za
+	}
+
+	z := make([]byte, 0, 128)
+	z = append(z, padBytes(ux.Bytes(), 32)...)
+	z = append(z, padBytes(uy.Bytes(), 32)...)
+	z = append(z, za...)
+	z = append(z, zb...)
+	return sm3KDF(z, 32), nil
+}
+
+// sm3KDF is the key derivation function of GB/T 32918.
+func sm3KDF(z []byte, length int) []byte {
+	out := make([]byte, 0, length+32)
+	var ct [4]byte
+	for i := uint32(1); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(ct[:], i)
+		h := sm3.New()
+		h.Write(z)
+		h.Write(ct[:])
+		out = h.Sum(out)
+	}
+	return out[:length]
+}
+
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
+
+//-------------------------------------
+
+func sharedSecretEd25519(privKey PrivKey, pubKey PubKey) ([]byte, error) {
+	u, err := ed25519PubKeyToX25519(pubKey.(PubKeyEd25519))
+	if err != nil {
+		return nil, err
+	}
+	// X25519 clamps the scalar and rejects small order points.
+	return curve25519.X25519(ed25519PrivKeyToX25519(privKey.(PrivKeyEd25519)), u)
+}
+
+// ed25519PrivKeyToX25519 returns the X25519 private key of an ed25519 key,
+// the first half of the SHA512 of its seed.
+func ed25519PrivKeyToX25519(privKey PrivKeyEd25519) []byte {
+	h := sha512.Sum512(privKey[:32])
+	return h[:32]
+}
+
+// ed25519PubKeyToX25519 returns the X25519 public key of an ed25519 key, the
+// Montgomery u = (1 + y) / (1 - y) of its Edwards point.
+func ed25519PubKeyToX25519(pubKey PubKeyEd25519) ([]byte, error) {
+	var p edwards25519.ExtendedPoint
+	if !decodeEdPoint(&p, (*[32]byte)(&pubKey)) {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	var one, num, den, u edwards25519.FieldElement
+	one.SetOne()
+	num.Add(&one, &p.Y)
+	den.Sub(&one, &p.Y)
+	if den.IsNonZeroI() == 0 {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	den.Inverse(&den)
+	u.Mul(&num, &den)
+	var buf [32]byte
+	u.BytesInto(&buf)
+	return buf[:], nil
+}
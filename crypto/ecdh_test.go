@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestSharedSecret(t *testing.T) {
+	for _, algo := range []string{CryptoTypeSecp256K1, CryptoTypeGM, CryptoTypeEd25519} {
+		privA, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+		privB, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+		privC, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+
+		ab, err := SharedSecret(privA, privB.PubKey())
+		require.Nil(t, err, algo)
+		ba, err := SharedSecret(privB, privA.PubKey())
+		require.Nil(t, err, algo)
+		assert.Len(t, ab, 32)
+		assert.Equal(t, ab, ba, algo)
+
+		ac, err := SharedSecret(privA, privC.PubKey())
+		require.Nil(t, err, algo)
+		assert.NotEqual(t, ab, ac, algo)
+	}
+}
+
+func TestSharedSecretMismatch(t *testing.T) {
+	edKey, err := GenPrivKeyEd25519()
+	require.Nil(t, err)
+	secpKey, err := GenPrivKeySecp256k1()
+	require.Nil(t, err)
+	_, err = SharedSecret(edKey, secpKey.PubKey())
+	assert.NotNil(t, err)
+
+	p256Key, err := GenPrivKeyP256()
+	require.Nil(t, err)
+	_, err = SharedSecret(p256Key, p256Key.PubKey())
+	assert.Equal(t, ErrKeyAgreementNotSupported, err)
+
+	// Small order ed25519 public key.
+	var smallOrder PubKeyEd25519
+	smallOrder[0] = 1
+	_, err = SharedSecret(edKey, smallOrder)
+	assert.NotNil(t, err)
+}
+
+func TestEd25519ToX25519(t *testing.T) {
+	privKey, err := GenPrivKeyEd25519()
+	require.Nil(t, err)
+	u, err := ed25519PubKeyToX25519(privKey.PubKey().(PubKeyEd25519))
+	require.Nil(t, err)
+	expect, err := curve25519.X25519(ed25519PrivKeyToX25519(privKey), curve25519.Basepoint)
+	require.Nil(t, err)
+	assert.Equal(t, expect, u)
+}
@@ -0,0 +1,186 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
+	"github.com/tjfoc/gmsm/sm2"
+	"golang.org/x/crypto/hkdf"
+)
+
+var (
+	// ErrEncryptionNotSupported is returned when the key type has no public
+	// key encryption.
+	ErrEncryptionNotSupported = errors.New("public key encryption not supported")
+	// ErrInvalidCiphertext is returned when a ciphertext can't be decrypted.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// Ciphertext is a payload encrypted to a public key. Type is the crypto type
+// of the key, Data is the output of the encryption scheme of that type:
+//
+//	secp256k1: [ephemeral pubkey (33) || AES-256-GCM ciphertext || tag (16)]
+//	gm:        [0x04 || C1 (64) || C3 (32) || C2], GB/T 32918.4 SM2 encryption
+type Ciphertext struct {
+	Type string
+	Data []byte
+}
+
+// Bytes returns the bal encoding of the ciphertext.
+func (c *Ciphertext) Bytes() []byte {
+	return bal.MustEncodeToBytes(c)
+}
+
+// CiphertextFromBytes decodes a ciphertext encoded by Bytes.
+func CiphertextFromBytes(bz []byte) (*Ciphertext, error) {
+	c := new(Ciphertext)
+	if err := bal.DecodeBytes(bz, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Encrypt encrypts plaintext so that only the owner of the private key of
+// pubKey can read it.
+func Encrypt(pubKey PubKey, plaintext []byte) (*Ciphertext, error) {
+	algo := algorithmByPubKey(pubKey)
+	if algo == nil || algo.Encrypt == nil {
+		return nil, ErrEncryptionNotSupported
+	}
+	data, err := algo.Encrypt(pubKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &Ciphertext{Type: algo.Name, Data: data}, nil
+}
+
+// Decrypt returns the plaintext of a ciphertext made by Encrypt with the
+// public key of privKey.
+func Decrypt(privKey PrivKey, ciphertext *Ciphertext) ([]byte, error) {
+	algo := algorithmByName(privKey.Type())
+	if algo == nil || algo.Decrypt == nil {
+		return nil, ErrEncryptionNotSupported
+	}
+	if ciphertext.Type != algo.Name {
+		return nil, fmt.Errorf("ciphertext of type %s, private key of type %s", ciphertext.Type, algo.Name)
+	}
+	return algo.Decrypt(privKey, ciphertext.Data)
+}
+
+//-------------------------------------
+
+// ECIES over secp256k1. The key and the nonce of AES-256-GCM are derived
+// with HKDF-SHA256 from the x coordinate of the shared point, bound to the
+// ephemeral and the recipient public keys. The ephemeral key is fresh for
+// every message, so is the derived key.
+
+const eciesSecp256k1Info = "TC_ECIES_SECP256K1_HKDF_SHA256_AES256GCM"
+
+func eciesSecp256k1AEAD(sharedX *big.Int, ephemeral, recipient []byte) (cipher.AEAD, []byte, error) {
+	var secret [32]byte
+	xBytes := sharedX.Bytes()
+	copy(secret[32-len(xBytes):], xBytes)
+	info := make([]byte, 0, len(eciesSecp256k1Info)+2*33)
+	info = append(info, eciesSecp256k1Info...)
+	info = append(info, ephemeral...)
+	info = append(info, recipient...)
+
+	keyNonce := make([]byte, 32+12)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret[:], nil, info), keyNonce); err != nil {
+		return nil, nil, err
+	}
+	block, err := aes.NewCipher(keyNonce[:32])
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, keyNonce[32:], nil
+}
+
+func encryptSecp256k1(pubKey PubKey, plaintext []byte) ([]byte, error) {
+	pub := pubKey.(*PubKeySecp256k1).toECDSA()
+	if pub.X == nil {
+		return nil, errors.New("invalid public key")
+	}
+	curve := secp256k1.S256()
+
+	ephemeral, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sx, _ := curve.ScalarMult(pub.X, pub.Y, ephemeral.D.Bytes())
+	if sx == nil {
+		return nil, errors.New("invalid public key")
+	}
+	ephemeralPub := secp256k1.CompressPubkey(ephemeral.X, ephemeral.Y)
+	aead, nonce, err := eciesSecp256k1AEAD(sx, ephemeralPub, secp256k1.CompressPubkey(pub.X, pub.Y))
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(ephemeralPub, nonce, plaintext, nil), nil
+}
+
+func decryptSecp256k1(privKey PrivKey, data []byte) ([]byte, error) {
+	if len(data) < 33+16 {
+		return nil, ErrInvalidCiphertext
+	}
+	ex, ey := secp256k1.DecompressPubkey(data[:33])
+	if ex == nil {
+		return nil, ErrInvalidCiphertext
+	}
+	priv := privKey.(*PrivKeySecp256k1).toECDSA()
+	curve := secp256k1.S256()
+	sx, _ := curve.ScalarMult(ex, ey, priv.D.Bytes())
+	if sx == nil {
+		return nil, ErrInvalidCiphertext
+	}
+	aead, nonce, err := eciesSecp256k1AEAD(sx, data[:33], secp256k1.CompressPubkey(priv.X, priv.Y))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, data[33:], nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+//-------------------------------------
+
+func encryptGM(pubKey PubKey, plaintext []byte) ([]byte, error) {
+	// The SM2 key stream of an empty message is all zero, which the
+	// scheme rejects.
+	if len(plaintext) == 0 {
+		return nil, errors.New("sm2: can't encrypt an empty message")
+	}
+	return sm2.Encrypt(pubKey.(*PubKeyGM).key(), plaintext)
+}
+
+func decryptGM(privKey PrivKey, data []byte) ([]byte, error) {
+	if len(data) <= 1+64+32 || data[0] != 0x04 {
+		return nil, ErrInvalidCiphertext
+	}
+	// C1 must be checked, sm2.Decrypt multiplies it blindly.
+	x := new(big.Int).SetBytes(data[1:33])
+	y := new(big.Int).SetBytes(data[33:65])
+	if !sm2.P256Sm2().IsOnCurve(x, y) {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := sm2.Decrypt(privKey.(*PrivKeyGM).key(), data)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	for _, algo := range []string{CryptoTypeSecp256K1, CryptoTypeGM} {
+		privKey, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+		otherKey, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+
+		for _, msg := range [][]byte{[]byte("a"), []byte("private memo to the recipient"), make([]byte, 1000)} {
+			c, err := Encrypt(privKey.PubKey(), msg)
+			require.Nil(t, err, algo)
+			assert.Equal(t, algo, c.Type)
+
+			decoded, err := CiphertextFromBytes(c.Bytes())
+			require.Nil(t, err)
+			assert.Equal(t, c, decoded)
+
+			plaintext, err := Decrypt(privKey, decoded)
+			require.Nil(t, err, algo)
+			assert.Equal(t, msg, plaintext)
+
+			_, err = Decrypt(otherKey, c)
+			assert.NotNil(t, err, algo)
+
+			// Ciphertexts are randomized.
+			c2, err := Encrypt(privKey.PubKey(), msg)
+			require.Nil(t, err)
+			assert.NotEqual(t, c.Data, c2.Data)
+
+			for _, i := range []int{0, 1, 40, len(c.Data) - 1} {
+				bad := &Ciphertext{Type: c.Type, Data: append([]byte(nil), c.Data...)}
+				bad.Data[i] ^= 0x01
+				_, err = Decrypt(privKey, bad)
+				assert.NotNil(t, err, "%s byte %d", algo, i)
+			}
+			_, err = Decrypt(privKey, &Ciphertext{Type: c.Type, Data: c.Data[:len(c.Data)/3]})
+			assert.NotNil(t, err, algo)
+		}
+	}
+}
+
+func TestEncryptNotSupported(t *testing.T) {
+	privKey, err := GenPrivKeyEd25519()
+	require.Nil(t, err)
+	_, err = Encrypt(privKey.PubKey(), []byte("msg"))
+	assert.Equal(t, ErrEncryptionNotSupported, err)
+	_, err = Decrypt(privKey, &Ciphertext{Type: CryptoTypeEd25519})
+	assert.Equal(t, ErrEncryptionNotSupported, err)
+
+	secpKey, err := GenPrivKeySecp256k1()
+	require.Nil(t, err)
+	gmKey, err := GenPrivKeyGM()
+	require.Nil(t, err)
+	c, err := Encrypt(secpKey.PubKey(), []byte("msg"))
+	require.Nil(t, err)
+	_, err = Decrypt(gmKey, c)
+	assert.NotNil(t, err)
+
+	_, err = Encrypt(gmKey.PubKey(), nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,88 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/tjfoc/gmsm/sm3"
+	"golang.org/x/crypto/sha3"
+)
+
+// Hash suites, a chain hashes with one of them: the addresses of the account
+// keys and of the contracts, the signing hashes of types and accounts, and the
+// merkle trees. The suite is Keccak-256 whatever the account type, so that
+// existing chains keep their addresses and hashes, and a GM compliant chain
+// opts in to SM3 with its GM account keys:
+//
+//	crypto.SetLocalAccountType(crypto.CryptoTypeGM)
+//	crypto.SetLocalHashSuite(crypto.HashSuiteSM3)
+//
+// All the suites have 32 bytes digests, so hashes keep fitting common.Hash and
+// addresses are still the last 20 bytes of the hash of the public key.
+const (
+	HashSuiteKeccak256 = "keccak256"
+	HashSuiteSHA256    = "sha256"
+	HashSuiteSM3       = "sm3"
+)
+
+// HashSuite is a 32 bytes hash function.
+type HashSuite struct {
+	Name string
+	New  func() hash.Hash
+}
+
+// Sum returns the hash of the concatenation of data.
+func (suite *HashSuite) Sum(data ...[]byte) []byte {
+	d := suite.New()
+	for _, b := range data {
+		d.Write(b)
+	}
+	return d.Sum(nil)
+}
+
+// SumHash returns the hash of the concatenation of data as a common.Hash.
+func (suite *HashSuite) SumHash(data ...[]byte) (h common.Hash) {
+	d := suite.New()
+	for _, b := range data {
+		d.Write(b)
+	}
+	d.Sum(h[:0])
+	return h
+}
+
+var hashSuites = map[string]*HashSuite{
+	HashSuiteKeccak256: {Name: HashSuiteKeccak256, New: sha3.NewLegacyKeccak256},
+	HashSuiteSHA256:    {Name: HashSuiteSHA256, New: sha256.New},
+	HashSuiteSM3:       {Name: HashSuiteSM3, New: sm3.New},
+}
+
+// default hash suite
+var localHashSuite = hashSuites[HashSuiteKeccak256]
+
+// SetLocalHashSuite selects the hash suite of the chain by name, or the
+// default Keccak-256 again if name is empty. It must be called before any
+// hashing, like SetLocalAccountType.
+func SetLocalHashSuite(name string) {
+	if name == "" {
+		localHashSuite = hashSuites[HashSuiteKeccak256]
+		return
+	}
+	if suite, ok := hashSuites[name]; ok {
+		localHashSuite = suite
+		return
+	}
+	panic(fmt.Sprintf("Invalid HashSuite: %s", name))
+}
+
+// LocalHashSuite returns the hash suite of the chain.
+func LocalHashSuite() *HashSuite { return localHashSuite }
+
+// HashSuiteByName returns the hash suite of name, or nil.
+func HashSuiteByName(name string) *HashSuite { return hashSuites[name] }
+
+// LocalHash returns the hash of data with the hash suite of the chain.
+func LocalHash(data ...[]byte) []byte {
+	return localHashSuite.Sum(data...)
+}
@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashSuites(t *testing.T) {
+	for name, digest := range map[string]string{
+		HashSuiteKeccak256: "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+		HashSuiteSHA256:    "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		// GB/T 32905-2016 example 1
+		HashSuiteSM3: "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0",
+	} {
+		suite := HashSuiteByName(name)
+		require.NotNil(t, suite, name)
+		assert.Equal(t, digest, hex.EncodeToString(suite.Sum([]byte("a"), []byte("bc"))), name)
+		h := suite.SumHash([]byte("abc"))
+		assert.Equal(t, digest, hex.EncodeToString(h[:]), name)
+	}
+	assert.Nil(t, HashSuiteByName("md5"))
+	assert.Panics(t, func() { SetLocalHashSuite("md5") })
+}
+
+func TestLocalHashSuiteAddress(t *testing.T) {
+	privKey, err := GenPrivKeyGM()
+	require.Nil(t, err)
+	pubKey := privKey.PubKey()
+
+	assert.Equal(t, HashSuiteKeccak256, LocalHashSuite().Name)
+	assert.Equal(t, Address(Keccak256(pubKey.Raw()[1:])[12:]), pubKey.Address())
+
+	defer SetLocalHashSuite("")
+	SetLocalHashSuite(HashSuiteSM3)
+	assert.Equal(t, HashSuiteSM3, LocalHashSuite().Name)
+	assert.Equal(t, Address(HashSuiteByName(HashSuiteSM3).Sum(pubKey.Raw()[1:])[12:]), pubKey.Address())
+	assert.Equal(t, common.BytesToAddress(pubKey.Address()), PubkeyToAddress(pubKey))
+}
+
+func TestLocalHashSuiteAccountType(t *testing.T) {
+	defer SetLocalAccountType(LocalAccountType())
+	defer SetLocalHashSuite("")
+
+	// GM chains keep Keccak-256 until they select SM3
+	SetLocalAccountType(CryptoTypeGM)
+	assert.Equal(t, HashSuiteKeccak256, LocalHashSuite().Name)
+	b := common.BytesToAddress([]byte("creator"))
+	var salt [32]byte
+	assert.Equal(t, common.BytesToAddress(Keccak256([]byte{0xff}, b.Bytes(), salt[:], []byte("code"))[12:]), CreateAddress2(b, salt, []byte("code")))
+	keccakAddress := CreateAddress(b, 1, nil)
+
+	SetLocalHashSuite(HashSuiteSM3)
+	assert.Equal(t, HashSuiteSM3, LocalHashSuite().Name)
+	sm3 := HashSuiteByName(HashSuiteSM3)
+	assert.Equal(t, common.BytesToAddress(sm3.Sum([]byte{0xff}, b.Bytes(), salt[:], []byte("code"))[12:]), CreateAddress2(b, salt, []byte("code")))
+	assert.NotEqual(t, keccakAddress, CreateAddress(b, 1, nil))
+
+	SetLocalHashSuite("")
+	assert.Equal(t, HashSuiteKeccak256, LocalHashSuite().Name)
+	assert.Equal(t, keccakAddress, CreateAddress(b, 1, nil))
+}
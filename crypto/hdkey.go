@@ -0,0 +1,490 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/XunleiBlockchain/tc-libs/common/base58"
+	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/tjfoc/gmsm/sm2"
+	"golang.org/x/crypto/ed25519"
+)
+
+// Hierarchical deterministic keys of BIP32 for the secp256k1 and SM2 account
+// keys, and of SLIP-0010 for the ed25519 node keys. A master key is made from
+// a seed, every other key is derived from its parent by a child number, child
+// numbers from HardenedKeyStart on are hardened and can only be derived from a
+// private extended key:
+//
+//	master, err := crypto.NewMasterKey(seed, crypto.CryptoTypeSecp256K1)
+//	child, err := master.Derive(accounts.DefaultBaseDerivationPath)
+//	privKey, err := child.PrivKey()
+//
+// An invalid key, with a probability below 2^-127, is retried as in SLIP-0010:
+// the master key with the HMAC of the previous output, a child with the HMAC
+// of 0x01 || IR || index. BIP32 instead rejects the seed or moves to the next
+// index, so both only differ for such keys. SM2 keys use the same
+// construction with their own master key and serialization versions, "sprv"
+// and "spub".
+//
+// Ed25519 keys follow SLIP-0010: every child is hardened, so an extended
+// public key cannot derive any key. They serialize as "eprv" and "epub", with
+// the public key prefixed by a zero byte.
+
+const (
+	// HardenedKeyStart is the first hardened child number.
+	HardenedKeyStart uint32 = 0x80000000
+
+	// extendedKeyLen is the size of a serialized extended key, without checksum.
+	extendedKeyLen = 78
+)
+
+var (
+	// ErrInvalidSeedLen is returned when the seed is not between 16 and 64 bytes.
+	ErrInvalidSeedLen = errors.New("invalid seed length, need 16 to 64 bytes")
+	// ErrHardenedFromPublic is returned when a hardened child of a public
+	// extended key is requested.
+	ErrHardenedFromPublic = errors.New("cannot derive a hardened key from a public key")
+	// ErrDeriveBeyondMaxDepth is returned when the key is already at depth 255.
+	ErrDeriveBeyondMaxDepth = errors.New("cannot derive a key with more than 255 indices in its path")
+	// ErrNotPrivExtKey is returned when the private key of a public extended
+	// key is requested.
+	ErrNotPrivExtKey = errors.New("extended key is not a private key")
+	// ErrNotHardened is returned when a normal child of an ed25519 key is
+	// requested.
+	ErrNotHardened = errors.New("ed25519 keys only have hardened children")
+	// ErrInvalidExtendedKey is returned when a serialized extended key cannot
+	// be parsed.
+	ErrInvalidExtendedKey = errors.New("invalid extended key")
+)
+
+// hdCurve describes the curve of an extended key type. The ed25519 curve has
+// no elliptic.Curve and none of the point functions.
+type hdCurve struct {
+	cryptoType  string
+	seedKey     []byte
+	curve       elliptic.Curve
+	privVersion uint32
+	pubVersion  uint32
+
+	compress   func(x, y *big.Int) []byte
+	decompress func(data []byte) (x, y *big.Int)
+	privKey    func(d []byte) (PrivKey, error)
+	pubKey     func(x, y *big.Int) PubKey
+}
+
+var hdCurveSecp256k1 = &hdCurve{
+	cryptoType:  CryptoTypeSecp256K1,
+	seedKey:     []byte("Bitcoin seed"),
+	curve:       secp256k1.S256(),
+	privVersion: 0x0488ADE4,
+	pubVersion:  0x0488B21E,
+
+	compress:   secp256k1.CompressPubkey,
+	decompress: secp256k1.DecompressPubkey,
+	privKey: func(d []byte) (PrivKey, error) {
+		return GenPrivKeySecp256k1FromSecret(d)
+	},
+	pubKey: func(x, y *big.Int) PubKey {
+		return makePubKeySecp256k1(&ecdsa.PublicKey{Curve: S256(), X: x, Y: y})
+	},
+}
+
+var hdCurveGM = &hdCurve{
+	cryptoType:  CryptoTypeGM,
+	seedKey:     []byte("SM2 seed"),
+	curve:       sm2.P256Sm2(),
+	privVersion: 0x0420B900,
+	pubVersion:  0x0420BD3A,
+
+	compress: func(x, y *big.Int) []byte {
+		return compressPoint(x, y)
+	},
+	decompress: func(data []byte) (x, y *big.Int) {
+		return decompressPointA3(sm2.P256Sm2(), data)
+	},
+	privKey: func(d []byte) (PrivKey, error) {
+		return GenPrivKeyGMFromSecret(d)
+	},
+	pubKey: func(x, y *big.Int) PubKey {
+		return makePubKeyGM(&sm2.PublicKey{Curve: sm2.P256Sm2(), X: x, Y: y})
+	},
+}
+
+var hdCurveEd25519 = &hdCurve{
+	cryptoType:  CryptoTypeEd25519,
+	seedKey:     []byte("ed25519 seed"),
+	privVersion: 0x03126F7C,
+	pubVersion:  0x031273B7,
+
+	privKey: func(d []byte) (PrivKey, error) {
+		return privKeyEd25519FromSeed(d), nil
+	},
+}
+
+func hdCurveByType(cryptoType string) *hdCurve {
+	switch cryptoType {
+	case CryptoTypeSecp256K1:
+		return hdCurveSecp256k1
+	case CryptoTypeGM:
+		return hdCurveGM
+	case CryptoTypeEd25519:
+		return hdCurveEd25519
+	}
+	return nil
+}
+
+func hdCurveByVersion(version uint32) (c *hdCurve, private bool) {
+	for _, c := range []*hdCurve{hdCurveSecp256k1, hdCurveGM, hdCurveEd25519} {
+		switch version {
+		case c.privVersion:
+			return c, true
+		case c.pubVersion:
+			return c, false
+		}
+	}
+	return nil, false
+}
+
+// hardenedOnly reports whether the curve only has hardened children.
+func (c *hdCurve) hardenedOnly() bool {
+	return c.curve == nil
+}
+
+// validPrivKey reports whether d is a private key of the curve.
+func (c *hdCurve) validPrivKey(d []byte) bool {
+	if c.hardenedOnly() {
+		return true
+	}
+	k := new(big.Int).SetBytes(d)
+	return k.Sign() != 0 && k.Cmp(c.curve.Params().N) < 0
+}
+
+// validPubKey reports whether data is a serialized public key of the curve.
+func (c *hdCurve) validPubKey(data []byte) bool {
+	if c.hardenedOnly() {
+		var buf [32]byte
+		var p edwards25519.ExtendedPoint
+		copy(buf[:], data[1:])
+		return data[0] == 0x00 && decodeEdPoint(&p, &buf)
+	}
+	x, _ := c.decompress(data)
+	return x != nil
+}
+
+// publicKey returns the serialized public key of the private key d.
+func (c *hdCurve) publicKey(d []byte) []byte {
+	if c.hardenedOnly() {
+		priv := ed25519.NewKeyFromSeed(d)
+		return append([]byte{0x00}, priv[32:]...)
+	}
+	x, y := c.curve.ScalarBaseMult(d)
+	return c.compress(x, y)
+}
+
+// compressPoint encodes a point in the 33 bytes SEC 1 compressed format.
+func compressPoint(x, y *big.Int) []byte {
+	out := make([]byte, 33)
+	out[0] = 0x02 | byte(y.Bit(0))
+	math.ReadBits(x, out[1:])
+	return out
+}
+
+// decompressPointA3 decodes a compressed point of a curve with a = -3, it
+// returns nil if the point is invalid.
+func decompressPointA3(curve elliptic.Curve, data []byte) (x, y *big.Int) {
+	params := curve.Params()
+	if len(data) != 33 || (data[0] != 0x02 && data[0] != 0x03) {
+		return nil, nil
+	}
+	x = new(big.Int).SetBytes(data[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil
+	}
+	// y² = x³ - 3x + b
+	y2 := new(big.Int).Mul(x, x)
+	y2.Mul(y2, x)
+	y2.Sub(y2, new(big.Int).Lsh(x, 1))
+	y2.Sub(y2, x)
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+	y = new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil, nil
+	}
+	if y.Bit(0) != uint(data[0]&1) {
+		y.Sub(params.P, y)
+	}
+	return x, y
+}
+
+func hash160(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return Ripemd160(h[:])
+}
+
+//-------------------------------------
+
+// ExtendedKey is a BIP32 extended private or public key.
+type ExtendedKey struct {
+	curve       *hdCurve
+	key         []byte // 32 bytes private key or 33 bytes compressed public key
+	chainCode   []byte
+	parentFP    []byte
+	depth       uint8
+	childNum    uint32
+	isPrivate   bool
+	pubKeyCache []byte
+}
+
+// NewMasterKey returns the master extended private key of seed for the
+// secp256k1, the gm or the ed25519 key type.
+func NewMasterKey(seed []byte, cryptoType string) (*ExtendedKey, error) {
+	c := hdCurveByType(cryptoType)
+	if c == nil {
+		return nil, ErrInvalidCryptoType
+	}
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ErrInvalidSeedLen
+	}
+
+	data := seed
+	for {
+		mac := hmac.New(sha512.New, c.seedKey)
+		mac.Write(data)
+		I := mac.Sum(nil)
+		if c.validPrivKey(I[:32]) {
+			return &ExtendedKey{
+				curve:     c,
+				key:       I[:32],
+				chainCode: I[32:],
+				parentFP:  []byte{0, 0, 0, 0},
+				isPrivate: true,
+			}, nil
+		}
+		data = I
+	}
+}
+
+// Type returns the key type of the extended key.
+func (k *ExtendedKey) Type() string {
+	return k.curve.cryptoType
+}
+
+// IsPrivate reports whether k is an extended private key.
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.isPrivate
+}
+
+// Depth returns the number of derivations from the master key.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// ChildNumber returns the child number of k, zero for the master key.
+func (k *ExtendedKey) ChildNumber() uint32 {
+	return k.childNum
+}
+
+// pubKeyBytes returns the compressed public key.
+func (k *ExtendedKey) pubKeyBytes() []byte {
+	if !k.isPrivate {
+		return k.key
+	}
+	if k.pubKeyCache == nil {
+		k.pubKeyCache = k.curve.publicKey(k.key)
+	}
+	return k.pubKeyCache
+}
+
+// Child returns the child key i of k. A private key gives a private child, a
+// public key gives a public child and cannot have hardened children.
+func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
+	if k.depth == 255 {
+		return nil, ErrDeriveBeyondMaxDepth
+	}
+	hardened := i >= HardenedKeyStart
+	if !hardened && k.curve.hardenedOnly() {
+		return nil, ErrNotHardened
+	}
+	if hardened && !k.isPrivate {
+		return nil, ErrHardenedFromPublic
+	}
+
+	data := make([]byte, 37)
+	if hardened {
+		copy(data[1:33], k.key)
+	} else {
+		copy(data[:33], k.pubKeyBytes())
+	}
+	binary.BigEndian.PutUint32(data[33:], i)
+
+	for {
+		mac := hmac.New(sha512.New, k.chainCode)
+		mac.Write(data)
+		I := mac.Sum(nil)
+
+		child := &ExtendedKey{
+			curve:     k.curve,
+			chainCode: I[32:],
+			parentFP:  hash160(k.pubKeyBytes())[:4],
+			depth:     k.depth + 1,
+			childNum:  i,
+			isPrivate: k.isPrivate,
+		}
+		if k.curve.hardenedOnly() {
+			child.key = I[:32]
+			return child, nil
+		}
+
+		curve := k.curve.curve
+		N := curve.Params().N
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(N) < 0 {
+			if k.isPrivate {
+				il.Add(il, new(big.Int).SetBytes(k.key))
+				il.Mod(il, N)
+				if il.Sign() != 0 {
+					child.key = math.PaddedBigBytes(il, 32)
+					return child, nil
+				}
+			} else {
+				px, py := k.curve.decompress(k.key)
+				x, y := curve.ScalarBaseMult(I[:32])
+				x, y = curve.Add(x, y, px, py)
+				if x.Sign() != 0 || y.Sign() != 0 {
+					child.key = k.curve.compress(x, y)
+					return child, nil
+				}
+			}
+		}
+		// The key is invalid, go on with the next one as in SLIP-0010.
+		data = append([]byte{0x01}, I[32:]...)
+		data = append(data, byte(i>>24), byte(i>>16), byte(i>>8), byte(i))
+	}
+}
+
+// Derive returns the key at path from k, path is relative to k. An
+// accounts.DerivationPath can be passed directly.
+func (k *ExtendedKey) Derive(path []uint32) (*ExtendedKey, error) {
+	key := k
+	for _, i := range path {
+		var err error
+		if key, err = key.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Neuter returns the extended public key of k.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.isPrivate {
+		return k
+	}
+	return &ExtendedKey{
+		curve:     k.curve,
+		key:       k.pubKeyBytes(),
+		chainCode: append([]byte(nil), k.chainCode...),
+		parentFP:  append([]byte(nil), k.parentFP...),
+		depth:     k.depth,
+		childNum:  k.childNum,
+	}
+}
+
+// PrivKey returns the private key of an extended private key.
+func (k *ExtendedKey) PrivKey() (PrivKey, error) {
+	if !k.isPrivate {
+		return nil, ErrNotPrivExtKey
+	}
+	d := make([]byte, len(k.key))
+	copy(d, k.key)
+	return k.curve.privKey(d)
+}
+
+// PubKey returns the public key of k.
+func (k *ExtendedKey) PubKey() PubKey {
+	if k.curve.hardenedOnly() {
+		var pubKey PubKeyEd25519
+		copy(pubKey[:], k.pubKeyBytes()[1:])
+		return pubKey
+	}
+	x, y := k.curve.decompress(k.pubKeyBytes())
+	return k.curve.pubKey(x, y)
+}
+
+// String returns the base58check serialization of k, like "xprv..." or
+// "xpub..." for secp256k1 keys.
+func (k *ExtendedKey) String() string {
+	buf := make([]byte, 0, extendedKeyLen)
+	version := k.curve.pubVersion
+	if k.isPrivate {
+		version = k.curve.privVersion
+	}
+	buf = append(buf, byte(version>>24), byte(version>>16), byte(version>>8), byte(version))
+	buf = append(buf, k.depth)
+	buf = append(buf, k.parentFP...)
+	buf = append(buf, byte(k.childNum>>24), byte(k.childNum>>16), byte(k.childNum>>8), byte(k.childNum))
+	buf = append(buf, k.chainCode...)
+	if k.isPrivate {
+		buf = append(buf, 0x00)
+	}
+	buf = append(buf, k.key...)
+	return base58.CheckEncode(buf)
+}
+
+// Reset clears the private key and the chain code.
+func (k *ExtendedKey) Reset() {
+	if k.isPrivate {
+		zeroBytes(k.key)
+	}
+	zeroBytes(k.chainCode)
+}
+
+// ParseExtendedKey parses a key serialized by ExtendedKey.String.
+func ParseExtendedKey(s string) (*ExtendedKey, error) {
+	buf, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) != extendedKeyLen {
+		return nil, ErrInvalidExtendedKey
+	}
+	c, private := hdCurveByVersion(binary.BigEndian.Uint32(buf[:4]))
+	if c == nil {
+		return nil, fmt.Errorf("%v: unknown version %x", ErrInvalidExtendedKey, buf[:4])
+	}
+
+	k := &ExtendedKey{
+		curve:     c,
+		depth:     buf[4],
+		parentFP:  buf[5:9],
+		childNum:  binary.BigEndian.Uint32(buf[9:13]),
+		chainCode: buf[13:45],
+		isPrivate: private,
+	}
+	if k.depth == 0 && (k.childNum != 0 || binary.BigEndian.Uint32(k.parentFP) != 0) {
+		return nil, ErrInvalidExtendedKey
+	}
+	if private {
+		if buf[45] != 0x00 || !c.validPrivKey(buf[46:]) {
+			return nil, ErrInvalidExtendedKey
+		}
+		k.key = buf[46:]
+	} else {
+		if !c.validPubKey(buf[45:]) {
+			return nil, ErrInvalidExtendedKey
+		}
+		k.key = buf[45:]
+	}
+	return k, nil
+}
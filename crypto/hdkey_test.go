@@ -0,0 +1,326 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BIP32 test vectors 1 and 2.
+var bip32Vectors = []struct {
+	seed string
+	path []uint32
+	xpub string
+	xprv string
+}{
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		xpub: "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		xprv: "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+	},
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		path: []uint32{HardenedKeyStart},
+		xpub: "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		xprv: "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+	},
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		path: []uint32{HardenedKeyStart, 1},
+		xpub: "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		xprv: "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+	},
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		path: []uint32{HardenedKeyStart, 1, HardenedKeyStart + 2},
+		xpub: "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5",
+		xprv: "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM",
+	},
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		path: []uint32{HardenedKeyStart, 1, HardenedKeyStart + 2, 2},
+		xpub: "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV",
+		xprv: "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334",
+	},
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		path: []uint32{HardenedKeyStart, 1, HardenedKeyStart + 2, 2, 1000000000},
+		xpub: "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+		xprv: "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+	},
+	{
+		seed: "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		xpub: "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB",
+		xprv: "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U",
+	},
+	{
+		seed: "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path: []uint32{0},
+		xpub: "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH",
+		xprv: "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt",
+	},
+	{
+		seed: "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path: []uint32{0, HardenedKeyStart + 2147483647},
+		xpub: "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a",
+		xprv: "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9",
+	},
+}
+
+func TestExtendedKeyBIP32Vectors(t *testing.T) {
+	for _, v := range bip32Vectors {
+		master, err := NewMasterKey(mustHex(t, v.seed), CryptoTypeSecp256K1)
+		require.Nil(t, err)
+		key, err := master.Derive(v.path)
+		require.Nil(t, err)
+		assert.Equal(t, v.xprv, key.String(), "%x", v.path)
+		assert.Equal(t, v.xpub, key.Neuter().String(), "%x", v.path)
+		assert.Equal(t, uint8(len(v.path)), key.Depth())
+
+		parsed, err := ParseExtendedKey(v.xprv)
+		require.Nil(t, err)
+		assert.Equal(t, v.xprv, parsed.String())
+		parsed, err = ParseExtendedKey(v.xpub)
+		require.Nil(t, err)
+		assert.False(t, parsed.IsPrivate())
+		assert.Equal(t, v.xpub, parsed.String())
+	}
+}
+
+func TestExtendedKeyPublicDerivation(t *testing.T) {
+	seed := CRandBytes(32)
+	for _, cryptoType := range []string{CryptoTypeSecp256K1, CryptoTypeGM} {
+		master, err := NewMasterKey(seed, cryptoType)
+		require.Nil(t, err)
+		account, err := master.Derive([]uint32{HardenedKeyStart + 44, HardenedKeyStart + 60, HardenedKeyStart})
+		require.Nil(t, err)
+
+		// Normal children of the public key are the public keys of the
+		// children of the private key.
+		path := []uint32{0, 7}
+		privChild, err := account.Derive(path)
+		require.Nil(t, err)
+		pubChild, err := account.Neuter().Derive(path)
+		require.Nil(t, err)
+		assert.Equal(t, privChild.Neuter().String(), pubChild.String(), cryptoType)
+
+		_, err = account.Neuter().Child(HardenedKeyStart)
+		assert.Equal(t, ErrHardenedFromPublic, err)
+		_, err = pubChild.PrivKey()
+		assert.Equal(t, ErrNotPrivExtKey, err)
+
+		privKey, err := privChild.PrivKey()
+		require.Nil(t, err)
+		assert.Equal(t, cryptoType, privKey.Type())
+		assert.True(t, privKey.PubKey().Equals(pubChild.PubKey()), cryptoType)
+
+		msg := Keccak256([]byte("hd key"))
+		sig, err := privKey.Sign(msg)
+		require.Nil(t, err)
+		assert.True(t, pubChild.PubKey().VerifyBytes(msg, sig), cryptoType)
+
+		parsed, err := ParseExtendedKey(privChild.String())
+		require.Nil(t, err)
+		assert.Equal(t, cryptoType, parsed.Type())
+		parsedPriv, err := parsed.PrivKey()
+		require.Nil(t, err)
+		assert.True(t, privKey.Equals(parsedPriv))
+	}
+}
+
+func TestExtendedKeyGMSerialization(t *testing.T) {
+	master, err := NewMasterKey(CRandBytes(64), CryptoTypeGM)
+	require.Nil(t, err)
+	assert.Equal(t, "sprv", master.String()[:4])
+	assert.Equal(t, "spub", master.Neuter().String()[:4])
+
+	// The same seed gives unrelated keys on the two curves.
+	secpMaster, err := NewMasterKey(mustHex(t, "000102030405060708090a0b0c0d0e0f"), CryptoTypeSecp256K1)
+	require.Nil(t, err)
+	gmMaster, err := NewMasterKey(mustHex(t, "000102030405060708090a0b0c0d0e0f"), CryptoTypeGM)
+	require.Nil(t, err)
+	assert.NotEqual(t, secpMaster.key, gmMaster.key)
+}
+
+func TestExtendedKeyErrors(t *testing.T) {
+	_, err := NewMasterKey(make([]byte, 15), CryptoTypeSecp256K1)
+	assert.Equal(t, ErrInvalidSeedLen, err)
+	_, err = NewMasterKey(make([]byte, 65), CryptoTypeSecp256K1)
+	assert.Equal(t, ErrInvalidSeedLen, err)
+	_, err = NewMasterKey(make([]byte, 32), CryptoTypeBLS)
+	assert.Equal(t, ErrInvalidCryptoType, err)
+
+	// Bad checksum.
+	_, err = ParseExtendedKey("xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet7")
+	assert.NotNil(t, err)
+	// Public key with a 0x04 prefix (BIP32 test vector 5).
+	_, err = ParseExtendedKey("xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6Q5JXayek4PRsn35jii4veMimro1xefsM58PgBMrvdYre8QyULY")
+	assert.NotNil(t, err)
+}
+
+// SLIP-0010 test vectors 1 and 2 for ed25519.
+var slip10Ed25519Vectors = []struct {
+	seed        string
+	path        []uint32
+	fingerprint string
+	chainCode   string
+	key         string
+	pubKey      string
+}{
+	{
+		seed:        "000102030405060708090a0b0c0d0e0f",
+		fingerprint: "00000000",
+		chainCode:   "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+		key:         "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		pubKey:      "00a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+	},
+	{
+		seed:        "000102030405060708090a0b0c0d0e0f",
+		path:        []uint32{HardenedKeyStart},
+		fingerprint: "ddebc675",
+		chainCode:   "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+		key:         "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		pubKey:      "008c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+	},
+	{
+		seed:        "000102030405060708090a0b0c0d0e0f",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 1},
+		fingerprint: "13dab143",
+		chainCode:   "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+		key:         "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		pubKey:      "001932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+	},
+	{
+		seed:        "000102030405060708090a0b0c0d0e0f",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 1, HardenedKeyStart + 2},
+		fingerprint: "ebe4cb29",
+		chainCode:   "2e69929e00b5ab250f49c3fb1c12f252de4fed2c1db88387094a0f8c4c9ccd6c",
+		key:         "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+		pubKey:      "00ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1",
+	},
+	{
+		seed:        "000102030405060708090a0b0c0d0e0f",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 1, HardenedKeyStart + 2, HardenedKeyStart + 2},
+		fingerprint: "316ec1c6",
+		chainCode:   "8f6d87f93d750e0efccda017d662a1b31a266e4a6f5993b15f5c1f07f74dd5cc",
+		key:         "30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+		pubKey:      "008abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c",
+	},
+	{
+		seed:        "000102030405060708090a0b0c0d0e0f",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 1, HardenedKeyStart + 2, HardenedKeyStart + 2, HardenedKeyStart + 1000000000},
+		fingerprint: "d6322ccd",
+		chainCode:   "68789923a0cac2cd5a29172a475fe9e0fb14cd6adb5ad98a3fa70333e7afa230",
+		key:         "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+		pubKey:      "003c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a",
+	},
+	{
+		seed:        "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		fingerprint: "00000000",
+		chainCode:   "ef70a74db9c3a5af931b5fe73ed8e1a53464133654fd55e7a66f8570b8e33c3b",
+		key:         "171cb88b1b3c1db25add599712e36245d75bc65a1a5c9e18d76f9f2b1eab4012",
+		pubKey:      "008fe9693f8fa62a4305a140b9764c5ee01e455963744fe18204b4fb948249308a",
+	},
+	{
+		seed:        "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path:        []uint32{HardenedKeyStart},
+		fingerprint: "31981b50",
+		chainCode:   "0b78a3226f915c082bf118f83618a618ab6dec793752624cbeb622acb562862d",
+		key:         "1559eb2bbec5790b0c65d8693e4d0875b1747f4970ae8b650486ed7470845635",
+		pubKey:      "0086fab68dcb57aa196c77c5f264f215a112c22a912c10d123b0d03c3c28ef1037",
+	},
+	{
+		seed:        "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 2147483647},
+		fingerprint: "1e9411b1",
+		chainCode:   "138f0b2551bcafeca6ff2aa88ba8ed0ed8de070841f0c4ef0165df8181eaad7f",
+		key:         "ea4f5bfe8694d8bb74b7b59404632fd5968b774ed545e810de9c32a4fb4192f4",
+		pubKey:      "005ba3b9ac6e90e83effcd25ac4e58a1365a9e35a3d3ae5eb07b9e4d90bcf7506d",
+	},
+	{
+		seed:        "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 2147483647, HardenedKeyStart + 1},
+		fingerprint: "fcadf38c",
+		chainCode:   "73bd9fff1cfbde33a1b846c27085f711c0fe2d66fd32e139d3ebc28e5a4a6b90",
+		key:         "3757c7577170179c7868353ada796c839135b3d30554bbb74a4b1e4a5a58505c",
+		pubKey:      "002e66aa57069c86cc18249aecf5cb5a9cebbfd6fadeab056254763874a9352b45",
+	},
+	{
+		seed:        "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 2147483647, HardenedKeyStart + 1, HardenedKeyStart + 2147483646},
+		fingerprint: "aca70953",
+		chainCode:   "0902fe8a29f9140480a00ef244bd183e8a13288e4412d8389d140aac1794825a",
+		key:         "5837736c89570de861ebc173b1086da4f505d4adb387c6a1b1342d5e4ac9ec72",
+		pubKey:      "00e33c0f7d81d843c572275f287498e8d408654fdf0d1e065b84e2e6f157aab09b",
+	},
+	{
+		seed:        "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		path:        []uint32{HardenedKeyStart, HardenedKeyStart + 2147483647, HardenedKeyStart + 1, HardenedKeyStart + 2147483646, HardenedKeyStart + 2},
+		fingerprint: "422c654b",
+		chainCode:   "5d70af781f3a37b829f0d060924d5e960bdc02e85423494afc0b1a41bbe196d4",
+		key:         "551d333177df541ad876a60ea71f00447931c0a9da16f227c11ea080d7391b8d",
+		pubKey:      "0047150c75db263559a70d5778bf36abbab30fb061ad69f69ece61a72b0cfa4fc0",
+	},
+}
+
+func TestExtendedKeySLIP10Ed25519Vectors(t *testing.T) {
+	for _, v := range slip10Ed25519Vectors {
+		master, err := NewMasterKey(mustHex(t, v.seed), CryptoTypeEd25519)
+		require.Nil(t, err)
+		key, err := master.Derive(v.path)
+		require.Nil(t, err)
+		assert.Equal(t, v.fingerprint, hex.EncodeToString(key.parentFP), "%x", v.path)
+		assert.Equal(t, v.chainCode, hex.EncodeToString(key.chainCode), "%x", v.path)
+		assert.Equal(t, v.key, hex.EncodeToString(key.key), "%x", v.path)
+		assert.Equal(t, v.pubKey, hex.EncodeToString(key.Neuter().key), "%x", v.path)
+
+		privKey, err := key.PrivKey()
+		require.Nil(t, err)
+		pubKey := privKey.PubKey().(PubKeyEd25519)
+		assert.Equal(t, v.pubKey[2:], hex.EncodeToString(pubKey[:]))
+		assert.True(t, privKey.PubKey().Equals(key.PubKey()))
+	}
+}
+
+func TestExtendedKeyEd25519(t *testing.T) {
+	master, err := NewMasterKey(CRandBytes(32), CryptoTypeEd25519)
+	require.Nil(t, err)
+	assert.Equal(t, "eprv", master.String()[:4])
+	assert.Equal(t, "epub", master.Neuter().String()[:4])
+
+	_, err = master.Child(0)
+	assert.Equal(t, ErrNotHardened, err)
+	_, err = master.Neuter().Child(HardenedKeyStart)
+	assert.Equal(t, ErrHardenedFromPublic, err)
+
+	key, err := master.Derive([]uint32{HardenedKeyStart + 44, HardenedKeyStart + 1})
+	require.Nil(t, err)
+	privKey, err := key.PrivKey()
+	require.Nil(t, err)
+	assert.Equal(t, CryptoTypeEd25519, privKey.Type())
+	msg := []byte("node key")
+	sig, err := privKey.Sign(msg)
+	require.Nil(t, err)
+	assert.True(t, key.PubKey().VerifyBytes(msg, sig))
+
+	for _, k := range []*ExtendedKey{key, key.Neuter()} {
+		parsed, err := ParseExtendedKey(k.String())
+		require.Nil(t, err)
+		assert.Equal(t, CryptoTypeEd25519, parsed.Type())
+		assert.Equal(t, k.String(), parsed.String())
+		assert.True(t, key.PubKey().Equals(parsed.PubKey()))
+	}
+}
+
+func TestExtendedKeyNeuterReset(t *testing.T) {
+	master, err := NewMasterKey(Keccak256([]byte("seed")), CryptoTypeSecp256K1)
+	require.Nil(t, err)
+	pub := master.Neuter()
+	xpub := pub.String()
+
+	// the public key keeps its chain code when the private key is reset
+	master.Reset()
+	assert.Equal(t, xpub, pub.String())
+}
@@ -2,7 +2,7 @@ package merkle
 
 import (
 	cmn "github.com/XunleiBlockchain/tc-libs/common"
-	"golang.org/x/crypto/sha3"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 )
 
 // Merkle tree from a map.
@@ -67,7 +67,7 @@ func (sm *simpleMap) KVPairs() cmn.KVPairs {
 type KVPair cmn.KVPair
 
 func (kv KVPair) Hash() []byte {
-	hasher := sha3.NewLegacyKeccak256()
+	hasher := crypto.LocalHashSuite().New()
 	err := encodeByteSlice(hasher, kv.Key)
 	if err != nil {
 		panic(err)
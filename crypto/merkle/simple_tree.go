@@ -1,12 +1,13 @@
 package merkle
 
 import (
-	"golang.org/x/crypto/sha3"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 )
 
-// SimpleHashFromTwoHashes is the basic operation of the Merkle tree: Hash(left | right).
+// SimpleHashFromTwoHashes is the basic operation of the Merkle tree: Hash(left | right),
+// with the local hash suite.
 func SimpleHashFromTwoHashes(left, right []byte) []byte {
-	var hasher = sha3.NewLegacyKeccak256()
+	var hasher = crypto.LocalHashSuite().New()
 	err := encodeByteSlice(hasher, left)
 	if err != nil {
 		panic(err)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	cmn "github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 )
 
 type testItem []byte
@@ -107,3 +108,20 @@ func TestSimpleProof(t *testing.T) {
 		}
 	}
 }
+
+func TestSimpleProofHashSuite(t *testing.T) {
+	items := []Hasher{testItem("a"), testItem("b"), testItem("c")}
+	keccakRoot, _ := SimpleProofsFromHashers(items)
+
+	defer crypto.SetLocalHashSuite("")
+	crypto.SetLocalHashSuite(crypto.HashSuiteSM3)
+	rootHash, proofs := SimpleProofsFromHashers(items)
+	if bytes.Equal(keccakRoot, rootHash) {
+		t.Fatalf("root hash does not depend on the hash suite")
+	}
+	for i, item := range items {
+		if !proofs[i].Verify(i, len(items), item.Hash(), rootHash) {
+			t.Fatalf("proof %d does not verify", i)
+		}
+	}
+}
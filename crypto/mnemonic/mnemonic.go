@@ -0,0 +1,212 @@
+// Package mnemonic implements the mnemonic sentences of BIP39, a way to back
+// up the seed of a key as a list of words:
+//
+//	entropy, err := mnemonic.NewEntropy(128)
+//	words, err := mnemonic.NewMnemonic(entropy, mnemonic.English)
+//	seed, err := mnemonic.NewSeedWithChecking(words, passphrase, mnemonic.English)
+//	privKey, err := mnemonic.DerivePrivKey(seed, crypto.CryptoTypeSecp256K1, accounts.DefaultBaseDerivationPath)
+//
+// The mnemonic and the passphrase are normalized to NFKD, which only matters
+// for mnemonics and passphrases outside of ASCII.
+package mnemonic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"strings"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+	"github.com/XunleiBlockchain/tc-libs/crypto/randentropy"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// SeedSize is the size of the seed of a mnemonic.
+	SeedSize = 64
+
+	seedIterations = 2048
+
+	// maxSecretTries bounds the search for a valid secret, an invalid secret
+	// has a probability below 2^-127 for the built in key types.
+	maxSecretTries = 64
+)
+
+var (
+	// ErrEntropyLen is returned when the entropy is not 128 to 256 bits and a
+	// multiple of 32 bits.
+	ErrEntropyLen = errors.New("entropy length must be 128 to 256 bits and a multiple of 32 bits")
+	// ErrInvalidMnemonic is returned when a mnemonic has a wrong number of
+	// words or a word that is not in the wordlist.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	// ErrChecksumIncorrect is returned when the checksum of a mnemonic does
+	// not match its entropy.
+	ErrChecksumIncorrect = errors.New("mnemonic checksum incorrect")
+	// ErrPathNotSupported is returned when a derivation path is given for a key
+	// type without hierarchical deterministic keys.
+	ErrPathNotSupported = errors.New("key type does not support derivation paths")
+)
+
+func validEntropyBits(bits int) bool {
+	return bits >= 128 && bits <= 256 && bits%32 == 0
+}
+
+// NewEntropy returns bitSize bits of random entropy.
+func NewEntropy(bitSize int) ([]byte, error) {
+	if !validEntropyBits(bitSize) {
+		return nil, ErrEntropyLen
+	}
+	return randentropy.GetEntropyCSPRNG(bitSize / 8), nil
+}
+
+// NewMnemonic returns the mnemonic of entropy, with the words separated by
+// a single space.
+func NewMnemonic(entropy []byte, wl *Wordlist) (string, error) {
+	bits := len(entropy) * 8
+	if !validEntropyBits(bits) {
+		return "", ErrEntropyLen
+	}
+	// The checksum is the first bits/32 bits of the hash, never more than a byte.
+	hash := sha256.Sum256(entropy)
+	data := append(append(make([]byte, 0, len(entropy)+1), entropy...), hash[0])
+
+	n := (bits + bits/32) / 11
+	words := make([]string, n)
+	for i := range words {
+		words[i] = wl.Word(readBits(data, i*11, 11))
+	}
+	return strings.Join(words, " "), nil
+}
+
+// Entropy returns the entropy of mnemonic, checking its words and checksum.
+func Entropy(mnemonic string, wl *Wordlist) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	n := len(words)
+	if n%3 != 0 || !validEntropyBits(n*11*32/33) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	data := make([]byte, (n*11+7)/8)
+	for i, w := range words {
+		idx, ok := wl.Index(w)
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+		writeBits(data, i*11, 11, idx)
+	}
+
+	bits := n * 11 * 32 / 33
+	entropy := data[:bits/8]
+	checksumBits := uint(bits / 32)
+	hash := sha256.Sum256(entropy)
+	if hash[0]>>(8-checksumBits) != data[bits/8]>>(8-checksumBits) {
+		return nil, ErrChecksumIncorrect
+	}
+	return append([]byte{}, entropy...), nil
+}
+
+// IsValid reports whether mnemonic is a valid mnemonic of wl.
+func IsValid(mnemonic string, wl *Wordlist) bool {
+	_, err := Entropy(mnemonic, wl)
+	return err == nil
+}
+
+// NewSeed returns the seed of mnemonic and passphrase, without checking the
+// mnemonic. Runs of whitespace between the words count as a single space, and
+// both are normalized to NFKD as BIP39 requires.
+func NewSeed(mnemonic, passphrase string) []byte {
+	mnemonic = norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+	passphrase = norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), seedIterations, SeedSize, sha512.New)
+}
+
+// NewSeedWithChecking returns the seed of mnemonic and passphrase, like
+// NewSeed, after checking that mnemonic is valid for wl.
+func NewSeedWithChecking(mnemonic, passphrase string, wl *Wordlist) ([]byte, error) {
+	if _, err := Entropy(mnemonic, wl); err != nil {
+		return nil, err
+	}
+	return NewSeed(mnemonic, passphrase), nil
+}
+
+// DerivePrivKey returns the private key of cryptoType for seed.
+//
+// The secp256k1 and gm keys are the BIP32 keys at path, the ed25519 keys are
+// the SLIP-0010 keys at path, which must be hardened. Key types without
+// hierarchical deterministic keys only have a master key and need an empty
+// path: its secret is the left half of HMAC-SHA512("<cryptoType> seed", seed),
+// hashed again the same way while it is not a valid secret for the type.
+func DerivePrivKey(seed []byte, cryptoType string, path []uint32) (crypto.PrivKey, error) {
+	master, err := crypto.NewMasterKey(seed, cryptoType)
+	if err == nil {
+		key, err := master.Derive(path)
+		if err != nil {
+			return nil, err
+		}
+		defer key.Reset()
+		defer master.Reset()
+		return key.PrivKey()
+	}
+	if err != crypto.ErrInvalidCryptoType {
+		return nil, err
+	}
+	if len(path) != 0 {
+		return nil, ErrPathNotSupported
+	}
+	if !knownCryptoType(cryptoType) {
+		return nil, crypto.ErrInvalidCryptoType
+	}
+
+	data := seed
+	for i := 0; i < maxSecretTries; i++ {
+		mac := hmac.New(sha512.New, []byte(cryptoType+" seed"))
+		mac.Write(data)
+		data = mac.Sum(nil)
+		var privKey crypto.PrivKey
+		privKey, err = crypto.GeneratePrivKeyFromSecret(data[:32], cryptoType)
+		if err == nil {
+			return privKey, nil
+		}
+	}
+	return nil, err
+}
+
+// NewPrivKey returns the private key of cryptoType at path for mnemonic and
+// passphrase, see DerivePrivKey.
+func NewPrivKey(mnemonic, passphrase string, wl *Wordlist, cryptoType string, path []uint32) (crypto.PrivKey, error) {
+	seed, err := NewSeedWithChecking(mnemonic, passphrase, wl)
+	if err != nil {
+		return nil, err
+	}
+	return DerivePrivKey(seed, cryptoType, path)
+}
+
+func knownCryptoType(cryptoType string) bool {
+	for _, name := range crypto.Algorithms() {
+		if name == cryptoType {
+			return true
+		}
+	}
+	return false
+}
+
+// readBits returns the n bits of data from bit offset, most significant first.
+func readBits(data []byte, offset, n int) int {
+	v := 0
+	for i := offset; i < offset+n; i++ {
+		v = v<<1 | int(data[i/8]>>(7-uint(i%8))&1)
+	}
+	return v
+}
+
+// writeBits sets the n bits of data from bit offset to v.
+func writeBits(data []byte, offset, n, v int) {
+	for i := 0; i < n; i++ {
+		if v>>(uint(n-1-i))&1 == 1 {
+			pos := offset + i
+			data[pos/8] |= 1 << (7 - uint(pos%8))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package mnemonic
+
+import "strings"
+
+// Wordlist is a list of 2048 words, a word encodes 11 bits by its index.
+type Wordlist struct {
+	name  string
+	words []string
+	index map[string]int
+}
+
+var (
+	// English is the English wordlist of BIP39.
+	English = newWordlist("english", englishWords)
+	// ChineseSimplified is the Simplified Chinese wordlist of BIP39.
+	ChineseSimplified = newWordlist("chinese_simplified", chineseSimplifiedWords)
+)
+
+func newWordlist(name, data string) *Wordlist {
+	words := strings.Fields(data)
+	if len(words) != 2048 {
+		panic("mnemonic: wordlist " + name + " does not have 2048 words")
+	}
+	index := make(map[string]int, len(words))
+	for i, w := range words {
+		index[w] = i
+	}
+	return &Wordlist{name: name, words: words, index: index}
+}
+
+// Name returns the file name of the list in BIP39, without extension.
+func (wl *Wordlist) Name() string { return wl.name }
+
+// Word returns the word at index i, i must be less than 2048.
+func (wl *Wordlist) Word(i int) string { return wl.words[i] }
+
+// Index returns the index of word in the list.
+func (wl *Wordlist) Index(word string) (int, bool) {
+	i, ok := wl.index[word]
+	return i, ok
+}
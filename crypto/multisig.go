@@ -0,0 +1,255 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/common"
+)
+
+var (
+	// ErrInvalidThreshold is returned when k is not in [1, n].
+	ErrInvalidThreshold = errors.New("invalid multisig threshold")
+	// ErrDuplicatePubKey is returned when a key appears twice in a multisig key.
+	ErrDuplicatePubKey = errors.New("duplicate multisig public key")
+	// ErrUnknownSigner is returned when a key is not part of a multisig key.
+	ErrUnknownSigner = errors.New("public key is not a multisig signer")
+)
+
+//-------------------------------------
+
+var _ PubKey = &PubKeyMultisigThreshold{}
+
+// PubKeyMultisigThreshold is satisfied by signatures of at least K of its
+// public keys, which can be of any registered type.
+type PubKeyMultisigThreshold struct {
+	K       uint     `json:"threshold"`
+	PubKeys []PubKey `json:"pubkeys"`
+}
+
+// NewPubKeyMultisigThreshold returns a k-of-n multisig key. The keys are
+// sorted by their encoding, so the same set of keys and threshold always gives
+// the same address whatever the order they are given in.
+func NewPubKeyMultisigThreshold(k int, pubKeys []PubKey) (*PubKeyMultisigThreshold, error) {
+	if k <= 0 || k > len(pubKeys) {
+		return nil, ErrInvalidThreshold
+	}
+
+	keys := make([]PubKey, len(pubKeys))
+	copy(keys, pubKeys)
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i].Bytes(), keys[j].Bytes()) < 0
+	})
+	for i := 1; i < len(keys); i++ {
+		if keys[i].Equals(keys[i-1]) {
+			return nil, ErrDuplicatePubKey
+		}
+	}
+
+	return &PubKeyMultisigThreshold{K: uint(k), PubKeys: keys}, nil
+}
+
+// validate checks the threshold and the keys of a decoded multisig key, which
+// unlike the ones of NewPubKeyMultisigThreshold can hold anything.
+func (pubKey *PubKeyMultisigThreshold) validate() error {
+	if pubKey.K == 0 || pubKey.K > uint(len(pubKey.PubKeys)) {
+		return ErrInvalidThreshold
+	}
+	seen := make(map[string]bool, len(pubKey.PubKeys))
+	for _, pk := range pubKey.PubKeys {
+		if pk == nil {
+			return errors.New("nil multisig public key")
+		}
+		bz := string(pk.Bytes())
+		if seen[bz] {
+			return ErrDuplicatePubKey
+		}
+		seen[bz] = true
+	}
+	return nil
+}
+
+// multisigThresholdJSON is the JSON form of PubKeyMultisigThreshold.
+type multisigThresholdJSON struct {
+	K       uint     `json:"threshold"`
+	PubKeys []PubKey `json:"pubkeys"`
+}
+
+// UnmarshalAmino rejects the invalid multisig keys decoded from JSON.
+func (pubKey *PubKeyMultisigThreshold) UnmarshalAmino(repr multisigThresholdJSON) error {
+	decoded := PubKeyMultisigThreshold{K: repr.K, PubKeys: repr.PubKeys}
+	if err := decoded.validate(); err != nil {
+		return err
+	}
+	*pubKey = decoded
+	return nil
+}
+
+// Address is the local hash of the bal encoding of the key.
+func (pubKey *PubKeyMultisigThreshold) Address() Address {
+	return Address(LocalHash(pubKey.Bytes())[12:])
+}
+
+// Bytes --
+func (pubKey *PubKeyMultisigThreshold) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(pubKey)
+}
+
+// Raw --
+func (pubKey *PubKeyMultisigThreshold) Raw() []byte {
+	bz, err := bal.EncodeToBytes(pubKey)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// VerifyBytes checks that sig is a *Multisignature holding at least K valid
+// signatures of msg, by the keys marked in its bit array. It is false for an
+// invalid key, like a zero threshold.
+func (pubKey *PubKeyMultisigThreshold) VerifyBytes(msg []byte, sig Signature) bool {
+	if pubKey.validate() != nil {
+		return false
+	}
+	multisig, ok := sig.(*Multisignature)
+	if !ok || multisig.BitArray.Size() != len(pubKey.PubKeys) {
+		return false
+	}
+
+	sigIndex := 0
+	for i, pk := range pubKey.PubKeys {
+		if !multisig.BitArray.GetIndex(i) {
+			continue
+		}
+		if sigIndex >= len(multisig.Sigs) || !pk.VerifyBytes(msg, multisig.Sigs[sigIndex]) {
+			return false
+		}
+		sigIndex++
+	}
+	return sigIndex == len(multisig.Sigs) && uint(sigIndex) >= pubKey.K
+}
+
+// Equals --
+func (pubKey *PubKeyMultisigThreshold) Equals(other PubKey) bool {
+	if otherMulti, ok := other.(*PubKeyMultisigThreshold); ok {
+		return bytes.Equal(pubKey.Bytes(), otherMulti.Bytes())
+	}
+	return false
+}
+
+func (pubKey *PubKeyMultisigThreshold) String() string {
+	return fmt.Sprintf("PubKeyMultisigThreshold{%d/%d %v}", pubKey.K, len(pubKey.PubKeys), pubKey.Address())
+}
+
+// indexOf returns the position of key in the multisig key, or -1.
+func (pubKey *PubKeyMultisigThreshold) indexOf(key PubKey) int {
+	for i, pk := range pubKey.PubKeys {
+		if pk.Equals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+//-------------------------------------
+
+var _ Signature = &Multisignature{}
+
+// Multisignature is the signature of a PubKeyMultisigThreshold. BitArray marks
+// which keys signed, Sigs holds their signatures in the order of the keys.
+type Multisignature struct {
+	BitArray *common.BitArray
+	Sigs     []Signature
+}
+
+// Bytes --
+func (sig *Multisignature) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(sig)
+}
+
+// Raw --
+func (sig *Multisignature) Raw() []byte {
+	bz, err := bal.EncodeToBytes(sig)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// IsZero --
+func (sig *Multisignature) IsZero() bool {
+	return len(sig.Sigs) == 0
+}
+
+// Equals --
+func (sig *Multisignature) Equals(other Signature) bool {
+	if otherMulti, ok := other.(*Multisignature); ok {
+		return bytes.Equal(sig.Bytes(), otherMulti.Bytes())
+	}
+	return false
+}
+
+//-------------------------------------
+
+// MultisigBuilder collects the partial signatures of a message, in any order,
+// until enough of them are gathered to build the Multisignature.
+type MultisigBuilder struct {
+	pubKey *PubKeyMultisigThreshold
+	msg    []byte
+	sigs   map[int]Signature
+}
+
+// NewMultisigBuilder --
+func NewMultisigBuilder(pubKey *PubKeyMultisigThreshold, msg []byte) *MultisigBuilder {
+	return &MultisigBuilder{
+		pubKey: pubKey,
+		msg:    msg,
+		sigs:   make(map[int]Signature),
+	}
+}
+
+// AddSignature adds the signature made by signer, it is checked against the
+// message. Adding a signature of the same signer again replaces it.
+func (b *MultisigBuilder) AddSignature(signer PubKey, sig Signature) error {
+	index := b.pubKey.indexOf(signer)
+	if index < 0 {
+		return ErrUnknownSigner
+	}
+	if !signer.VerifyBytes(b.msg, sig) {
+		return fmt.Errorf("invalid signature of signer %d", index)
+	}
+	b.sigs[index] = sig
+	return nil
+}
+
+// Count returns the number of collected signatures.
+func (b *MultisigBuilder) Count() int {
+	return len(b.sigs)
+}
+
+// Ready reports whether the threshold is reached.
+func (b *MultisigBuilder) Ready() bool {
+	return uint(len(b.sigs)) >= b.pubKey.K
+}
+
+// Build returns the Multisignature of all collected signatures.
+func (b *MultisigBuilder) Build() (*Multisignature, error) {
+	if !b.Ready() {
+		return nil, fmt.Errorf("not enough signatures, have %d, need %d", len(b.sigs), b.pubKey.K)
+	}
+
+	multisig := &Multisignature{
+		BitArray: common.NewBitArray(len(b.pubKey.PubKeys)),
+		Sigs:     make([]Signature, 0, len(b.sigs)),
+	}
+	for i := range b.pubKey.PubKeys {
+		if sig, ok := b.sigs[i]; ok {
+			multisig.BitArray.SetIndex(i, true)
+			multisig.Sigs = append(multisig.Sigs, sig)
+		}
+	}
+	return multisig, nil
+}
@@ -0,0 +1,134 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func genMultisigKeys(t *testing.T) ([]PrivKey, []PubKey) {
+	var privKeys []PrivKey
+	for _, algo := range []string{CryptoTypeSecp256K1, CryptoTypeGM, CryptoTypeEd25519, CryptoTypeP256, CryptoTypeSecp256K1} {
+		privKey, err := algorithmByName(algo).GenerateKey()
+		require.Nil(t, err)
+		privKeys = append(privKeys, privKey)
+	}
+	pubKeys := make([]PubKey, len(privKeys))
+	for i, privKey := range privKeys {
+		pubKeys[i] = privKey.PubKey()
+	}
+	return privKeys, pubKeys
+}
+
+func TestPubKeyMultisigThreshold(t *testing.T) {
+	privKeys, pubKeys := genMultisigKeys(t)
+	multiKey, err := NewPubKeyMultisigThreshold(3, pubKeys)
+	require.Nil(t, err)
+
+	msg := Keccak256([]byte("hello world"))
+	builder := NewMultisigBuilder(multiKey, msg)
+	for _, i := range []int{4, 1} {
+		sig, err := privKeys[i].Sign(msg)
+		require.Nil(t, err)
+		require.Nil(t, builder.AddSignature(pubKeys[i], sig))
+	}
+	assert.False(t, builder.Ready())
+	_, err = builder.Build()
+	assert.NotNil(t, err)
+
+	// A signature of another message is rejected.
+	badSig, _ := privKeys[2].Sign(Keccak256(msg))
+	assert.NotNil(t, builder.AddSignature(pubKeys[2], badSig))
+
+	sig, _ := privKeys[2].Sign(msg)
+	require.Nil(t, builder.AddSignature(pubKeys[2], sig))
+	assert.True(t, builder.Ready())
+
+	multisig, err := builder.Build()
+	require.Nil(t, err)
+	assert.True(t, multiKey.VerifyBytes(msg, multisig))
+	assert.False(t, multiKey.VerifyBytes(Keccak256(msg), multisig))
+
+	// Round trip through bal.
+	pubKey2, err := PubKeyFromBytes(multiKey.Bytes())
+	require.Nil(t, err)
+	assert.True(t, multiKey.Equals(pubKey2))
+	assert.Equal(t, multiKey.Address(), pubKey2.Address())
+	sig2, err := SignatureFromBytes(multisig.Bytes())
+	require.Nil(t, err)
+	assert.True(t, pubKey2.VerifyBytes(msg, sig2))
+
+	// Dropping a signature below the threshold.
+	multisig.Sigs = multisig.Sigs[1:]
+	assert.False(t, multiKey.VerifyBytes(msg, multisig))
+}
+
+func TestPubKeyMultisigThresholdAddress(t *testing.T) {
+	_, pubKeys := genMultisigKeys(t)
+	multiKey, err := NewPubKeyMultisigThreshold(2, pubKeys)
+	require.Nil(t, err)
+
+	reversed := make([]PubKey, len(pubKeys))
+	for i := range pubKeys {
+		reversed[len(pubKeys)-1-i] = pubKeys[i]
+	}
+	multiKey2, err := NewPubKeyMultisigThreshold(2, reversed)
+	require.Nil(t, err)
+	assert.Equal(t, multiKey.Address(), multiKey2.Address())
+
+	multiKey3, err := NewPubKeyMultisigThreshold(3, pubKeys)
+	require.Nil(t, err)
+	assert.NotEqual(t, multiKey.Address(), multiKey3.Address())
+
+	_, err = NewPubKeyMultisigThreshold(0, pubKeys)
+	assert.Equal(t, ErrInvalidThreshold, err)
+	_, err = NewPubKeyMultisigThreshold(6, pubKeys)
+	assert.Equal(t, ErrInvalidThreshold, err)
+	_, err = NewPubKeyMultisigThreshold(2, append(pubKeys, pubKeys[0]))
+	assert.Equal(t, ErrDuplicatePubKey, err)
+
+	other, _ := GenPrivKeyEd25519()
+	assert.Equal(t, ErrUnknownSigner, NewMultisigBuilder(multiKey, nil).AddSignature(other.PubKey(), nil))
+}
+
+func TestPubKeyMultisigThresholdInvalid(t *testing.T) {
+	privKeys, pubKeys := genMultisigKeys(t)
+	msg := Keccak256([]byte("multisig message"))
+
+	// a zero threshold key would accept an empty multisignature
+	zeroK := &PubKeyMultisigThreshold{K: 0, PubKeys: pubKeys}
+	empty := &Multisignature{BitArray: common.NewBitArray(len(pubKeys))}
+	assert.False(t, zeroK.VerifyBytes(msg, empty))
+	_, err := PubKeyFromBytes(zeroK.Bytes())
+	assert.Equal(t, ErrInvalidThreshold, err)
+
+	tooHigh := &PubKeyMultisigThreshold{K: uint(len(pubKeys) + 1), PubKeys: pubKeys}
+	_, err = PubKeyFromBytes(tooHigh.Bytes())
+	assert.Equal(t, ErrInvalidThreshold, err)
+
+	// a signer listed twice does not count twice
+	sig, err := privKeys[0].Sign(msg)
+	require.Nil(t, err)
+	twice := &PubKeyMultisigThreshold{K: 2, PubKeys: []PubKey{pubKeys[0], pubKeys[0]}}
+	bitArray := common.NewBitArray(2)
+	bitArray.SetIndex(0, true)
+	bitArray.SetIndex(1, true)
+	assert.False(t, twice.VerifyBytes(msg, &Multisignature{BitArray: bitArray, Sigs: []Signature{sig, sig}}))
+	_, err = PubKeyFromBytes(twice.Bytes())
+	assert.Equal(t, ErrDuplicatePubKey, err)
+
+	// the JSON decoder checks the key too
+	multiKey, err := NewPubKeyMultisigThreshold(2, pubKeys)
+	require.Nil(t, err)
+	bz, err := bal.MarshalJSON(multiKey)
+	require.Nil(t, err)
+	var decoded PubKey
+	require.Nil(t, bal.UnmarshalJSON(bz, &decoded))
+	assert.True(t, multiKey.Equals(decoded))
+	bz, err = bal.MarshalJSON(zeroK)
+	require.Nil(t, err)
+	assert.Equal(t, ErrInvalidThreshold, bal.UnmarshalJSON(bz, &decoded))
+}
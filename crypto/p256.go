@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	p256N     = elliptic.P256().Params().N
+	p256halfN = new(big.Int).Rsh(p256N, 1)
+)
+
+// signP256 calculates a recoverable ECDSA signature over a 32 bytes hash.
+// The S value is normalized to the lower half of the curve order, and the
+// recovery id v is 0 or 1 (the parity of R.y).
+func signP256(priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int, v byte, err error) {
+	for {
+		r, s, err = ecdsa.Sign(rand.Reader, priv, hash)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if s.Cmp(p256halfN) > 0 {
+			s.Sub(p256N, s)
+		}
+
+		// ecdsa.Sign does not return R, the recovery id is the one that
+		// gives the key back. None does when R.x is not a valid scalar and r
+		// is its reduction, then sign again.
+		for v = 0; v < 2; v++ {
+			pub, err := recoverP256(hash, r, s, v)
+			if err == nil && pub.X.Cmp(priv.X) == 0 && pub.Y.Cmp(priv.Y) == 0 {
+				return r, s, v, nil
+			}
+		}
+	}
+}
+
+// decompressP256 returns the y coordinate of the point of P256 with the x
+// coordinate x and the parity odd, or nil if there is none.
+func decompressP256(x *big.Int, odd uint) *big.Int {
+	params := elliptic.P256().Params()
+	if x.Sign() < 0 || x.Cmp(params.P) >= 0 {
+		return nil
+	}
+
+	// y^2 = x^3 - 3x + b
+	y2 := new(big.Int).Mul(x, x)
+	y2.Mul(y2, x)
+	threeX := new(big.Int).Lsh(x, 1)
+	threeX.Add(threeX, x)
+	y2.Sub(y2, threeX)
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+
+	y := new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil
+	}
+	if y.Bit(0) != odd {
+		y.Sub(params.P, y)
+	}
+	return y
+}
+
+// recoverP256 returns the public key that created the signature (r, s, v) over hash.
+func recoverP256(hash []byte, r, s *big.Int, v byte) (*ecdsa.PublicKey, error) {
+	c := elliptic.P256()
+	if v > 1 {
+		return nil, errors.New("invalid p256 recovery id")
+	}
+	if r.Sign() <= 0 || r.Cmp(p256N) >= 0 || s.Sign() <= 0 || s.Cmp(p256N) >= 0 {
+		return nil, errors.New("invalid p256 signature values")
+	}
+
+	x := new(big.Int).Set(r)
+	y := decompressP256(x, uint(v))
+	if y == nil {
+		return nil, errors.New("invalid p256 signature, R is not on curve")
+	}
+
+	// Q = r^-1 * (s*R - e*G)
+	e := new(big.Int).SetBytes(hash)
+	e.Mod(e, p256N)
+	rInv := new(big.Int).ModInverse(r, p256N)
+
+	u1 := new(big.Int).Mul(e, rInv)
+	u1.Neg(u1)
+	u1.Mod(u1, p256N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, p256N)
+
+	x1, y1 := c.ScalarBaseMult(u1.Bytes())
+	x2, y2 := c.ScalarMult(x, y, u2.Bytes())
+	qx, qy := c.Add(x1, y1, x2, y2)
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, errors.New("invalid p256 signature, recovered infinity")
+	}
+	return &ecdsa.PublicKey{Curve: c, X: qx, Y: qy}, nil
+}
+
+// verifyP256 checks a low-S ECDSA signature over hash.
+func verifyP256(pub *ecdsa.PublicKey, hash []byte, r, s *big.Int) bool {
+	if pub == nil || pub.X == nil || s.Cmp(p256halfN) > 0 {
+		return false
+	}
+	return ecdsa.Verify(pub, hash, r, s)
+}
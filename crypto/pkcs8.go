@@ -0,0 +1,608 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/tjfoc/gmsm/sm2"
+	"github.com/tjfoc/gmsm/sm4"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PKCS#8 private keys (RFC 5208, RFC 5958) and SubjectPublicKeyInfo public
+// keys (RFC 5280) for the ed25519, secp256k1, gm and p256 keys, the formats
+// of OpenSSL and GmSSL:
+//
+//	der, err := crypto.MarshalPKCS8(privKey)
+//	privKey, err := crypto.ParsePKCS8(der)
+//
+// Ed25519 keys use the id-Ed25519 OID of RFC 8410. The other keys are
+// id-ecPublicKey keys with the named curve as parameter, SM2 keys use the sm2
+// curve OID of GM/T 0006 and the structures of GM/T 0010. Encrypted keys use
+// PBES2 with PBKDF2 (RFC 8018), AES-256-CBC and, for gm keys, SM4-CBC.
+
+const (
+	// PEM block types of the keys.
+	pemPrivateKey          = "PRIVATE KEY"
+	pemEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+	pemECPrivateKey        = "EC PRIVATE KEY"
+	pemPublicKey           = "PUBLIC KEY"
+
+	// pkcs8Iterations is the PBKDF2 iteration count of encrypted keys.
+	pkcs8Iterations = 100000
+	// pkcs8MaxIterations bounds the PBKDF2 iteration count of the keys to
+	// decrypt, which is chosen by whoever encrypted them.
+	pkcs8MaxIterations = 10000000
+	pkcs8SaltLen       = 16
+)
+
+var (
+	oidPublicKeyEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+	oidPublicKeyECDSA   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeySM2     = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+
+	oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	oidNamedCurveSM2       = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 301}
+	oidNamedCurveP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSM4CBC     = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 104, 2}
+)
+
+var (
+	// ErrPKCS8Decrypt is returned when an encrypted private key cannot be
+	// decrypted, usually because of a wrong password.
+	ErrPKCS8Decrypt = errors.New("pkcs8: decryption failed, wrong password?")
+	// ErrInvalidPEM is returned when no PEM block of the expected type is found.
+	ErrInvalidPEM = errors.New("invalid PEM key")
+)
+
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	// optional attributes and public key omitted
+}
+
+// ecPrivateKey is the ECPrivateKey of RFC 5915 and GM/T 0010.
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+type publicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// x509Curve describes the named curve of an id-ecPublicKey key type.
+type x509Curve struct {
+	cryptoType string
+	oid        asn1.ObjectIdentifier
+	curve      elliptic.Curve
+	decompress func(data []byte) (x, y *big.Int)
+	privKey    func(d []byte) (PrivKey, error)
+	pubKey     func(x, y *big.Int) PubKey
+}
+
+var x509Curves = []*x509Curve{
+	{
+		cryptoType: CryptoTypeSecp256K1,
+		oid:        oidNamedCurveSecp256k1,
+		curve:      secp256k1.S256(),
+		decompress: secp256k1.DecompressPubkey,
+		privKey: func(d []byte) (PrivKey, error) {
+			return GenPrivKeySecp256k1FromSecret(d)
+		},
+		pubKey: func(x, y *big.Int) PubKey {
+			return makePubKeySecp256k1(&ecdsa.PublicKey{Curve: S256(), X: x, Y: y})
+		},
+	},
+	{
+		cryptoType: CryptoTypeGM,
+		oid:        oidNamedCurveSM2,
+		curve:      sm2.P256Sm2(),
+		decompress: func(data []byte) (x, y *big.Int) {
+			return decompressPointA3(sm2.P256Sm2(), data)
+		},
+		privKey: func(d []byte) (PrivKey, error) {
+			return GenPrivKeyGMFromSecret(d)
+		},
+		pubKey: func(x, y *big.Int) PubKey {
+			return makePubKeyGM(&sm2.PublicKey{Curve: sm2.P256Sm2(), X: x, Y: y})
+		},
+	},
+	{
+		cryptoType: CryptoTypeP256,
+		oid:        oidNamedCurveP256,
+		curve:      elliptic.P256(),
+		decompress: func(data []byte) (x, y *big.Int) {
+			return decompressPointA3(elliptic.P256(), data)
+		},
+		privKey: func(d []byte) (PrivKey, error) {
+			return GenPrivKeyP256FromSecret(d)
+		},
+		pubKey: func(x, y *big.Int) PubKey {
+			return makePubKeyP256(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+		},
+	},
+}
+
+func x509CurveByType(cryptoType string) *x509Curve {
+	for _, c := range x509Curves {
+		if c.cryptoType == cryptoType {
+			return c
+		}
+	}
+	return nil
+}
+
+func x509CurveByOID(oid asn1.ObjectIdentifier) *x509Curve {
+	for _, c := range x509Curves {
+		if c.oid.Equal(oid) {
+			return c
+		}
+	}
+	return nil
+}
+
+// x509CurveByAlgorithm returns the curve of an id-ecPublicKey algorithm. Some
+// SM2 implementations put the sm2 OID in the algorithm and leave out the
+// parameters, they are accepted too.
+func x509CurveByAlgorithm(algo pkix.AlgorithmIdentifier) (*x509Curve, error) {
+	if algo.Algorithm.Equal(oidPublicKeySM2) && len(algo.Parameters.FullBytes) == 0 {
+		return x509CurveByType(CryptoTypeGM), nil
+	}
+	if !algo.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("unknown public key algorithm %v", algo.Algorithm)
+	}
+	var oid asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(algo.Parameters.FullBytes, &oid); err != nil || len(rest) != 0 {
+		return nil, errors.New("invalid named curve")
+	}
+	c := x509CurveByOID(oid)
+	if c == nil {
+		return nil, fmt.Errorf("unknown named curve %v", oid)
+	}
+	return c, nil
+}
+
+func (c *x509Curve) algorithm() pkix.AlgorithmIdentifier {
+	params, _ := asn1.Marshal(c.oid)
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidPublicKeyECDSA,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}
+}
+
+// parsePoint parses an uncompressed or compressed point.
+func (c *x509Curve) parsePoint(data []byte) (x, y *big.Int) {
+	if len(data) == 33 {
+		return c.decompress(data)
+	}
+	return elliptic.Unmarshal(c.curve, data)
+}
+
+// parsePrivKey parses the private key d, which may have lost leading zeros.
+func (c *x509Curve) parsePrivKey(d []byte) (PrivKey, error) {
+	k := new(big.Int).SetBytes(d)
+	if len(d) > 32 || k.Sign() == 0 || k.Cmp(c.curve.Params().N) >= 0 {
+		return nil, errors.New("invalid elliptic curve private key value")
+	}
+	return c.privKey(math.PaddedBigBytes(k, 32))
+}
+
+func (c *x509Curve) marshalECPrivateKey(d []byte) ([]byte, error) {
+	// RFC 5915 keeps the leading zeros of the private key, which Gm keys may
+	// have lost
+	d = math.PaddedBigBytes(new(big.Int).SetBytes(d), (c.curve.Params().N.BitLen()+7)/8)
+	defer zeroBytes(d)
+	x, y := c.curve.ScalarBaseMult(d)
+	key := ecPrivateKey{
+		Version:    1,
+		PrivateKey: d,
+		PublicKey:  asn1.BitString{Bytes: elliptic.Marshal(c.curve, x, y)},
+	}
+	key.PublicKey.BitLength = 8 * len(key.PublicKey.Bytes)
+	return asn1.Marshal(key)
+}
+
+//-------------------------------------
+
+// MarshalPKCS8 returns the unencrypted PKCS#8 DER encoding of privKey.
+func MarshalPKCS8(privKey PrivKey) ([]byte, error) {
+	var info pkcs8
+	switch privKey.Type() {
+	case CryptoTypeEd25519:
+		seed, err := asn1.Marshal(privKey.Raw()[:32])
+		if err != nil {
+			return nil, err
+		}
+		info.Algo = pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyEd25519}
+		info.PrivateKey = seed
+	default:
+		c := x509CurveByType(privKey.Type())
+		if c == nil {
+			return nil, ErrInvalidCryptoType
+		}
+		key, err := c.marshalECPrivateKey(privKey.Raw())
+		if err != nil {
+			return nil, err
+		}
+		info.Algo = c.algorithm()
+		info.PrivateKey = key
+	}
+	return asn1.Marshal(info)
+}
+
+// ParsePKCS8 parses an unencrypted PKCS#8 private key.
+func ParsePKCS8(der []byte) (PrivKey, error) {
+	var info pkcs8
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("pkcs8: %v", err)
+	}
+	if info.Algo.Algorithm.Equal(oidPublicKeyEd25519) {
+		if len(info.Algo.Parameters.FullBytes) != 0 {
+			return nil, errors.New("pkcs8: invalid Ed25519 private key parameters")
+		}
+		var seed []byte
+		if rest, err := asn1.Unmarshal(info.PrivateKey, &seed); err != nil || len(rest) != 0 || len(seed) != 32 {
+			return nil, errors.New("pkcs8: invalid Ed25519 private key")
+		}
+		return privKeyEd25519FromSeed(seed), nil
+	}
+
+	c, err := x509CurveByAlgorithm(info.Algo)
+	if err != nil {
+		return nil, fmt.Errorf("pkcs8: %v", err)
+	}
+	return parseECPrivateKey(c, info.PrivateKey)
+}
+
+// parseECPrivateKey parses an ECPrivateKey of the curve c, or of its named
+// curve if c is nil.
+func parseECPrivateKey(c *x509Curve, der []byte) (PrivKey, error) {
+	var key ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &key); err != nil {
+		return nil, fmt.Errorf("ec private key: %v", err)
+	}
+	if key.Version != 1 {
+		return nil, fmt.Errorf("ec private key: unknown version %d", key.Version)
+	}
+	if len(key.NamedCurveOID) != 0 {
+		named := x509CurveByOID(key.NamedCurveOID)
+		if named == nil || c != nil && named != c {
+			return nil, fmt.Errorf("ec private key: unexpected named curve %v", key.NamedCurveOID)
+		}
+		c = named
+	}
+	if c == nil {
+		return nil, errors.New("ec private key: missing named curve")
+	}
+	privKey, err := c.parsePrivKey(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("ec private key: %v", err)
+	}
+	return privKey, nil
+}
+
+// MarshalPKIXPublicKey returns the DER encoded SubjectPublicKeyInfo of pubKey.
+func MarshalPKIXPublicKey(pubKey PubKey) ([]byte, error) {
+	var info publicKeyInfo
+	switch pk := pubKey.(type) {
+	case PubKeyEd25519:
+		info.Algorithm = pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyEd25519}
+		info.PublicKey = asn1.BitString{Bytes: pk[:], BitLength: 8 * len(pk)}
+	default:
+		algo := algorithmByPubKey(pubKey)
+		if algo == nil {
+			return nil, ErrInvalidCryptoType
+		}
+		c := x509CurveByType(algo.Name)
+		if c == nil {
+			return nil, ErrInvalidCryptoType
+		}
+		x, y := c.parsePoint(pubKey.Raw())
+		if x == nil {
+			return nil, errors.New("pkix: invalid public key")
+		}
+		point := elliptic.Marshal(c.curve, x, y)
+		info.Algorithm = c.algorithm()
+		info.PublicKey = asn1.BitString{Bytes: point, BitLength: 8 * len(point)}
+	}
+	return asn1.Marshal(info)
+}
+
+// ParsePKIXPublicKey parses a DER encoded SubjectPublicKeyInfo.
+func ParsePKIXPublicKey(der []byte) (PubKey, error) {
+	var info publicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("pkix: %v", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("pkix: trailing data after public key")
+	}
+	if info.PublicKey.BitLength%8 != 0 {
+		return nil, errors.New("pkix: invalid public key bit string")
+	}
+	data := info.PublicKey.Bytes
+
+	if info.Algorithm.Algorithm.Equal(oidPublicKeyEd25519) {
+		if len(info.Algorithm.Parameters.FullBytes) != 0 || len(data) != PubKeyEd25519Size {
+			return nil, errors.New("pkix: invalid Ed25519 public key")
+		}
+		var pubKey PubKeyEd25519
+		copy(pubKey[:], data)
+		return pubKey, nil
+	}
+
+	c, err := x509CurveByAlgorithm(info.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("pkix: %v", err)
+	}
+	x, y := c.parsePoint(data)
+	if x == nil {
+		return nil, errors.New("pkix: invalid elliptic curve public key")
+	}
+	return c.pubKey(x, y), nil
+}
+
+//-------------------------------------
+
+// pbes2Cipher is a block cipher in CBC mode of PBES2.
+type pbes2Cipher struct {
+	oid      asn1.ObjectIdentifier
+	keySize  int
+	newBlock func(key []byte) (cipher.Block, error)
+}
+
+var pbes2Ciphers = []*pbes2Cipher{
+	{oidAES128CBC, 16, aes.NewCipher},
+	{oidAES192CBC, 24, aes.NewCipher},
+	{oidAES256CBC, 32, aes.NewCipher},
+	{oidSM4CBC, 16, sm4.NewCipher},
+}
+
+func pbes2CipherByOID(oid asn1.ObjectIdentifier) *pbes2Cipher {
+	for _, c := range pbes2Ciphers {
+		if c.oid.Equal(oid) {
+			return c
+		}
+	}
+	return nil
+}
+
+func pbkdf2PRF(oid asn1.ObjectIdentifier) func() hash.Hash {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACSHA1):
+		return sha1.New
+	case oid.Equal(oidHMACSHA256):
+		return sha256.New
+	case oid.Equal(oidHMACSHA512):
+		return sha512.New
+	}
+	return nil
+}
+
+// MarshalEncryptedPKCS8 returns the PKCS#8 DER encoding of privKey encrypted
+// with password. Gm keys are encrypted with SM4-CBC, the other keys with
+// AES-256-CBC.
+func MarshalEncryptedPKCS8(privKey PrivKey, password []byte) ([]byte, error) {
+	plain, err := MarshalPKCS8(privKey)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(plain)
+
+	c := pbes2CipherByOID(oidAES256CBC)
+	if privKey.Type() == CryptoTypeGM {
+		c = pbes2CipherByOID(oidSM4CBC)
+	}
+	salt := CRandBytes(pkcs8SaltLen)
+	key := pbkdf2.Key(password, salt, pkcs8Iterations, c.keySize, sha256.New)
+	defer zeroBytes(key)
+	block, err := c.newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := CRandBytes(block.BlockSize())
+
+	padLen := block.BlockSize() - len(plain)%block.BlockSize()
+	data := append(append([]byte{}, plain...), bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pkcs8Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, _ := asn1.Marshal(iv)
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: c.oid, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo:          pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: data,
+	})
+}
+
+// ParseEncryptedPKCS8 decrypts and parses a PKCS#8 private key encrypted with
+// PBES2, PBKDF2 with HMAC-SHA1, HMAC-SHA256 or HMAC-SHA512, and AES-CBC or
+// SM4-CBC.
+func ParseEncryptedPKCS8(der, password []byte) (PrivKey, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("pkcs8: %v", err)
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("pkcs8: unsupported encryption algorithm %v", info.Algo.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("pkcs8: invalid PBES2 parameters: %v", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("pkcs8: unsupported key derivation function %v", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("pkcs8: invalid PBKDF2 parameters: %v", err)
+	}
+	prf := pbkdf2PRF(kdf.PRF.Algorithm)
+	if prf == nil {
+		return nil, fmt.Errorf("pkcs8: unsupported PBKDF2 PRF %v", kdf.PRF.Algorithm)
+	}
+	c := pbes2CipherByOID(params.EncryptionScheme.Algorithm)
+	if c == nil {
+		return nil, fmt.Errorf("pkcs8: unsupported cipher %v", params.EncryptionScheme.Algorithm)
+	}
+	if kdf.KeyLength != 0 && kdf.KeyLength != c.keySize {
+		return nil, errors.New("pkcs8: invalid PBKDF2 key length")
+	}
+	if kdf.IterationCount <= 0 || kdf.IterationCount > pkcs8MaxIterations {
+		return nil, errors.New("pkcs8: invalid PBKDF2 iteration count")
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("pkcs8: invalid cipher parameters: %v", err)
+	}
+
+	key := pbkdf2.Key(password, kdf.Salt, kdf.IterationCount, c.keySize, prf)
+	defer zeroBytes(key)
+	block, err := c.newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	bs := block.BlockSize()
+	if len(iv) != bs || len(info.EncryptedData) == 0 || len(info.EncryptedData)%bs != 0 {
+		return nil, errors.New("pkcs8: invalid encrypted data")
+	}
+	data := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, info.EncryptedData)
+	defer zeroBytes(data)
+
+	padLen := int(data[len(data)-1])
+	if padLen == 0 || padLen > bs || !hmac.Equal(data[len(data)-padLen:], bytes.Repeat([]byte{byte(padLen)}, padLen)) {
+		return nil, ErrPKCS8Decrypt
+	}
+	privKey, err := ParsePKCS8(data[:len(data)-padLen])
+	if err != nil {
+		return nil, ErrPKCS8Decrypt
+	}
+	return privKey, nil
+}
+
+//-------------------------------------
+
+// MarshalPrivKeyPEM returns privKey as a "PRIVATE KEY" PEM block, or as an
+// "ENCRYPTED PRIVATE KEY" block if password is not empty.
+func MarshalPrivKeyPEM(privKey PrivKey, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		der, err := MarshalPKCS8(privKey)
+		if err != nil {
+			return nil, err
+		}
+		defer zeroBytes(der)
+		return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+	}
+	der, err := MarshalEncryptedPKCS8(privKey, password)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemEncryptedPrivateKey, Bytes: der}), nil
+}
+
+// ParsePrivKeyPEM parses the first private key PEM block of data, which may
+// also be an "EC PRIVATE KEY" block of OpenSSL. The password is only used by
+// encrypted keys.
+func ParsePrivKeyPEM(data, password []byte) (PrivKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, ErrInvalidPEM
+		}
+		switch block.Type {
+		case pemPrivateKey:
+			return ParsePKCS8(block.Bytes)
+		case pemEncryptedPrivateKey:
+			return ParseEncryptedPKCS8(block.Bytes, password)
+		case pemECPrivateKey:
+			if _, ok := block.Headers["DEK-Info"]; ok {
+				return nil, errors.New("legacy PEM encryption is not supported")
+			}
+			return parseECPrivateKey(nil, block.Bytes)
+		}
+	}
+}
+
+// MarshalPubKeyPEM returns pubKey as a "PUBLIC KEY" PEM block.
+func MarshalPubKeyPEM(pubKey PubKey) ([]byte, error) {
+	der, err := MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// ParsePubKeyPEM parses the first "PUBLIC KEY" PEM block of data.
+func ParsePubKeyPEM(data []byte) (PubKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, ErrInvalidPEM
+		}
+		if block.Type == pemPublicKey {
+			return ParsePKIXPublicKey(block.Bytes)
+		}
+	}
+}
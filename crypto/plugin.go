@@ -1,46 +1,375 @@
 package crypto
 
-type plugin interface {
-	GenerateKey() (PrivKey, error)
-	GenerateKeyFromSecret([]byte) (PrivKey, error)
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// Algorithm describes a key algorithm that can be plugged into the crypto
+// package with RegisterAlgorithm.
+type Algorithm struct {
+	// Name identifies the algorithm, it is the value returned by PrivKey.Type().
+	Name string
+	// Account and Node report whether the algorithm may be selected with
+	// SetLocalAccountType and SetLocalNodeType.
+	Account bool
+	Node    bool
+
+	GenerateKey           func() (PrivKey, error)
+	GenerateKeyFromSecret func(secret []byte) (PrivKey, error)
+	// PrivKeyFromRaw returns the private key whose Raw method returns raw,
+	// it is nil if the algorithm does not support it.
+	PrivKeyFromRaw func(raw []byte) (PrivKey, error)
+
+	// Concrete key and signature types, registered with bal under the given names.
+	PubKey        PubKey
+	PubKeyName    string
+	PrivKey       PrivKey
+	PrivKeyName   string
+	Signature     Signature
+	SignatureName string
+
+	// MatchSignature reports whether the raw signature was produced by this algorithm.
+	// Algorithms without MatchSignature and Verify are not reachable from raw
+	// signatures, their keys are only verified through PubKey.VerifyBytes.
+	MatchSignature func(sig []byte) bool
+	// Verify checks the raw signature of hash against the raw public key.
+	Verify func(pubkey, hash, sig []byte) bool
+	// Recover returns the public key that created the raw signature,
+	// it is nil if the algorithm does not support public key recovery.
+	Recover func(hash, sig []byte) (PubKey, error)
+
+	// Encrypt and Decrypt implement public key encryption to the keys of
+	// the algorithm, they are nil if it is not supported.
+	Encrypt func(pubKey PubKey, plaintext []byte) ([]byte, error)
+	Decrypt func(privKey PrivKey, ciphertext []byte) ([]byte, error)
+	// SharedSecret computes the Diffie-Hellman secret of a private key and a
+	// public key of the algorithm, it is nil if it is not supported.
+	SharedSecret func(privKey PrivKey, pubKey PubKey) ([]byte, error)
 }
 
 var (
-	cryptos = make(map[string]plugin)
+	cryptosMu sync.RWMutex
+	cryptos   = make(map[string]*Algorithm)
+	// cryptoList keeps the registration order, signatures are matched in this order.
+	cryptoList []*Algorithm
 )
 
-type pluginGM struct{}
+// RegisterAlgorithm makes a key algorithm available by its name and registers
+// its concrete types with bal. It panics if the name is already registered or
+// a mandatory field is missing.
+func RegisterAlgorithm(algo Algorithm) {
+	if algo.Name == "" {
+		panic("crypto: RegisterAlgorithm with empty name")
+	}
+	if algo.GenerateKey == nil || algo.GenerateKeyFromSecret == nil {
+		panic(fmt.Sprintf("crypto: RegisterAlgorithm %s without key generation", algo.Name))
+	}
+	if (algo.MatchSignature == nil) != (algo.Verify == nil) {
+		panic(fmt.Sprintf("crypto: RegisterAlgorithm %s needs both MatchSignature and Verify", algo.Name))
+	}
+	if algo.PubKey == nil || algo.PrivKey == nil || algo.Signature == nil {
+		panic(fmt.Sprintf("crypto: RegisterAlgorithm %s without concrete types", algo.Name))
+	}
+	if (algo.Encrypt == nil) != (algo.Decrypt == nil) {
+		panic(fmt.Sprintf("crypto: RegisterAlgorithm %s needs both Encrypt and Decrypt", algo.Name))
+	}
+
+	cryptosMu.Lock()
+	defer cryptosMu.Unlock()
+
+	if _, ok := cryptos[algo.Name]; ok {
+		panic(fmt.Sprintf("crypto: RegisterAlgorithm called twice for %s", algo.Name))
+	}
+
+	bal.RegisterConcrete(algo.PubKey, algo.PubKeyName, nil)
+	bal.RegisterConcrete(algo.PrivKey, algo.PrivKeyName, nil)
+	bal.RegisterConcrete(algo.Signature, algo.SignatureName, nil)
 
-func (p pluginGM) GenerateKey() (PrivKey, error) {
-	return GenPrivKeyGM()
+	a := algo
+	cryptos[a.Name] = &a
+	cryptoList = append(cryptoList, &a)
 }
 
-func (p pluginGM) GenerateKeyFromSecret(secret []byte) (PrivKey, error) {
-	return GenPrivKeyGMFromSecret(secret)
+// Algorithms returns the names of all registered algorithms in registration order.
+func Algorithms() []string {
+	cryptosMu.RLock()
+	defer cryptosMu.RUnlock()
+
+	names := make([]string, 0, len(cryptoList))
+	for _, a := range cryptoList {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+func algorithmByName(name string) *Algorithm {
+	cryptosMu.RLock()
+	defer cryptosMu.RUnlock()
+	return cryptos[name]
 }
 
-type pluginEd25519 struct{}
+// algorithmByPubKey returns the algorithm whose public key type is the one of pubKey.
+func algorithmByPubKey(pubKey PubKey) *Algorithm {
+	cryptosMu.RLock()
+	defer cryptosMu.RUnlock()
 
-func (p pluginEd25519) GenerateKey() (PrivKey, error) {
-	return GenPrivKeyEd25519()
+	t := reflect.TypeOf(pubKey)
+	for _, a := range cryptoList {
+		if reflect.TypeOf(a.PubKey) == t {
+			return a
+		}
+	}
+	return nil
 }
 
-func (p pluginEd25519) GenerateKeyFromSecret(secret []byte) (PrivKey, error) {
-	return GenPrivKeyEd25519FromSecret(secret)
+func algorithmBySignature(sig []byte) *Algorithm {
+	cryptosMu.RLock()
+	defer cryptosMu.RUnlock()
+
+	for _, a := range cryptoList {
+		if a.MatchSignature != nil && a.MatchSignature(sig) {
+			return a
+		}
+	}
+	return nil
 }
 
-type pluginSecp256K1 struct{}
+// -----------------------------------
+
+var algorithmEd25519 = Algorithm{
+	Name: CryptoTypeEd25519,
+	Node: true,
 
-func (p pluginSecp256K1) GenerateKey() (PrivKey, error) {
-	return GenPrivKeySecp256k1()
+	GenerateKey: func() (PrivKey, error) {
+		return GenPrivKeyEd25519()
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		return GenPrivKeyEd25519FromSecret(secret)
+	},
+	PrivKeyFromRaw: func(raw []byte) (PrivKey, error) {
+		return privKeyEd25519FromRaw(raw)
+	},
+
+	PubKey:        PubKeyEd25519{},
+	PubKeyName:    "PubKeyEd25519",
+	PrivKey:       PrivKeyEd25519{},
+	PrivKeyName:   "PrivKeyEd25519",
+	Signature:     SignatureEd25519{},
+	SignatureName: "SignEd25519",
+
+	MatchSignature: func(sig []byte) bool {
+		return len(sig) == SignatureEd25519Size
+	},
+	Verify: func(pubkey, hash, sig []byte) bool {
+		pk := PubKeyEd25519{}
+		copy(pk[:], pubkey)
+		return pk.VerifyBytes(hash, NewSignatureEd25519(sig))
+	},
+	SharedSecret: sharedSecretEd25519,
 }
 
-func (p pluginSecp256K1) GenerateKeyFromSecret(secret []byte) (PrivKey, error) {
-	return GenPrivKeySecp256k1FromSecret(secret)
+var algorithmSecp256K1 = Algorithm{
+	Name:    CryptoTypeSecp256K1,
+	Account: true,
+
+	GenerateKey: func() (PrivKey, error) {
+		return GenPrivKeySecp256k1()
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		return GenPrivKeySecp256k1FromSecret(secret)
+	},
+	PrivKeyFromRaw: func(raw []byte) (PrivKey, error) {
+		if err := checkRawScalar(raw, secp256k1N); err != nil {
+			return nil, err
+		}
+		return GenPrivKeySecp256k1FromSecret(raw)
+	},
+
+	PubKey:        &PubKeySecp256k1{},
+	PubKeyName:    "PubKeySecp256k1",
+	PrivKey:       &PrivKeySecp256k1{},
+	PrivKeyName:   "PrivKeySecp256k1",
+	Signature:     SignatureSecp256k1{},
+	SignatureName: "SignSecp256k1",
+
+	MatchSignature: func(sig []byte) bool {
+		return len(sig) == 65 && (sig[64] == 0 || sig[64] == 1)
+	},
+	Verify: func(pubkey, hash, sig []byte) bool {
+		// The signature should be in [R || S] format.
+		return secp256k1.VerifySignature(pubkey, hash, sig[:64])
+	},
+	Recover: func(hash, sig []byte) (PubKey, error) {
+		raw, err := secp256k1.RecoverPubkey(hash, sig)
+		if err != nil {
+			return nil, err
+		}
+		return &PubKeySecp256k1{Data: raw}, nil
+	},
+	Encrypt:      encryptSecp256k1,
+	Decrypt:      decryptSecp256k1,
+	SharedSecret: sharedSecretSecp256k1,
+}
+
+var algorithmGM = Algorithm{
+	Name:    CryptoTypeGM,
+	Account: true,
+
+	GenerateKey: func() (PrivKey, error) {
+		return GenPrivKeyGM()
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		return GenPrivKeyGMFromSecret(secret)
+	},
+	PrivKeyFromRaw: func(raw []byte) (PrivKey, error) {
+		if err := checkRawScalar(raw, sm2.P256Sm2().Params().N); err != nil {
+			return nil, err
+		}
+		return GenPrivKeyGMFromSecret(raw)
+	},
+
+	PubKey:        &PubKeyGM{},
+	PubKeyName:    "PubKeyGM",
+	PrivKey:       &PrivKeyGM{},
+	PrivKeyName:   "PrivKeyGM",
+	Signature:     SignatureGM{},
+	SignatureName: "SignGM",
+
+	MatchSignature: func(sig []byte) bool {
+		return len(sig) == SignatureGMSize && (sig[64] == SM2Magic || sig[64] == SM2Magic+1)
+	},
+	Verify: func(pubkey, hash, sig []byte) bool {
+		pk := PubKeyGMFromBytes(pubkey)
+		s, ok := NewSignatureGM(sig)
+		if !ok {
+			return false
+		}
+		return pk.VerifyBytes(hash, s)
+	},
+	Recover: func(hash, sig []byte) (PubKey, error) {
+		r, s, v, ok := ParseSignatureGM(sig)
+		if !ok {
+			return nil, fmt.Errorf("ParseSignatureGM fail")
+		}
+		pk, ok := sm2.Ecrecover(hash, r, s, v)
+		if !ok {
+			return nil, fmt.Errorf("sm2.Recover fail")
+		}
+		return makePubKeyGM(pk), nil
+	},
+	Encrypt:      encryptGM,
+	Decrypt:      decryptGM,
+	SharedSecret: sharedSecretGM,
+}
+
+var algorithmP256 = Algorithm{
+	Name:    CryptoTypeP256,
+	Account: true,
+
+	GenerateKey: func() (PrivKey, error) {
+		return GenPrivKeyP256()
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		return GenPrivKeyP256FromSecret(secret)
+	},
+	PrivKeyFromRaw: func(raw []byte) (PrivKey, error) {
+		if err := checkRawScalar(raw, p256N); err != nil {
+			return nil, err
+		}
+		return GenPrivKeyP256FromSecret(raw)
+	},
+
+	PubKey:        &PubKeyP256{},
+	PubKeyName:    "PubKeyP256",
+	PrivKey:       &PrivKeyP256{},
+	PrivKeyName:   "PrivKeyP256",
+	Signature:     SignatureP256{},
+	SignatureName: "SignP256",
+
+	MatchSignature: func(sig []byte) bool {
+		return len(sig) == SignatureP256Size && (sig[64] == P256Magic || sig[64] == P256Magic+1)
+	},
+	Verify: func(pubkey, hash, sig []byte) bool {
+		s, ok := NewSignatureP256(sig)
+		if !ok {
+			return false
+		}
+		return PubKeyP256FromBytes(pubkey).VerifyBytes(hash, s)
+	},
+	Recover: func(hash, sig []byte) (PubKey, error) {
+		r, s, v, ok := ParseSignatureP256(sig)
+		if !ok {
+			return nil, fmt.Errorf("ParseSignatureP256 fail")
+		}
+		pk, err := recoverP256(hash, r, s, v)
+		if err != nil {
+			return nil, err
+		}
+		return makePubKeyP256(pk), nil
+	},
 }
 
-func init() {
-	cryptos[CryptoTypeGM] = pluginGM{}
-	cryptos[CryptoTypeEd25519] = pluginEd25519{}
-	cryptos[CryptoTypeSecp256K1] = pluginSecp256K1{}
+// BLS signatures are plain G1 points, they are not dispatched from raw bytes
+// since their size collides with ed25519 signatures.
+var algorithmBLS = Algorithm{
+	Name: CryptoTypeBLS,
+	Node: true,
+
+	GenerateKey: func() (PrivKey, error) {
+		return GenPrivKeyBLS()
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		return GenPrivKeyBLSFromSecret(secret)
+	},
+	PrivKeyFromRaw: func(raw []byte) (PrivKey, error) {
+		return privKeyBLSFromRaw(raw)
+	},
+
+	PubKey:        PubKeyBLS{},
+	PubKeyName:    "PubKeyBLS",
+	PrivKey:       PrivKeyBLS{},
+	PrivKeyName:   "PrivKeyBLS",
+	Signature:     SignatureBLS{},
+	SignatureName: "SignBLS",
+}
+
+var algorithmRistretto = Algorithm{
+	Name: CryptoTypeRistretto,
+	Node: true,
+
+	GenerateKey: func() (PrivKey, error) {
+		return GenPrivKeyRistretto()
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		return GenPrivKeyRistrettoFromSecret(secret)
+	},
+	PrivKeyFromRaw: func(raw []byte) (PrivKey, error) {
+		return privKeyRistrettoFromRaw(raw)
+	},
+
+	PubKey:        PubKeyRistretto{},
+	PubKeyName:    "PubKeyRistretto",
+	PrivKey:       PrivKeyRistretto{},
+	PrivKeyName:   "PrivKeyRistretto",
+	Signature:     SignatureRistretto{},
+	SignatureName: "SignRistretto",
+
+	MatchSignature: func(sig []byte) bool {
+		return len(sig) == SignatureRistrettoSize && sig[64] == RistrettoMagic
+	},
+	Verify: func(pubkey, hash, sig []byte) bool {
+		s, ok := NewSignatureRistretto(sig)
+		if !ok {
+			return false
+		}
+		var pk PubKeyRistretto
+		copy(pk[:], pubkey)
+		return pk.VerifyBytes(hash, s)
+	},
 }
@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMagic = 0xfe
+
+// testSignature is an ed25519 signature tagged with testMagic.
+type testSignature []byte
+
+func (sig testSignature) Bytes() []byte           { return sig }
+func (sig testSignature) IsZero() bool            { return len(sig) == 0 }
+func (sig testSignature) Equals(o Signature) bool { return false }
+func (sig testSignature) Raw() []byte             { return sig }
+
+type testPubKey struct{ Key PubKeyEd25519 }
+
+func (pubKey *testPubKey) Address() Address     { return pubKey.Key.Address() }
+func (pubKey *testPubKey) Bytes() []byte        { return pubKey.Key.Raw() }
+func (pubKey *testPubKey) Equals(o PubKey) bool { return false }
+func (pubKey *testPubKey) Raw() []byte          { return pubKey.Key.Raw() }
+func (pubKey *testPubKey) VerifyBytes(msg []byte, sig Signature) bool {
+	return pubKey.Key.VerifyBytes(msg, NewSignatureEd25519(sig.Raw()[:SignatureEd25519Size]))
+}
+
+type testPrivKey struct{ Key PrivKeyEd25519 }
+
+func (privKey *testPrivKey) Bytes() []byte { return privKey.Key.Raw() }
+func (privKey *testPrivKey) PubKey() PubKey {
+	return &testPubKey{Key: privKey.Key.PubKey().(PubKeyEd25519)}
+}
+func (privKey *testPrivKey) Equals(o PrivKey) bool { return false }
+func (privKey *testPrivKey) Reset()                { privKey.Key.Reset() }
+func (privKey *testPrivKey) Raw() []byte           { return privKey.Key.Raw() }
+func (privKey *testPrivKey) Type() string          { return "test" }
+func (privKey *testPrivKey) Sign(msg []byte) (Signature, error) {
+	sig, err := privKey.Key.Sign(msg)
+	if err != nil {
+		return nil, err
+	}
+	return testSignature(append(sig.Raw(), testMagic)), nil
+}
+
+// registerTestOnce keeps TestRegisterAlgorithm re-runnable, algorithms and
+// their concrete types cannot be registered twice.
+var registerTestOnce sync.Once
+
+func registerTestAlgorithm() {
+	registerTestOnce.Do(func() { RegisterAlgorithm(testAlgorithm) })
+}
+
+var testAlgorithm = Algorithm{
+	Name: "test",
+	Node: true,
+	GenerateKey: func() (PrivKey, error) {
+		priv, err := GenPrivKeyEd25519()
+		return &testPrivKey{Key: priv}, err
+	},
+	GenerateKeyFromSecret: func(secret []byte) (PrivKey, error) {
+		priv, err := GenPrivKeyEd25519FromSecret(secret)
+		return &testPrivKey{Key: priv}, err
+	},
+	PubKey:        &testPubKey{},
+	PubKeyName:    "PubKeyTest",
+	PrivKey:       &testPrivKey{},
+	PrivKeyName:   "PrivKeyTest",
+	Signature:     testSignature{},
+	SignatureName: "SignTest",
+	MatchSignature: func(sig []byte) bool {
+		return len(sig) == 65 && sig[64] == testMagic
+	},
+	Verify: func(pubkey, hash, sig []byte) bool {
+		pk := &testPubKey{}
+		copy(pk.Key[:], pubkey)
+		return pk.VerifyBytes(hash, testSignature(sig))
+	},
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	registerTestAlgorithm()
+	assert.Contains(t, Algorithms(), "test")
+	assert.Panics(t, func() { RegisterAlgorithm(algorithmEd25519) })
+	assert.Panics(t, func() { SetLocalAccountType("test") })
+
+	SetLocalNodeType("test")
+	defer SetLocalNodeType(CryptoTypeEd25519)
+
+	privKey, err := GenerateNodeKey()
+	require.Nil(t, err)
+	assert.Equal(t, "test", privKey.Type())
+
+	hash := Keccak256([]byte("hello world"))
+	sig, err := privKey.Sign(hash)
+	require.Nil(t, err)
+	assert.True(t, VerifySignature(privKey.PubKey().Raw(), hash, sig.Raw()))
+
+	_, err = Sender(hash, sig.Raw())
+	assert.NotNil(t, err, "test algorithm does not support recovery")
+
+	privKey2, err := GeneratePrivKeyFromSecret([]byte("secret"), "test")
+	require.Nil(t, err)
+	assert.False(t, VerifySignature(privKey2.PubKey().Raw(), hash, sig.Raw()))
+}
+
+func TestVerifySignatureBuiltin(t *testing.T) {
+	hash := Keccak256([]byte("hello world"))
+	for _, name := range []string{CryptoTypeEd25519, CryptoTypeSecp256K1, CryptoTypeGM, CryptoTypeP256, CryptoTypeRistretto} {
+		privKey, err := GeneratePrivKeyFromSecret(Keccak256([]byte(name)), name)
+		require.Nil(t, err, name)
+		assert.Equal(t, name, privKey.Type())
+
+		sig, err := privKey.Sign(hash)
+		require.Nil(t, err, name)
+		assert.True(t, VerifySignature(privKey.PubKey().Raw(), hash, sig.Raw()), name)
+		assert.False(t, VerifySignature(privKey.PubKey().Raw(), Keccak256(hash), sig.Raw()), name)
+	}
+	assert.False(t, VerifySignature(nil, hash, []byte{1, 2, 3}))
+
+	_, err := GeneratePrivKeyFromSecret([]byte("secret"), "unknown")
+	assert.Equal(t, ErrInvalidCryptoType, err)
+}
@@ -3,6 +3,7 @@ package crypto
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/subtle"
 	"errors"
@@ -89,6 +90,9 @@ func (privKey PrivKeyEd25519) Reset() {
 }
 
 // Deterministically generates new priv-key bytes from key.
+//
+// Deprecated: derive keys from a seed with NewMasterKey and CryptoTypeEd25519,
+// see ExtendedKey.
 func (privKey PrivKeyEd25519) Generate(index int) PrivKeyEd25519 {
 	bz, err := bal.EncodeToBytes(struct {
 		PrivKey [64]byte
@@ -141,6 +145,25 @@ func GenPrivKeyEd25519FromSecret(secret []byte) (PrivKeyEd25519, error) {
 	return PrivKeyEd25519(*privKeyBytes), err
 }
 
+// privKeyEd25519FromSeed returns the key of the 32 bytes RFC 8032 seed.
+func privKeyEd25519FromSeed(seed []byte) PrivKeyEd25519 {
+	var privKey PrivKeyEd25519
+	copy(privKey[:], ed25519.NewKeyFromSeed(seed))
+	return privKey
+}
+
+// privKeyEd25519FromRaw returns the key of the 64 bytes seed || public key.
+func privKeyEd25519FromRaw(raw []byte) (PrivKeyEd25519, error) {
+	if len(raw) != 64 {
+		return PrivKeyEd25519{}, errors.New("invalid length, need 64 bytes")
+	}
+	privKey := privKeyEd25519FromSeed(raw[:32])
+	if !bytes.Equal(privKey[32:], raw[32:]) {
+		return PrivKeyEd25519{}, errors.New("invalid private key, public key mismatch")
+	}
+	return privKey, nil
+}
+
 //-------------------------------------
 
 var _ PrivKey = &PrivKeySecp256k1{}
@@ -243,19 +266,6 @@ func (privKey *PrivKeySecp256k1) Reset() {
 	}
 }
 
-/*
-// Deterministically generates new priv-key bytes from key.
-func (key PrivKeySecp256k1) Generate(index int) PrivKeySecp256k1 {
-	newBytes := cdc.BinarySha256(struct {
-		PrivKey [64]byte
-		Index   int
-	}{key, index})
-	var newKey [64]byte
-	copy(newKey[:], newBytes)
-	return PrivKeySecp256k1(newKey)
-}
-*/
-
 func GenPrivKeySecp256k1() (*PrivKeySecp256k1, error) {
 	pk, err := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
 	if err != nil {
@@ -366,7 +376,7 @@ func GenPrivKeyGM() (*PrivKeyGM, error) {
 
 	privKey := PrivKeyGM{
 		pk: pk,
-		Db: pk.D.Bytes(),
+		Db: math.PaddedBigBytes(pk.D, 32),
 	}
 	return &privKey, nil
 }
@@ -381,3 +391,116 @@ func GenPrivKeyGMFromSecret(secret []byte) (*PrivKeyGM, error) {
 	}
 	return &privKey, nil
 }
+
+// -----------------------------------
+
+var _ PrivKey = &PrivKeyP256{}
+
+// PrivKeyP256 is a NIST P-256 (secp256r1) private key.
+type PrivKeyP256 struct {
+	pk   *ecdsa.PrivateKey
+	Data []byte
+}
+
+func (privKey *PrivKeyP256) key() *ecdsa.PrivateKey {
+	if privKey.pk == nil {
+		if err := privKey.fromRaw(); err != nil {
+			panic(err)
+		}
+	}
+	return privKey.pk
+}
+
+func (privKey *PrivKeyP256) fromRaw() error {
+	if privKey.pk == nil {
+		priv := new(ecdsa.PrivateKey)
+		priv.PublicKey.Curve = elliptic.P256()
+		if 8*len(privKey.Raw()) != priv.Params().BitSize {
+			return fmt.Errorf("invalid length, need %d bits", priv.Params().BitSize)
+		}
+
+		priv.D = new(big.Int).SetBytes(privKey.Raw())
+		if priv.D.Cmp(p256N) >= 0 {
+			return fmt.Errorf("invalid private key, >=N")
+		}
+		if priv.D.Sign() <= 0 {
+			return fmt.Errorf("invalid private key, zero or negative")
+		}
+
+		priv.PublicKey.X, priv.PublicKey.Y = priv.PublicKey.Curve.ScalarBaseMult(privKey.Raw())
+		privKey.pk = priv
+	}
+	return nil
+}
+
+// Type --
+func (privKey *PrivKeyP256) Type() string {
+	return CryptoTypeP256
+}
+
+// Bytes --
+func (privKey *PrivKeyP256) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(privKey)
+}
+
+// Raw --
+func (privKey *PrivKeyP256) Raw() []byte {
+	return privKey.Data
+}
+
+// Sign calculates a recoverable ECDSA signature of a 32 bytes hash.
+// The produced signature is in the [R || S || V] format where V is P256Magic or P256Magic+1.
+func (privKey *PrivKeyP256) Sign(hash []byte) (Signature, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash is required to be exactly 32 bytes (%d)", len(hash))
+	}
+	r, s, v, err := signP256(privKey.key(), hash)
+	if err != nil {
+		return nil, err
+	}
+	return makeSignatureP256(r, s, v), nil
+}
+
+// PubKey --
+func (privKey *PrivKeyP256) PubKey() PubKey {
+	return makePubKeyP256(&privKey.key().PublicKey)
+}
+
+// Equals --
+func (privKey *PrivKeyP256) Equals(other PrivKey) bool {
+	if otherP256, ok := other.(*PrivKeyP256); ok {
+		return subtle.ConstantTimeCompare(privKey.Data, otherP256.Data) == 1
+	}
+	return false
+}
+
+// Reset --
+func (privKey *PrivKeyP256) Reset() {
+	for i := 0; i < len(privKey.Data); i++ {
+		privKey.Data[i] = 0
+	}
+}
+
+// GenPrivKeyP256 --
+func GenPrivKeyP256() (*PrivKeyP256, error) {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivKeyP256{
+		pk:   pk,
+		Data: math.PaddedBigBytes(pk.D, pk.Params().BitSize/8),
+	}, nil
+}
+
+// GenPrivKeyP256FromSecret --
+func GenPrivKeyP256FromSecret(secret []byte) (*PrivKeyP256, error) {
+	privKey := PrivKeyP256{
+		Data: secret,
+	}
+	if err := privKey.fromRaw(); err != nil {
+		return nil, err
+	}
+	return &privKey, nil
+}
@@ -144,6 +144,63 @@ func TestSecp256k1(t *testing.T) {
 	assert.Equal(t, sender, PubkeyToAddress(pubKey), "Address Equal")
 }
 
+func TestGenPrivKeyP256(t *testing.T) {
+	privKey, err := GenPrivKeyP256()
+	if err != nil {
+		t.Fatalf("GenPrivKeyP256 fail: %s", err)
+	}
+
+	privKey2, err := GenPrivKeyP256FromSecret(privKey.Raw())
+	if err != nil {
+		t.Fatalf("GenPrivKeyP256FromSecret fail: %s", err)
+	}
+	assert.Equal(t, privKey.pk, privKey2.pk, "ecdsa.PrivateKey Equal")
+	assert.True(t, privKey.Equals(privKey2))
+
+	_, err = GenPrivKeyP256FromSecret(make([]byte, 32))
+	assert.NotNil(t, err, "zero private key")
+
+	pubKey := privKey.PubKey()
+	for i := 0; i < 16; i++ {
+		hash := Keccak256(CRandBytes(32))
+		sig, err := privKey.Sign(hash)
+		if err != nil {
+			t.Fatalf("Sign fail: %s", err)
+		}
+		assert.True(t, pubKey.VerifyBytes(hash, sig))
+
+		recoverData, err := Ecrecover(hash, sig.Raw())
+		if err != nil {
+			t.Fatalf("Ecrecover fail: %s", err)
+		}
+		assert.Equal(t, pubKey.Raw(), recoverData)
+
+		sender, err := Sender(hash, sig.Raw())
+		if err != nil {
+			t.Fatalf("Sender fail: %s", err)
+		}
+		assert.Equal(t, PubkeyToAddress(pubKey), sender, "Address Equal")
+
+		r, s, v, ok := ParseSignatureP256(sig.Raw())
+		assert.True(t, ok)
+		assert.True(t, ValidateSignatureValues(P256Magic+v, r, s, true))
+	}
+
+	bz := pubKey.Bytes()
+	pubKey2, err := PubKeyFromBytes(bz)
+	assert.Nil(t, err)
+	assert.True(t, pubKey.Equals(pubKey2))
+
+	// compressed form
+	raw := pubKey.Raw()
+	compressed := append([]byte{2 | raw[64]&1}, raw[1:33]...)
+	assert.Equal(t, raw, PubKeyP256FromBytes(compressed).Raw())
+	compressed[0] ^= 1
+	assert.NotEqual(t, raw, PubKeyP256FromBytes(compressed).Raw())
+	compressed[0] = 5
+	assert.Nil(t, PubKeyP256FromBytes(compressed).pk, "invalid prefix")
+}
+
 func BenchmarkEd25519VerifyBytes(b *testing.B) {
 	secret := []byte("hello")
 	priv, err := GenPrivKeyEd25519FromSecret(secret)
@@ -169,3 +226,20 @@ func BenchmarkEd25519Sign(b *testing.B) {
 		priv.Sign(secret)
 	}
 }
+
+func TestPrivKeyFromRaw(t *testing.T) {
+	for _, name := range []string{CryptoTypeEd25519, CryptoTypeSecp256K1, CryptoTypeGM, CryptoTypeP256, CryptoTypeBLS, CryptoTypeRistretto} {
+		privKey, err := GeneratePrivKeyFromSecret(CRandBytes(32), name)
+		assert.NoError(t, err, name)
+		raw, err := PrivKeyFromRaw(privKey.Raw(), name)
+		assert.NoError(t, err, name)
+		assert.True(t, privKey.Equals(raw), name)
+
+		_, err = PrivKeyFromRaw(privKey.Raw()[1:], name)
+		assert.Error(t, err, name)
+		_, err = PrivKeyFromRaw(make([]byte, len(privKey.Raw())), name)
+		assert.Error(t, err, name)
+	}
+	_, err := PrivKeyFromRaw(make([]byte, 32), "unknown")
+	assert.Equal(t, ErrInvalidCryptoType, err)
+}
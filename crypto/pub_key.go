@@ -6,6 +6,7 @@ import (
 	"crypto/elliptic"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 
 	"github.com/XunleiBlockchain/tc-libs/bal"
 	cmn "github.com/XunleiBlockchain/tc-libs/common"
@@ -22,6 +23,11 @@ type Address = cmn.HexBytes
 
 func PubKeyFromBytes(pubKeyBytes []byte) (pubKey PubKey, err error) {
 	err = bal.DecodeBytesWithType(pubKeyBytes, &pubKey)
+	if multiKey, ok := pubKey.(*PubKeyMultisigThreshold); ok && err == nil {
+		if err = multiKey.validate(); err != nil {
+			pubKey = nil
+		}
+	}
 	return
 }
 
@@ -148,10 +154,10 @@ func (pubKey *PubKeySecp256k1) fromRaw() {
 	}
 }
 
-// Address --
+// Address is the last 20 bytes of the local hash of the public key.
 func (pubKey *PubKeySecp256k1) Address() Address {
 	pubBytes := pubKey.Raw()
-	return Address(Keccak256(pubBytes[1:])[12:])
+	return Address(LocalHash(pubBytes[1:])[12:])
 }
 
 // Bytes --
@@ -212,10 +218,10 @@ type PubKeyGM struct {
 	pk   *sm2.PublicKey
 }
 
-// Address --
+// Address is the last 20 bytes of the local hash of the public key.
 func (pubKey *PubKeyGM) Address() Address {
 	pubBytes := pubKey.Raw()
-	return Address(Keccak256(pubBytes[1:])[12:])
+	return Address(LocalHash(pubBytes[1:])[12:])
 }
 
 // Bytes --
@@ -277,3 +283,92 @@ func PubKeyGMFromBytes(data []byte) *PubKeyGM {
 	pubKey.fromRaw()
 	return &pubKey
 }
+
+// -----------------------------------
+
+var _ PubKey = &PubKeyP256{}
+
+// PubKeyP256 is a NIST P-256 (secp256r1) public key in uncompressed form.
+type PubKeyP256 struct {
+	pk   *ecdsa.PublicKey
+	Data []byte
+}
+
+// Address is the last 20 bytes of the local hash of the public key.
+func (pubKey *PubKeyP256) Address() Address {
+	pubBytes := pubKey.Raw()
+	return Address(LocalHash(pubBytes[1:])[12:])
+}
+
+// Bytes --
+func (pubKey *PubKeyP256) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(pubKey)
+}
+
+// Raw --
+func (pubKey *PubKeyP256) Raw() []byte {
+	return pubKey.Data
+}
+
+// VerifyBytes checks a [R || S || V] signature of a 32 bytes hash.
+func (pubKey *PubKeyP256) VerifyBytes(msg []byte, sig Signature) bool {
+	r, s, _, ok := ParseSignatureP256(sig.Raw())
+	if !ok {
+		return false
+	}
+	return verifyP256(pubKey.key(), msg, r, s)
+}
+
+func (pubKey *PubKeyP256) String() string {
+	return fmt.Sprintf("PubKeyP256{%X}", pubKey.Data)
+}
+
+// Equals --
+func (pubKey *PubKeyP256) Equals(other PubKey) bool {
+	if otherP256, ok := other.(*PubKeyP256); ok {
+		return bytes.Equal(pubKey.Data, otherP256.Data)
+	}
+	return false
+}
+
+func (pubKey *PubKeyP256) key() *ecdsa.PublicKey {
+	if pubKey.pk == nil {
+		pubKey.fromRaw()
+	}
+	return pubKey.pk
+}
+
+func (pubKey *PubKeyP256) fromRaw() {
+	if pubKey.pk == nil {
+		if len(pubKey.Data) == 0 {
+			panic("")
+		}
+		x, y := elliptic.Unmarshal(elliptic.P256(), pubKey.Raw())
+		if x == nil {
+			return
+		}
+		pubKey.pk = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	}
+}
+
+func makePubKeyP256(pk *ecdsa.PublicKey) *PubKeyP256 {
+	return &PubKeyP256{
+		pk:   pk,
+		Data: elliptic.Marshal(elliptic.P256(), pk.X, pk.Y),
+	}
+}
+
+// PubKeyP256FromBytes accepts an uncompressed (65 bytes) or compressed (33 bytes) public key.
+func PubKeyP256FromBytes(raw []byte) *PubKeyP256 {
+	if len(raw) == 33 && (raw[0] == 2 || raw[0] == 3) {
+		x := new(big.Int).SetBytes(raw[1:])
+		if y := decompressP256(x, uint(raw[0]&1)); y != nil {
+			return makePubKeyP256(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+		}
+	}
+	pubKey := PubKeyP256{
+		Data: raw,
+	}
+	pubKey.fromRaw()
+	return &pubKey
+}
@@ -2,6 +2,8 @@ package regulation
 
 import (
 	"fmt"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 	"github.com/bwesterb/go-ristretto"
 )
 
@@ -106,6 +108,19 @@ func GetSymmetricKeyWithX(C1 *[32]byte, C2 *[32]byte, x *[32]byte) (symk *[32]by
 	return
 }
 
+// GetSymmetricCipher returns the cipher to encrypt regulated data with for the
+// local account type, see GetSymmetricCipherByType.
 func GetSymmetricCipher() SymmetricCipher {
-	return AesCipher{}
+	return GetSymmetricCipherByType(crypto.LocalAccountType())
+}
+
+// GetSymmetricCipherByType returns the cipher to encrypt regulated data with
+// on a chain of accounts of cryptoType: SM4-GCM for GM keys and AES-GCM
+// otherwise. The ciphers decrypt each other's cipher texts and the ones of the
+// former ciphers.
+func GetSymmetricCipherByType(cryptoType string) SymmetricCipher {
+	if cryptoType == crypto.CryptoTypeGM {
+		return Sm4GcmCipher{}
+	}
+	return AesGcmCipher{}
 }
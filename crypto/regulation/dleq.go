@@ -0,0 +1,71 @@
+package regulation
+
+import (
+	"github.com/bwesterb/go-ristretto"
+)
+
+// DLEQProof is a Chaum-Pedersen proof that two points have the same discrete
+// log x to two bases, P = x*G and Q = x*H, without revealing x. It is made
+// non-interactive with a hash of the statement and the commitments:
+//
+//	A = r*G, B = r*H
+//	c = H(domain || G || H || P || Q || A || B)
+//	s = r - c*x
+//
+// and checked by recomputing A = s*G + c*P and B = s*H + c*Q.
+type DLEQProof struct {
+	C [32]byte
+	S [32]byte
+}
+
+// newDLEQProof proves that P = x*G and Q = x*H for the given domain.
+func newDLEQProof(domain string, x *ristretto.Scalar, G, H, P, Q *ristretto.Point) *DLEQProof {
+	var r ristretto.Scalar
+	r.Rand()
+	var A, B ristretto.Point
+	A.ScalarMult(G, &r)
+	B.ScalarMult(H, &r)
+
+	c := dleqChallenge(domain, G, H, P, Q, &A, &B)
+	var s ristretto.Scalar
+	s.MulSub(c, x, &r)
+	s.Neg(&s)
+	r.SetZero()
+
+	proof := new(DLEQProof)
+	c.BytesInto(&proof.C)
+	s.BytesInto(&proof.S)
+	return proof
+}
+
+// verify checks that P = x*G and Q = x*H for the same x.
+func (proof *DLEQProof) verify(domain string, G, H, P, Q *ristretto.Point) bool {
+	var c, s ristretto.Scalar
+	if !setCanonicalScalar(&c, &proof.C) || !setCanonicalScalar(&s, &proof.S) {
+		return false
+	}
+	var A, B, t ristretto.Point
+	A.PublicScalarMult(G, &s)
+	A.Add(&A, t.PublicScalarMult(P, &c))
+	B.PublicScalarMult(H, &s)
+	B.Add(&B, t.PublicScalarMult(Q, &c))
+	return dleqChallenge(domain, G, H, P, Q, &A, &B).Equals(&c)
+}
+
+func dleqChallenge(domain string, points ...*ristretto.Point) *ristretto.Scalar {
+	buf := make([]byte, 0, len(domain)+32*len(points))
+	buf = append(buf, domain...)
+	for _, p := range points {
+		buf = append(buf, p.Bytes()...)
+	}
+	return new(ristretto.Scalar).Derive(buf)
+}
+
+// setCanonicalScalar sets s to the scalar of buf, and reports whether buf is
+// its canonical encoding.
+func setCanonicalScalar(s *ristretto.Scalar, buf *[32]byte) bool {
+	s.SetBytes(buf)
+	var out [32]byte
+	s.BytesInto(&out)
+	return out == *buf
+}
@@ -0,0 +1,152 @@
+package regulation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// A record (C1, C2) = (k*G, M + k*Y) is well formed when the sender knows k
+// and M = m*G. The sender proves it without revealing them, along with the
+// key commitment K = m*H to the same m, H being a second generator of unknown
+// discrete log, with a Chaum-Pedersen proof for the three equations at once:
+//
+//	A1 = rk*G, A2 = rm*G + rk*Y, A3 = rm*H
+//	c  = H(domain || Y || C1 || C2 || K || A1 || A2 || A3)
+//	sk = rk - c*k, sm = rm - c*m
+//
+// checked by recomputing A1 = sk*G + c*C1, A2 = sm*G + sk*Y + c*C2 and
+// A3 = sm*H + c*K, that is C1 = k*G, C2 - M = k*Y and K = m*H.
+//
+// From public data alone the verifiers learn that the record encrypts a key
+// M known to the sender and that K commits to that very key. They do not
+// learn that the payload is encrypted with its symmetric key symk: the sender
+// seals the payload with K as associated data, and only the holders of symk,
+// the regulator once it opens the record, can check that it decrypts.
+
+const encryptionProofDomain = "tc-libs regulation encryption proof"
+
+// ErrInvalidEncryptionProof is returned when the proof of a record does not
+// verify.
+var ErrInvalidEncryptionProof = errors.New("invalid regulation encryption proof")
+
+// keyCommitmentBase is H, derived from a hash so that its discrete log to G is
+// unknown.
+var keyCommitmentBase = new(ristretto.Point).DeriveDalek([]byte("tc-libs regulation key commitment base"))
+
+// EncryptionProof is the proof that a record (C1, C2) is well formed, with
+// the commitment to its symmetric key.
+type EncryptionProof struct {
+	KeyCommitment [32]byte
+	C             [32]byte
+	Sk            [32]byte
+	Sm            [32]byte
+}
+
+// GenerateAndEncryptSymmetricKeyWithProof is GenerateAndEncryptSymmetricKey
+// with the proof that (C1, C2) is well formed, to publish along with them.
+func GenerateAndEncryptSymmetricKeyWithProof(Y *[32]byte) (k *[32]byte, C1 *[32]byte, C2 *[32]byte, symk *[32]byte, proof *EncryptionProof, err error) {
+	var YPoint ristretto.Point
+	if !YPoint.SetBytes(Y) {
+		err = fmt.Errorf("Y is not a valid public key. ")
+		return
+	}
+
+	// M = m*G, the symmetric key is its compressed form
+	var m, kScalar ristretto.Scalar
+	defer m.SetZero()
+	defer kScalar.SetZero()
+	m.Rand()
+	kScalar.Rand()
+	var M ristretto.Point
+	M.ScalarMultBase(&m)
+	symk = new([32]byte)
+	M.BytesInto(symk)
+	k = new([32]byte)
+	kScalar.BytesInto(k)
+
+	// C1 = k*G, C2 = M + k*Y
+	var C1Point, C2Point, kY ristretto.Point
+	C1Point.ScalarMultBase(&kScalar)
+	kY.ScalarMult(&YPoint, &kScalar)
+	C2Point.Add(&M, &kY)
+	C1 = new([32]byte)
+	C1Point.BytesInto(C1)
+	C2 = new([32]byte)
+	C2Point.BytesInto(C2)
+
+	// K = m*H
+	var K ristretto.Point
+	K.ScalarMult(keyCommitmentBase, &m)
+	proof = new(EncryptionProof)
+	K.BytesInto(&proof.KeyCommitment)
+
+	var rk, rm ristretto.Scalar
+	defer rk.SetZero()
+	defer rm.SetZero()
+	rk.Rand()
+	rm.Rand()
+	var A1, A2, A3, t ristretto.Point
+	A1.ScalarMultBase(&rk)
+	A2.ScalarMultBase(&rm)
+	A2.Add(&A2, t.ScalarMult(&YPoint, &rk))
+	A3.ScalarMult(keyCommitmentBase, &rm)
+
+	c := encryptionChallenge(Y, C1, C2, &proof.KeyCommitment, &A1, &A2, &A3)
+	var s ristretto.Scalar
+	s.MulSub(c, &kScalar, &rk)
+	s.Neg(&s).BytesInto(&proof.Sk)
+	s.MulSub(c, &m, &rm)
+	s.Neg(&s).BytesInto(&proof.Sm)
+	c.BytesInto(&proof.C)
+	return
+}
+
+// Verify checks that the record (C1, C2) to the regulator key Y is well
+// formed and that the key commitment of the proof commits to its key.
+func (proof *EncryptionProof) Verify(Y *[32]byte, C1 *[32]byte, C2 *[32]byte) error {
+	var YPoint, C1Point, C2Point, K ristretto.Point
+	if !YPoint.SetBytes(Y) {
+		return fmt.Errorf("Y is not a valid public key. ")
+	}
+	if !C1Point.SetBytes(C1) {
+		return fmt.Errorf("C1 is not a valid point in the curve. ")
+	}
+	if !C2Point.SetBytes(C2) {
+		return fmt.Errorf("C2 is not a valid point in the curve. ")
+	}
+	if !K.SetBytes(&proof.KeyCommitment) {
+		return ErrInvalidEncryptionProof
+	}
+	var c, sk, sm ristretto.Scalar
+	if !setCanonicalScalar(&c, &proof.C) || !setCanonicalScalar(&sk, &proof.Sk) || !setCanonicalScalar(&sm, &proof.Sm) {
+		return ErrInvalidEncryptionProof
+	}
+
+	var A1, A2, A3, t ristretto.Point
+	A1.PublicScalarMultBase(&sk)
+	A1.Add(&A1, t.PublicScalarMult(&C1Point, &c))
+	A2.PublicScalarMultBase(&sm)
+	A2.Add(&A2, t.PublicScalarMult(&YPoint, &sk))
+	A2.Add(&A2, t.PublicScalarMult(&C2Point, &c))
+	A3.PublicScalarMult(keyCommitmentBase, &sm)
+	A3.Add(&A3, t.PublicScalarMult(&K, &c))
+	if !encryptionChallenge(Y, C1, C2, &proof.KeyCommitment, &A1, &A2, &A3).Equals(&c) {
+		return ErrInvalidEncryptionProof
+	}
+	return nil
+}
+
+func encryptionChallenge(Y, C1, C2, K *[32]byte, A1, A2, A3 *ristretto.Point) *ristretto.Scalar {
+	buf := make([]byte, 0, len(encryptionProofDomain)+7*32)
+	buf = append(buf, encryptionProofDomain...)
+	buf = append(buf, Y[:]...)
+	buf = append(buf, C1[:]...)
+	buf = append(buf, C2[:]...)
+	buf = append(buf, K[:]...)
+	buf = append(buf, A1.Bytes()...)
+	buf = append(buf, A2.Bytes()...)
+	buf = append(buf, A3.Bytes()...)
+	return new(ristretto.Scalar).Derive(buf)
+}
@@ -0,0 +1,139 @@
+package regulation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+func TestEncryptionProof(t *testing.T) {
+	x, Y := GenerateRegulationKey()
+	k, C1, C2, symk, proof, err := GenerateAndEncryptSymmetricKeyWithProof(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := proof.Verify(Y, C1, C2); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the record opens like the ones of GenerateAndEncryptSymmetricKey
+	symk1, err := GetSymmetricKeyWithK(C2, Y, k)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	symk2, err := GetSymmetricKeyWithX(C1, C2, x)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if *symk1 != *symk || *symk2 != *symk {
+		t.Fatalf("opened a wrong symk")
+	}
+
+	// the payload is bound to the key commitment of the record
+	data := []byte("regulated transaction data")
+	cipherText, err := AesGcmCipher{}.EncryptWithAD(data, symk[:], proof.KeyCommitment[:])
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	plainText, err := AesGcmCipher{}.DecryptWithAD(cipherText, symk2[:], proof.KeyCommitment[:])
+	if err != nil || !bytes.Equal(data, plainText) {
+		t.Fatalf("decrypted data is wrong: %v", err)
+	}
+	_, C1b, C2b, _, proofb, err := GenerateAndEncryptSymmetricKeyWithProof(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := proofb.Verify(Y, C1b, C2b); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if _, err := (AesGcmCipher{}).DecryptWithAD(cipherText, symk2[:], proofb.KeyCommitment[:]); err == nil {
+		t.Fatalf("decrypted with the key commitment of another record")
+	}
+}
+
+func TestEncryptionProofForged(t *testing.T) {
+	_, Y := GenerateRegulationKey()
+	_, C1, C2, _, proof, err := GenerateAndEncryptSymmetricKeyWithProof(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// another regulator key, or a record mixed with another one
+	_, Y2 := GenerateRegulationKey()
+	if err := proof.Verify(Y2, C1, C2); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified with another regulator key: %v", err)
+	}
+	_, C1b, C2b, _, proofb, err := GenerateAndEncryptSymmetricKeyWithProof(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := proof.Verify(Y, C1b, C2); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified with another C1: %v", err)
+	}
+	if err := proof.Verify(Y, C1, C2b); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified with another C2: %v", err)
+	}
+
+	// the proof is bound to the key commitment
+	forged := *proof
+	forged.KeyCommitment[0] ^= 1
+	if err := forged.Verify(Y, C1, C2); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified with another key commitment: %v", err)
+	}
+	forged = *proof
+	forged.KeyCommitment = proofb.KeyCommitment
+	if err := forged.Verify(Y, C1, C2); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified with the key commitment of another record: %v", err)
+	}
+	forged = *proof
+	forged.Sm[0] ^= 1
+	if err := forged.Verify(Y, C1, C2); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified a tampered proof: %v", err)
+	}
+	forged = *proof
+	forged.Sk[31] = 0xff
+	if err := forged.Verify(Y, C1, C2); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified a non canonical scalar: %v", err)
+	}
+
+	// a sender cannot commit to another key than the one of its record
+	C1f, C2f, forgedProof := forgeKeyCommitment(Y)
+	if err := forgedProof.Verify(Y, C1f, C2f); err != ErrInvalidEncryptionProof {
+		t.Fatalf("verified a commitment to another key: %v", err)
+	}
+}
+
+// forgeKeyCommitment encrypts M = m*G to Y and proves the record the way
+// GenerateAndEncryptSymmetricKeyWithProof does, but with the key commitment
+// K = m'*H of another m'.
+func forgeKeyCommitment(Y *[32]byte) (C1, C2 *[32]byte, proof *EncryptionProof) {
+	var k, m, m2, rk, rm, s ristretto.Scalar
+	k.Rand()
+	m.Rand()
+	m2.Rand()
+	rk.Rand()
+	rm.Rand()
+	var YPoint, C1Point, C2Point, K, A1, A2, A3, t ristretto.Point
+	YPoint.SetBytes(Y)
+	C1Point.ScalarMultBase(&k)
+	C2Point.ScalarMultBase(&m)
+	C2Point.Add(&C2Point, t.ScalarMult(&YPoint, &k))
+	K.ScalarMult(keyCommitmentBase, &m2)
+	C1, C2, proof = new([32]byte), new([32]byte), new(EncryptionProof)
+	C1Point.BytesInto(C1)
+	C2Point.BytesInto(C2)
+	K.BytesInto(&proof.KeyCommitment)
+
+	A1.ScalarMultBase(&rk)
+	A2.ScalarMultBase(&rm)
+	A2.Add(&A2, t.ScalarMult(&YPoint, &rk))
+	A3.ScalarMult(keyCommitmentBase, &rm)
+	c := encryptionChallenge(Y, C1, C2, &proof.KeyCommitment, &A1, &A2, &A3)
+	s.MulSub(c, &k, &rk)
+	s.Neg(&s).BytesInto(&proof.Sk)
+	s.MulSub(c, &m, &rm)
+	s.Neg(&s).BytesInto(&proof.Sm)
+	c.BytesInto(&proof.C)
+	return
+}
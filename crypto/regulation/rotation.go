@@ -0,0 +1,204 @@
+package regulation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// Rotation of a regulator key x, Y = x*G, to a new key Y'. Each record
+// (C1, C2) is opened with x and encrypted again to Y' with a fresh k':
+//
+//	M = C2 - x*C1,  C1' = k'*G,  C2' = M + k'*Y'
+//
+// so M, and the payloads encrypted with its symmetric key, are unchanged. The
+// regulator proves that the new record holds the same M as the old one, with
+// a Chaum-Pedersen proof of x and k' such that
+//
+//	Y = x*G,  C1' = k'*G,  C2 - C2' = x*C1 - k'*Y'
+//
+// that anyone can check from the two records and the two keys. An
+// EncryptionProof is checked against the original record, not the rotated
+// one, and its key commitment still commits to the same M.
+
+const reEncryptionProofDomain = "tc-libs regulation re-encryption proof"
+
+var (
+	// ErrInvalidReEncryptionProof is returned when the proof of a
+	// re-encryption does not verify.
+	ErrInvalidReEncryptionProof = errors.New("invalid regulation re-encryption proof")
+	// ErrRegulatorKeyMismatch is returned when a regulator key is not the
+	// secret key of the expected regulator public key.
+	ErrRegulatorKeyMismatch = errors.New("regulator key does not match the public key")
+)
+
+// Record is a symmetric key encrypted to a regulator.
+type Record struct {
+	C1 [32]byte
+	C2 [32]byte
+}
+
+// ReEncryptionProof is the proof that two records to two regulator keys
+// hold the same symmetric key.
+type ReEncryptionProof struct {
+	C  [32]byte
+	Sx [32]byte
+	Sk [32]byte
+}
+
+// ReEncryption is a record encrypted again to a new regulator key.
+type ReEncryption struct {
+	Old   Record
+	New   Record
+	Proof ReEncryptionProof
+}
+
+// ReEncrypt opens the record (C1, C2) with the regulator key x and encrypts
+// its symmetric key again to the regulator key newY.
+func ReEncrypt(C1 *[32]byte, C2 *[32]byte, x *[32]byte, newY *[32]byte) (*ReEncryption, error) {
+	var C1Point, C2Point, newYPoint ristretto.Point
+	if !C1Point.SetBytes(C1) {
+		return nil, fmt.Errorf("C1 is not a valid point in the curve. ")
+	}
+	if !C2Point.SetBytes(C2) {
+		return nil, fmt.Errorf("C2 is not a valid point in the curve. ")
+	}
+	if !newYPoint.SetBytes(newY) {
+		return nil, fmt.Errorf("Y is not a valid public key. ")
+	}
+	var xScalar, kScalar, rx, rk ristretto.Scalar
+	defer xScalar.SetZero()
+	defer kScalar.SetZero()
+	defer rx.SetZero()
+	defer rk.SetZero()
+	xScalar.SetBytes(x)
+	kScalar.Rand()
+
+	// M = C2 - x*C1, C1' = k'*G, C2' = M + k'*Y'
+	var M, t, Y, newC1, newC2 ristretto.Point
+	M.Sub(&C2Point, t.ScalarMult(&C1Point, &xScalar))
+	newC1.ScalarMultBase(&kScalar)
+	newC2.Add(&M, t.ScalarMult(&newYPoint, &kScalar))
+	M.SetZero()
+	Y.ScalarMultBase(&xScalar)
+
+	re := &ReEncryption{Old: Record{C1: *C1, C2: *C2}}
+	newC1.BytesInto(&re.New.C1)
+	newC2.BytesInto(&re.New.C2)
+
+	// A1 = rx*G, A2 = rk*G, A3 = rx*C1 - rk*Y'
+	rx.Rand()
+	rk.Rand()
+	var A1, A2, A3 ristretto.Point
+	A1.ScalarMultBase(&rx)
+	A2.ScalarMultBase(&rk)
+	A3.Sub(A3.ScalarMult(&C1Point, &rx), t.ScalarMult(&newYPoint, &rk))
+
+	c := reEncryptionChallenge(&Y, &newYPoint, re, &A1, &A2, &A3)
+	var s ristretto.Scalar
+	s.MulSub(c, &xScalar, &rx)
+	s.Neg(&s).BytesInto(&re.Proof.Sx)
+	s.MulSub(c, &kScalar, &rk)
+	s.Neg(&s).BytesInto(&re.Proof.Sk)
+	c.BytesInto(&re.Proof.C)
+	return re, nil
+}
+
+// Verify checks that the new record of re to the regulator key newY holds the
+// symmetric key of its old record to the regulator key Y.
+func (re *ReEncryption) Verify(Y *[32]byte, newY *[32]byte) error {
+	var YPoint, newYPoint, C1, C2, newC1, newC2 ristretto.Point
+	if !YPoint.SetBytes(Y) || !newYPoint.SetBytes(newY) {
+		return fmt.Errorf("Y is not a valid public key. ")
+	}
+	if !C1.SetBytes(&re.Old.C1) || !C2.SetBytes(&re.Old.C2) || !newC1.SetBytes(&re.New.C1) || !newC2.SetBytes(&re.New.C2) {
+		return ErrInvalidReEncryptionProof
+	}
+	var c, sx, sk ristretto.Scalar
+	if !setCanonicalScalar(&c, &re.Proof.C) || !setCanonicalScalar(&sx, &re.Proof.Sx) || !setCanonicalScalar(&sk, &re.Proof.Sk) {
+		return ErrInvalidReEncryptionProof
+	}
+
+	// A1 = sx*G + c*Y, A2 = sk*G + c*C1', A3 = sx*C1 - sk*Y' + c*(C2 - C2')
+	var A1, A2, A3, t, d ristretto.Point
+	A1.PublicScalarMultBase(&sx)
+	A1.Add(&A1, t.PublicScalarMult(&YPoint, &c))
+	A2.PublicScalarMultBase(&sk)
+	A2.Add(&A2, t.PublicScalarMult(&newC1, &c))
+	A3.PublicScalarMult(&C1, &sx)
+	A3.Sub(&A3, t.PublicScalarMult(&newYPoint, &sk))
+	d.Sub(&C2, &newC2)
+	A3.Add(&A3, t.PublicScalarMult(&d, &c))
+	if !reEncryptionChallenge(&YPoint, &newYPoint, re, &A1, &A2, &A3).Equals(&c) {
+		return ErrInvalidReEncryptionProof
+	}
+	return nil
+}
+
+func reEncryptionChallenge(Y, newY *ristretto.Point, re *ReEncryption, A1, A2, A3 *ristretto.Point) *ristretto.Scalar {
+	buf := make([]byte, 0, len(reEncryptionProofDomain)+9*32)
+	buf = append(buf, reEncryptionProofDomain...)
+	buf = append(buf, Y.Bytes()...)
+	buf = append(buf, newY.Bytes()...)
+	buf = append(buf, re.Old.C1[:]...)
+	buf = append(buf, re.Old.C2[:]...)
+	buf = append(buf, re.New.C1[:]...)
+	buf = append(buf, re.New.C2[:]...)
+	buf = append(buf, A1.Bytes()...)
+	buf = append(buf, A2.Bytes()...)
+	buf = append(buf, A3.Bytes()...)
+	return new(ristretto.Scalar).Derive(buf)
+}
+
+//-------------------------------------
+
+// RecordIterator iterates over the stored records of a regulator key. Next
+// moves to the next record and reports whether there is one, Error returns
+// the error that ended the iteration, if any.
+type RecordIterator interface {
+	Next() bool
+	Record() *Record
+	Error() error
+}
+
+// ReEncryptRecords rotates the records of it from the regulator key x of Y to
+// the regulator key newY. It returns ErrRegulatorKeyMismatch before reading
+// any record if x*G is not Y. Each re-encryption is verified against Y, then
+// given to store, which replaces the record and may keep the proof for
+// audits, and progress, if not nil, is called with the number of records done
+// so far. It stops at the first error and returns the number of records
+// stored.
+func ReEncryptRecords(it RecordIterator, x *[32]byte, Y *[32]byte, newY *[32]byte, store func(*ReEncryption) error, progress func(done int)) (int, error) {
+	var YPoint, xG ristretto.Point
+	if !YPoint.SetBytes(Y) {
+		return 0, fmt.Errorf("Y is not a valid public key. ")
+	}
+	var xScalar ristretto.Scalar
+	xScalar.SetBytes(x)
+	xG.ScalarMultBase(&xScalar)
+	xScalar.SetZero()
+	if !xG.Equals(&YPoint) {
+		return 0, ErrRegulatorKeyMismatch
+	}
+
+	done := 0
+	for it.Next() {
+		r := it.Record()
+		re, err := ReEncrypt(&r.C1, &r.C2, x, newY)
+		if err != nil {
+			return done, fmt.Errorf("record %d: %v", done, err)
+		}
+		if err := re.Verify(Y, newY); err != nil {
+			return done, fmt.Errorf("record %d: %v", done, err)
+		}
+		if err := store(re); err != nil {
+			return done, fmt.Errorf("record %d: %v", done, err)
+		}
+		done++
+		if progress != nil {
+			progress(done)
+		}
+	}
+	return done, it.Error()
+}
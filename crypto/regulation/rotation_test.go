@@ -0,0 +1,173 @@
+package regulation
+
+import (
+	"errors"
+	"testing"
+)
+
+type sliceIterator struct {
+	records []*Record
+	pos     int
+	err     error
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.pos >= len(it.records) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *sliceIterator) Record() *Record { return it.records[it.pos-1] }
+func (it *sliceIterator) Error() error    { return it.err }
+
+func TestReEncryptRecords(t *testing.T) {
+	x, Y := GenerateRegulationKey()
+	newX, newY := GenerateRegulationKey()
+
+	const n = 10
+	records := make([]*Record, n)
+	symks := make([]*[32]byte, n)
+	for i := range records {
+		_, C1, C2, symk, err := GenerateAndEncryptSymmetricKey(Y)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		records[i] = &Record{C1: *C1, C2: *C2}
+		symks[i] = symk
+	}
+
+	var stored []*ReEncryption
+	var reported []int
+	done, err := ReEncryptRecords(&sliceIterator{records: records}, x, Y, newY, func(re *ReEncryption) error {
+		stored = append(stored, re)
+		return nil
+	}, func(done int) {
+		reported = append(reported, done)
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if done != n || len(stored) != n || len(reported) != n || reported[n-1] != n {
+		t.Fatalf("rotated %d records, stored %d, reported %v", done, len(stored), reported)
+	}
+
+	for i, re := range stored {
+		if re.Old != *records[i] {
+			t.Fatalf("record %d: wrong old record", i)
+		}
+		if err := re.Verify(Y, newY); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		symk, err := GetSymmetricKeyWithX(&re.New.C1, &re.New.C2, newX)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if *symk != *symks[i] {
+			t.Fatalf("record %d: rotated record holds a wrong symk", i)
+		}
+		if symk, err := GetSymmetricKeyWithX(&re.New.C1, &re.New.C2, x); err == nil && *symk == *symks[i] {
+			t.Fatalf("record %d: rotated record still opens with the old key", i)
+		}
+	}
+}
+
+func TestReEncryptionForged(t *testing.T) {
+	x, Y := GenerateRegulationKey()
+	_, newY := GenerateRegulationKey()
+	_, C1, C2, _, err := GenerateAndEncryptSymmetricKey(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	re, err := ReEncrypt(C1, C2, x, newY)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := re.Verify(Y, newY); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// a record holding another key
+	_, C1b, C2b, _, err := GenerateAndEncryptSymmetricKey(newY)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	forged := *re
+	forged.New = Record{C1: *C1b, C2: *C2b}
+	if err := forged.Verify(Y, newY); err != ErrInvalidReEncryptionProof {
+		t.Fatalf("verified a record holding another key: %v", err)
+	}
+	forged = *re
+	forged.Old.C2 = *C2b
+	if err := forged.Verify(Y, newY); err != ErrInvalidReEncryptionProof {
+		t.Fatalf("verified another old record: %v", err)
+	}
+	if err := re.Verify(newY, Y); err != ErrInvalidReEncryptionProof {
+		t.Fatalf("verified with swapped keys: %v", err)
+	}
+
+	// opened with a wrong key, the proof does not verify for Y
+	wrongX, _ := GenerateRegulationKey()
+	re, err = ReEncrypt(C1, C2, wrongX, newY)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := re.Verify(Y, newY); err != ErrInvalidReEncryptionProof {
+		t.Fatalf("verified a re-encryption with a wrong key: %v", err)
+	}
+}
+
+func TestReEncryptRecordsErrors(t *testing.T) {
+	x, Y := GenerateRegulationKey()
+	_, newY := GenerateRegulationKey()
+	records := make([]*Record, 3)
+	for i := range records {
+		_, C1, C2, _, err := GenerateAndEncryptSymmetricKey(Y)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		records[i] = &Record{C1: *C1, C2: *C2}
+	}
+
+	errStore := errors.New("store failed")
+	calls := 0
+	done, err := ReEncryptRecords(&sliceIterator{records: records}, x, Y, newY, func(re *ReEncryption) error {
+		calls++
+		if calls == 2 {
+			return errStore
+		}
+		return nil
+	}, nil)
+	if err == nil || done != 1 {
+		t.Fatalf("store error: done %d, %v", done, err)
+	}
+
+	errIter := errors.New("iterator failed")
+	done, err = ReEncryptRecords(&sliceIterator{records: records, err: errIter}, x, Y, newY, func(re *ReEncryption) error {
+		return nil
+	}, nil)
+	if err != errIter || done != 3 {
+		t.Fatalf("iterator error: done %d, %v", done, err)
+	}
+
+	records[1].C1[0] ^= 0xff
+	records[1].C1[31] = 0xff
+	done, err = ReEncryptRecords(&sliceIterator{records: records}, x, Y, newY, func(re *ReEncryption) error {
+		return nil
+	}, nil)
+	if err == nil || done != 1 {
+		t.Fatalf("invalid record: done %d, %v", done, err)
+	}
+
+	// a wrong key fails before any record is read or stored
+	wrongX, _ := GenerateRegulationKey()
+	it := &sliceIterator{records: records}
+	done, err = ReEncryptRecords(it, wrongX, Y, newY, func(re *ReEncryption) error {
+		t.Fatalf("stored a record with a wrong key")
+		return nil
+	}, nil)
+	if err != ErrRegulatorKeyMismatch || done != 0 || it.pos != 0 {
+		t.Fatalf("wrong key: done %d, read %d, %v", done, it.pos, err)
+	}
+}
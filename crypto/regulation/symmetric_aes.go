@@ -9,6 +9,8 @@ import (
 
 var CommonIV = []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
 
+// AesCipher is the legacy AES-CBC cipher with the fixed CommonIV and no
+// authentication, AesGcmCipher replaces it and still decrypts its cipher texts.
 type AesCipher struct{}
 
 func (a AesCipher) Encrypt(plainText []byte, secret []byte) (cipherText []byte, err error) {
@@ -28,6 +30,9 @@ func (a AesCipher) Decrypt(cipherText []byte, secret []byte) (plainText []byte,
 	if err != nil {
 		return
 	}
+	if len(cipherText) == 0 || len(cipherText)%c.BlockSize() != 0 {
+		return nil, fmt.Errorf("cipher text is not a multiple of the block size")
+	}
 	decrypter := cipher.NewCBCDecrypter(c, CommonIV)
 	paddedText := make([]byte, len(cipherText))
 	decrypter.CryptBlocks(paddedText, cipherText)
@@ -43,9 +48,17 @@ func PKCS5Padding(plainText []byte, blockSize int) []byte {
 
 func PKCS5UnPadding(plainText []byte) ([]byte, error) {
 	length := len(plainText)
+	if length == 0 {
+		return nil, fmt.Errorf("padding length is wrong")
+	}
 	number := int(plainText[length-1])
-	if number > length {
+	if number == 0 || number > length {
 		return nil, fmt.Errorf("padding length is wrong")
 	}
+	for _, b := range plainText[length-number:] {
+		if int(b) != number {
+			return nil, fmt.Errorf("padding is wrong")
+		}
+	}
 	return plainText[:length-number], nil
 }
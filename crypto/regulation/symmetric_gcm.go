@@ -0,0 +1,148 @@
+package regulation
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+// The cipher texts of the AEAD ciphers are versioned envelopes
+//
+//	magic (3 bytes) || version (1 byte) || nonce || sealed plain text
+//
+// where the header is authenticated along with the associated data. Every
+// AEAD cipher decrypts the envelopes of all versions, so that the data stays
+// readable when the cipher of a chain changes. Cipher texts without the header
+// are legacy AesCipher cipher texts. A cipher text starting with the magic is
+// only ever opened as an envelope: falling back to the unauthenticated legacy
+// cipher would accept tampered envelopes. A legacy cipher text that happens to
+// start with the magic, one in 2^24, thus fails to decrypt here, and the
+// callers that know a record is legacy decrypt it with AesCipher.Decrypt.
+const (
+	envelopeMagic = "REG"
+
+	// EnvelopeAesGcm is the version of the AES-GCM envelope.
+	EnvelopeAesGcm byte = 1
+	// EnvelopeSm4Gcm is the version of the SM4-GCM envelope.
+	EnvelopeSm4Gcm byte = 2
+
+	envelopeHeaderSize = len(envelopeMagic) + 1
+)
+
+var (
+	// ErrAuthFailed is returned when a cipher text, its associated data or
+	// the secret has been tampered with.
+	ErrAuthFailed = errors.New("cipher text authentication failed")
+	// ErrUnknownEnvelope is returned when the version of an envelope is
+	// unknown, or associated data is given for a legacy cipher text.
+	ErrUnknownEnvelope = errors.New("unknown cipher text envelope version")
+)
+
+// envelopeVersion returns the version of the envelope of cipherText, and
+// false for a legacy cipher text.
+func envelopeVersion(cipherText []byte) (byte, bool) {
+	if len(cipherText) < envelopeHeaderSize || !bytes.HasPrefix(cipherText, []byte(envelopeMagic)) {
+		return 0, false
+	}
+	return cipherText[len(envelopeMagic)], true
+}
+
+// sealEnvelope encrypts plainText with aead and a random nonce.
+func sealEnvelope(aead cipher.AEAD, version byte, plainText, ad []byte) ([]byte, error) {
+	out := make([]byte, envelopeHeaderSize+aead.NonceSize(), envelopeHeaderSize+aead.NonceSize()+len(plainText)+aead.Overhead())
+	copy(out, envelopeMagic)
+	out[len(envelopeMagic)] = version
+	nonce := out[envelopeHeaderSize:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, plainText, envelopeAD(out[:envelopeHeaderSize], ad)), nil
+}
+
+// openEnvelope decrypts the envelope cipherText of version with aead.
+func openEnvelope(aead cipher.AEAD, version byte, cipherText, ad []byte) ([]byte, error) {
+	if v, ok := envelopeVersion(cipherText); !ok || v != version {
+		return nil, ErrUnknownEnvelope
+	}
+	if len(cipherText) < envelopeHeaderSize+aead.NonceSize()+aead.Overhead() {
+		return nil, ErrAuthFailed
+	}
+	nonce := cipherText[envelopeHeaderSize : envelopeHeaderSize+aead.NonceSize()]
+	sealed := cipherText[envelopeHeaderSize+aead.NonceSize():]
+	plainText, err := aead.Open(nil, nonce, sealed, envelopeAD(cipherText[:envelopeHeaderSize], ad))
+	if err != nil {
+		return nil, ErrAuthFailed
+	}
+	return plainText, nil
+}
+
+func envelopeAD(header, ad []byte) []byte {
+	return append(append(make([]byte, 0, len(header)+len(ad)), header...), ad...)
+}
+
+// decryptEnvelope decrypts cipherText with the cipher of its envelope.
+func decryptEnvelope(cipherText, secret, ad []byte) ([]byte, error) {
+	if _, ok := envelopeVersion(cipherText); !ok {
+		if len(ad) != 0 {
+			return nil, ErrUnknownEnvelope
+		}
+		return AesCipher{}.Decrypt(cipherText, secret)
+	}
+
+	version, _ := envelopeVersion(cipherText)
+	var aead cipher.AEAD
+	var err error
+	switch version {
+	case EnvelopeAesGcm:
+		aead, err = newAesGcm(secret)
+	case EnvelopeSm4Gcm:
+		aead, err = newSm4Gcm(secret)
+	default:
+		return nil, ErrUnknownEnvelope
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openEnvelope(aead, version, cipherText, ad)
+}
+
+// AesGcmCipher encrypts with AES-GCM and a random nonce, the secret is the
+// 16, 24 or 32 bytes AES key. It decrypts the other envelopes too, and legacy
+// AesCipher cipher texts, which are not authenticated, when no associated data
+// is given.
+type AesGcmCipher struct{}
+
+var _ AEADCipher = AesGcmCipher{}
+
+func newAesGcm(secret []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
+
+func (a AesGcmCipher) Encrypt(plainText []byte, secret []byte) (cipherText []byte, err error) {
+	return a.EncryptWithAD(plainText, secret, nil)
+}
+
+func (a AesGcmCipher) Decrypt(cipherText []byte, secret []byte) (plainText []byte, err error) {
+	return a.DecryptWithAD(cipherText, secret, nil)
+}
+
+// EncryptWithAD encrypts plainText and authenticates it with ad.
+func (a AesGcmCipher) EncryptWithAD(plainText, secret, ad []byte) (cipherText []byte, err error) {
+	aead, err := newAesGcm(secret)
+	if err != nil {
+		return nil, err
+	}
+	return sealEnvelope(aead, EnvelopeAesGcm, plainText, ad)
+}
+
+// DecryptWithAD decrypts cipherText, checking it and ad.
+func (a AesGcmCipher) DecryptWithAD(cipherText, secret, ad []byte) (plainText []byte, err error) {
+	return decryptEnvelope(cipherText, secret, ad)
+}
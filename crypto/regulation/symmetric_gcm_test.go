@@ -0,0 +1,176 @@
+package regulation
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestAesGcmCipher(t *testing.T) {
+	var gcm AesGcmCipher
+	for i := 0; i <= 128; i++ {
+		key := make([]byte, 32)
+		data := make([]byte, i)
+		_, _ = rand.Read(key)
+		_, _ = rand.Read(data)
+		cipherText, err := gcm.Encrypt(data, key)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		plainText, err := gcm.Decrypt(cipherText, key)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if !bytes.Equal(data, plainText) {
+			t.Fatalf("decrypted data is wrong")
+		}
+
+		// every bit is authenticated, a tampered magic makes a legacy cipher
+		// text which is not
+		ad := []byte("ad")
+		adCipherText, err := gcm.EncryptWithAD(data, key, ad)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		for j := len(envelopeMagic); j < len(cipherText); j++ {
+			adCipherText[j] ^= 0x80
+			if _, err := gcm.DecryptWithAD(adCipherText, key, ad); err == nil {
+				t.Fatalf("tampered byte %d of %d decrypted", j, len(adCipherText))
+			}
+			adCipherText[j] ^= 0x80
+			cipherText[j] ^= 0x80
+			if _, err := gcm.Decrypt(cipherText, key); err == nil {
+				t.Fatalf("tampered byte %d of %d decrypted", j, len(cipherText))
+			}
+			cipherText[j] ^= 0x80
+		}
+	}
+}
+
+func TestAesGcmCipherAD(t *testing.T) {
+	var gcm AesGcmCipher
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	data := []byte("regulated transaction data")
+	ad := []byte("tx hash")
+
+	cipherText, err := gcm.EncryptWithAD(data, key, ad)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	plainText, err := gcm.DecryptWithAD(cipherText, key, ad)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !bytes.Equal(data, plainText) {
+		t.Fatalf("decrypted data is wrong")
+	}
+	if _, err := gcm.DecryptWithAD(cipherText, key, []byte("other hash")); err != ErrAuthFailed {
+		t.Fatalf("decrypted with the wrong associated data: %v", err)
+	}
+	if _, err := gcm.Decrypt(cipherText, key); err != ErrAuthFailed {
+		t.Fatalf("decrypted without the associated data: %v", err)
+	}
+	key[0] ^= 1
+	if _, err := gcm.DecryptWithAD(cipherText, key, ad); err != ErrAuthFailed {
+		t.Fatalf("decrypted with the wrong key: %v", err)
+	}
+
+	// the same plain text encrypts differently
+	c1, _ := gcm.Encrypt(data, key)
+	c2, _ := gcm.Encrypt(data, key)
+	if bytes.Equal(c1, c2) {
+		t.Fatalf("nonce reused")
+	}
+
+	cipherText[len(envelopeMagic)] = 0xff
+	if _, err := gcm.DecryptWithAD(cipherText, key, ad); err != ErrUnknownEnvelope {
+		t.Fatalf("decrypted an unknown envelope: %v", err)
+	}
+	if _, err := gcm.Decrypt(cipherText[:envelopeHeaderSize+4], key); err != ErrUnknownEnvelope {
+		t.Fatalf("decrypted a truncated envelope: %v", err)
+	}
+}
+
+func TestAesGcmCipherLegacy(t *testing.T) {
+	var gcm AesGcmCipher
+	for i := 0; i <= 64; i++ {
+		key := make([]byte, 32)
+		data := make([]byte, i)
+		_, _ = rand.Read(key)
+		_, _ = rand.Read(data)
+		cipherText, err := AesCipher{}.Encrypt(data, key)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		plainText, err := GetSymmetricCipher().Decrypt(cipherText, key)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if !bytes.Equal(data, plainText) {
+			t.Fatalf("decrypted legacy data is wrong")
+		}
+		if _, err := gcm.DecryptWithAD(cipherText, key, []byte("ad")); err == nil {
+			t.Fatalf("decrypted legacy data with associated data")
+		}
+	}
+}
+
+func TestAesGcmCipherLegacyMagic(t *testing.T) {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	// the first block of plain text that encrypts to a block starting with
+	// the magic, CBC with CommonIV
+	block := make([]byte, 16)
+	_, _ = rand.Read(block)
+	copy(block, envelopeMagic)
+	for _, version := range []byte{EnvelopeAesGcm, EnvelopeSm4Gcm, 0xff} {
+		block[len(envelopeMagic)] = version
+		c, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		data := make([]byte, 16, 40)
+		c.Decrypt(data, block)
+		for i := range data {
+			data[i] ^= CommonIV[i]
+		}
+		data = append(data, "regulated transaction data"...)
+
+		cipherText, err := AesCipher{}.Encrypt(data, key)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if v, ok := envelopeVersion(cipherText); !ok || v != version {
+			t.Fatalf("legacy cipher text does not start with the magic")
+		}
+		// taken for an envelope, it only decrypts as a legacy cipher text
+		for _, sc := range []SymmetricCipher{AesGcmCipher{}, Sm4GcmCipher{}} {
+			if _, err := sc.Decrypt(cipherText, key); err == nil {
+				t.Fatalf("version %d: decrypted a legacy cipher text as an envelope", version)
+			}
+		}
+		plainText, err := AesCipher{}.Decrypt(cipherText, key)
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if !bytes.Equal(data, plainText) {
+			t.Fatalf("decrypted legacy data is wrong")
+		}
+	}
+}
+
+func TestPKCS5UnPadding(t *testing.T) {
+	for _, in := range [][]byte{nil, {0}, {1, 2, 3, 5}, {1, 3, 1, 2}} {
+		if _, err := PKCS5UnPadding(in); err == nil {
+			t.Fatalf("unpadded %x", in)
+		}
+	}
+	key := make([]byte, 32)
+	for _, cipherText := range [][]byte{nil, make([]byte, 15)} {
+		if _, err := (AesCipher{}).Decrypt(cipherText, key); err == nil {
+			t.Fatalf("decrypted %d bytes", len(cipherText))
+		}
+	}
+}
@@ -4,3 +4,12 @@ type SymmetricCipher interface {
 	Encrypt(plainText []byte, secret []byte) (cipherText []byte, err error)
 	Decrypt(cipherText []byte, secret []byte) (plainText []byte, err error)
 }
+
+// AEADCipher is a SymmetricCipher that authenticates the cipher text together
+// with associated data, which is not encrypted but must be given again to
+// decrypt.
+type AEADCipher interface {
+	SymmetricCipher
+	EncryptWithAD(plainText, secret, ad []byte) (cipherText []byte, err error)
+	DecryptWithAD(cipherText, secret, ad []byte) (plainText []byte, err error)
+}
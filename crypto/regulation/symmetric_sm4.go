@@ -0,0 +1,50 @@
+package regulation
+
+import (
+	"crypto/cipher"
+
+	"github.com/tjfoc/gmsm/sm3"
+	"github.com/tjfoc/gmsm/sm4"
+)
+
+// Sm4GcmCipher encrypts with SM4-GCM (RFC 8998) and a random nonce, for the
+// chains with GM keys. The secret is the 16 bytes SM4 key, or a longer secret
+// such as the 32 bytes regulation key whose SM3 hash gives the key. Like
+// AesGcmCipher, it decrypts every envelope and legacy AesCipher cipher texts.
+type Sm4GcmCipher struct{}
+
+var _ AEADCipher = Sm4GcmCipher{}
+
+func newSm4Gcm(secret []byte) (cipher.AEAD, error) {
+	key := secret
+	if len(key) != sm4.BlockSize {
+		key = sm3.Sm3Sum(secret)[:sm4.BlockSize]
+	}
+	c, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
+
+func (a Sm4GcmCipher) Encrypt(plainText []byte, secret []byte) (cipherText []byte, err error) {
+	return a.EncryptWithAD(plainText, secret, nil)
+}
+
+func (a Sm4GcmCipher) Decrypt(cipherText []byte, secret []byte) (plainText []byte, err error) {
+	return a.DecryptWithAD(cipherText, secret, nil)
+}
+
+// EncryptWithAD encrypts plainText and authenticates it with ad.
+func (a Sm4GcmCipher) EncryptWithAD(plainText, secret, ad []byte) (cipherText []byte, err error) {
+	aead, err := newSm4Gcm(secret)
+	if err != nil {
+		return nil, err
+	}
+	return sealEnvelope(aead, EnvelopeSm4Gcm, plainText, ad)
+}
+
+// DecryptWithAD decrypts cipherText, checking it and ad.
+func (a Sm4GcmCipher) DecryptWithAD(cipherText, secret, ad []byte) (plainText []byte, err error) {
+	return decryptEnvelope(cipherText, secret, ad)
+}
@@ -0,0 +1,97 @@
+package regulation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+)
+
+func TestSm4Gcm(t *testing.T) {
+	// RFC 8998 appendix A.1
+	key, _ := hex.DecodeString("0123456789ABCDEFFEDCBA9876543210")
+	nonce, _ := hex.DecodeString("00001234567800000000ABCD")
+	ad, _ := hex.DecodeString("FEEDFACEDEADBEEFFEEDFACEDEADBEEFABADDAD2")
+	data, _ := hex.DecodeString("AAAAAAAAAAAAAAAABBBBBBBBBBBBBBBBCCCCCCCCCCCCCCCCDDDDDDDDDDDDDDDDEEEEEEEEEEEEEEEEFFFFFFFFFFFFFFFFEEEEEEEEEEEEEEEEAAAAAAAAAAAAAAAA")
+	expected, _ := hex.DecodeString("17F399F08C67D5EE19D0DC9969C4BB7D5FD46FD3756489069157B282BB200735D82710CA5C22F0CCFA7CBF93D496AC15A56834CBCF98C397B4024A2691233B8D" +
+		"83DE3541E4C2B58177E065A9BF7B62EC")
+
+	aead, err := newSm4Gcm(key)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if sealed := aead.Seal(nil, nonce, data, ad); !bytes.Equal(expected, sealed) {
+		t.Fatalf("SM4-GCM sealed %x", sealed)
+	}
+}
+
+func TestSm4GcmCipher(t *testing.T) {
+	var sm4Gcm Sm4GcmCipher
+	for i := 0; i <= 128; i++ {
+		key := make([]byte, 32)
+		data := make([]byte, i)
+		_, _ = rand.Read(key)
+		_, _ = rand.Read(data)
+		ad := key[:i%8]
+		cipherText, err := sm4Gcm.EncryptWithAD(data, key, ad)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if v, _ := envelopeVersion(cipherText); v != EnvelopeSm4Gcm {
+			t.Fatalf("wrong envelope version %d", v)
+		}
+		plainText, err := sm4Gcm.DecryptWithAD(cipherText, key, ad)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if !bytes.Equal(data, plainText) {
+			t.Fatalf("decrypted data is wrong")
+		}
+		for j := len(envelopeMagic); j < len(cipherText); j++ {
+			cipherText[j] ^= 0x01
+			if _, err := sm4Gcm.DecryptWithAD(cipherText, key, ad); err == nil {
+				t.Fatalf("tampered byte %d of %d decrypted", j, len(cipherText))
+			}
+			cipherText[j] ^= 0x01
+		}
+	}
+}
+
+func TestSymmetricCipherByType(t *testing.T) {
+	if _, ok := GetSymmetricCipherByType(crypto.CryptoTypeGM).(Sm4GcmCipher); !ok {
+		t.Fatalf("gm chains must use SM4")
+	}
+	if _, ok := GetSymmetricCipherByType(crypto.CryptoTypeSecp256K1).(AesGcmCipher); !ok {
+		t.Fatalf("secp256k1 chains must use AES")
+	}
+
+	defer crypto.SetLocalAccountType(crypto.LocalAccountType())
+	crypto.SetLocalAccountType(crypto.CryptoTypeGM)
+	if _, ok := GetSymmetricCipher().(Sm4GcmCipher); !ok {
+		t.Fatalf("GetSymmetricCipher does not follow the local account type")
+	}
+
+	// the data stays readable when a chain changes its cipher
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	data := []byte("regulated transaction data")
+	legacy, _ := AesCipher{}.Encrypt(data, key)
+	if _, ok := envelopeVersion(legacy); ok {
+		t.Skip("legacy cipher text taken for an envelope, one in 2^24")
+	}
+	aesGcm, _ := AesGcmCipher{}.Encrypt(data, key)
+	sm4Gcm, _ := Sm4GcmCipher{}.Encrypt(data, key)
+	for _, c := range []SymmetricCipher{AesGcmCipher{}, Sm4GcmCipher{}} {
+		for _, cipherText := range [][]byte{legacy, aesGcm, sm4Gcm} {
+			plainText, err := c.Decrypt(cipherText, key)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			if !bytes.Equal(data, plainText) {
+				t.Fatalf("decrypted data is wrong")
+			}
+		}
+	}
+}
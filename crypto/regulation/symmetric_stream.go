@@ -0,0 +1,302 @@
+package regulation
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// Streams encrypt large payloads in chunks, with the STREAM construction of
+// Hoang, Reyhanitabar, Rogaway and Vizár. A stream is the envelope header,
+// a random salt, then the sealed chunks:
+//
+//	magic || version || salt (16 bytes) || chunk 0 || chunk 1 || ... || last chunk
+//
+// Every chunk but the last holds StreamChunkSize bytes of plain text. The
+// chunks are sealed with a key derived from the secret and the salt,
+// HMAC(secret, salt) with SHA-256 for AES and SM3 for SM4, the header as
+// associated data and the nonce
+//
+//	0 (7 bytes) || chunk index (4 bytes, big endian) || 1 for the last chunk, else 0
+//
+// so that chunks cannot be reordered, and a stream cut at a chunk boundary
+// does not end with a last chunk and fails to decrypt.
+const (
+	// StreamChunkSize is the size of the plain text of the chunks of a stream.
+	StreamChunkSize = 64 * 1024
+
+	// EnvelopeAesGcmStream is the version of AES-GCM streams.
+	EnvelopeAesGcmStream byte = 3
+	// EnvelopeSm4GcmStream is the version of SM4-GCM streams.
+	EnvelopeSm4GcmStream byte = 4
+
+	streamSaltSize   = 16
+	streamHeaderSize = envelopeHeaderSize + streamSaltSize
+	streamOverhead   = 16
+	streamMaxChunks  = 1 << 32
+)
+
+var (
+	// ErrStreamTruncated is returned when a stream ends before its last chunk.
+	ErrStreamTruncated = errors.New("encrypted stream is truncated")
+	// ErrStreamTooLong is returned when a stream has more chunks than its nonces.
+	ErrStreamTooLong = errors.New("encrypted stream is too long")
+)
+
+// newStreamAEAD returns the AEAD of the stream version for secret and salt.
+func newStreamAEAD(version byte, secret, salt []byte) (cipher.AEAD, error) {
+	var h func() hash.Hash
+	var newAEAD func([]byte) (cipher.AEAD, error)
+	switch version {
+	case EnvelopeAesGcmStream:
+		h, newAEAD = sha256.New, newAesGcm
+	case EnvelopeSm4GcmStream:
+		h, newAEAD = sm3.New, newSm4Gcm
+	default:
+		return nil, ErrUnknownEnvelope
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("empty stream secret")
+	}
+	mac := hmac.New(h, secret)
+	mac.Write(salt)
+	key := mac.Sum(nil)
+	defer zeroBytes(key)
+	if version == EnvelopeSm4GcmStream {
+		key = key[:16]
+	}
+	return newAEAD(key)
+}
+
+func streamNonce(nonce []byte, index uint64, last bool) {
+	binary.BigEndian.PutUint32(nonce[7:11], uint32(index))
+	nonce[11] = 0
+	if last {
+		nonce[11] = 1
+	}
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// streamVersionByType returns the stream version for chains of cryptoType,
+// SM4 for GM keys and AES otherwise like GetSymmetricCipherByType.
+func streamVersionByType(cryptoType string) byte {
+	if cryptoType == crypto.CryptoTypeGM {
+		return EnvelopeSm4GcmStream
+	}
+	return EnvelopeAesGcmStream
+}
+
+//-------------------------------------
+
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	nonce  [12]byte
+	index  uint64
+	buf    []byte
+	out    []byte
+	err    error
+}
+
+// NewEncryptWriter returns a writer that encrypts what is written to it to w
+// with secret, with SM4 on chains of GM keys and AES otherwise. The stream is
+// only complete once the writer is closed, which does not close w.
+func NewEncryptWriter(w io.Writer, secret []byte) (io.WriteCloser, error) {
+	return NewEncryptWriterByType(w, secret, crypto.LocalAccountType())
+}
+
+// NewEncryptWriterByType is NewEncryptWriter for chains of cryptoType.
+func NewEncryptWriterByType(w io.Writer, secret []byte, cryptoType string) (io.WriteCloser, error) {
+	version := streamVersionByType(cryptoType)
+	header := make([]byte, streamHeaderSize)
+	copy(header, envelopeMagic)
+	header[len(envelopeMagic)] = version
+	if _, err := io.ReadFull(rand.Reader, header[envelopeHeaderSize:]); err != nil {
+		return nil, err
+	}
+	aead, err := newStreamAEAD(version, secret, header[envelopeHeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		buf:    make([]byte, 0, StreamChunkSize),
+		out:    make([]byte, 0, StreamChunkSize+streamOverhead),
+	}, nil
+}
+
+func (ew *encryptWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// a full chunk is only sealed once more data comes, the last chunk
+		// is sealed by Close
+		if len(ew.buf) == StreamChunkSize {
+			if err := ew.seal(false); err != nil {
+				return n, err
+			}
+		}
+		m := copy(ew.buf[len(ew.buf):StreamChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+// Close seals the last chunk.
+func (ew *encryptWriter) Close() error {
+	if ew.err != nil {
+		return ew.err
+	}
+	if err := ew.seal(true); err != nil {
+		return err
+	}
+	ew.err = errors.New("encrypt writer is closed")
+	return nil
+}
+
+func (ew *encryptWriter) seal(last bool) error {
+	if ew.index >= streamMaxChunks {
+		ew.err = ErrStreamTooLong
+		return ew.err
+	}
+	streamNonce(ew.nonce[:], ew.index, last)
+	ew.out = ew.aead.Seal(ew.out[:0], ew.nonce[:], ew.buf, ew.header)
+	zeroBytes(ew.buf)
+	ew.buf = ew.buf[:0]
+	ew.index++
+	if _, err := ew.w.Write(ew.out); err != nil {
+		ew.err = err
+		return err
+	}
+	return nil
+}
+
+//-------------------------------------
+
+type decryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	header []byte
+	nonce  [12]byte
+	index  uint64
+	// in holds the sealed chunk being read and one more byte, to tell
+	// whether it is the last chunk
+	in   []byte
+	next int
+	out  []byte
+	pos  int
+	done bool
+	err  error
+}
+
+// NewDecryptReader returns a reader that decrypts the stream of r written by
+// an encrypt writer with secret, with the cipher of the stream. It returns
+// ErrStreamTruncated at the end of a truncated stream and ErrAuthFailed for a
+// tampered stream, and nothing of a chunk before it is authenticated.
+func NewDecryptReader(r io.Reader, secret []byte) (io.Reader, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrStreamTruncated
+		}
+		return nil, err
+	}
+	version, ok := envelopeVersion(header)
+	if !ok {
+		return nil, ErrUnknownEnvelope
+	}
+	aead, err := newStreamAEAD(version, secret, header[envelopeHeaderSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		in:     make([]byte, StreamChunkSize+streamOverhead+1),
+	}, nil
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for dr.pos == len(dr.out) {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		if dr.done {
+			return 0, io.EOF
+		}
+		dr.err = dr.open()
+	}
+	n := copy(p, dr.out[dr.pos:])
+	dr.pos += n
+	return n, nil
+}
+
+// open reads and opens the next chunk.
+func (dr *decryptReader) open() error {
+	n, err := io.ReadFull(dr.r, dr.in[dr.next:])
+	n += dr.next
+	last := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	if n < streamOverhead {
+		return ErrStreamTruncated
+	}
+	if dr.index >= streamMaxChunks {
+		return ErrStreamTooLong
+	}
+
+	size := n
+	if !last {
+		size = n - 1
+	}
+	streamNonce(dr.nonce[:], dr.index, last)
+	out, err := dr.aead.Open(dr.out[:0], dr.nonce[:], dr.in[:size], dr.header)
+	if err != nil {
+		if last {
+			// a stream cut after a chunk that is not the last
+			streamNonce(dr.nonce[:], dr.index, false)
+			if _, err := dr.aead.Open(nil, dr.nonce[:], dr.in[:size], dr.header); err == nil {
+				return ErrStreamTruncated
+			}
+		}
+		return ErrAuthFailed
+	}
+	dr.out, dr.pos = out, 0
+	dr.index++
+	dr.done = last
+	if !last {
+		// the extra byte starts the next chunk
+		dr.in[0] = dr.in[size]
+		dr.next = 1
+	}
+	return nil
+}
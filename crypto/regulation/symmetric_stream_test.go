@@ -0,0 +1,146 @@
+package regulation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+)
+
+func encryptStream(t *testing.T, data, secret []byte, cryptoType string) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriterByType(&buf, secret, cryptoType)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	// odd sized writes, across the chunks
+	for p := data; len(p) > 0; {
+		n := 1000
+		if n > len(p) {
+			n = len(p)
+		}
+		if _, err := w.Write(p[:n]); err != nil {
+			t.Fatalf(err.Error())
+		}
+		p = p[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(stream, secret []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(stream), secret)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestStream(t *testing.T) {
+	secret := []byte("regulation stream secret")
+	data := make([]byte, 3*StreamChunkSize+1)
+	rand.Read(data)
+	sizes := []int{0, 1, StreamChunkSize - 1, StreamChunkSize, StreamChunkSize + 1, 2 * StreamChunkSize, len(data)}
+	for _, cryptoType := range []string{crypto.CryptoTypeGM, crypto.CryptoTypeSecp256K1} {
+		for _, size := range sizes {
+			stream := encryptStream(t, data[:size], secret, cryptoType)
+			if version, _ := envelopeVersion(stream); version != streamVersionByType(cryptoType) {
+				t.Fatalf("%s: stream version %d", cryptoType, version)
+			}
+			chunks := size/StreamChunkSize + 1
+			if size > 0 && size%StreamChunkSize == 0 {
+				chunks--
+			}
+			if len(stream) != streamHeaderSize+size+chunks*streamOverhead {
+				t.Fatalf("%s %d: stream of %d bytes", cryptoType, size, len(stream))
+			}
+			plainText, err := decryptStream(stream, secret)
+			if err != nil {
+				t.Fatalf("%s %d: %v", cryptoType, size, err)
+			}
+			if !bytes.Equal(plainText, data[:size]) {
+				t.Fatalf("%s %d: decrypted data is wrong", cryptoType, size)
+			}
+		}
+	}
+}
+
+func TestStreamTampered(t *testing.T) {
+	secret := []byte("regulation stream secret")
+	data := make([]byte, 2*StreamChunkSize+100)
+	rand.Read(data)
+	stream := encryptStream(t, data, secret, crypto.CryptoTypeSecp256K1)
+	chunk := StreamChunkSize + streamOverhead
+
+	if _, err := decryptStream(stream, []byte("another secret")); err != ErrAuthFailed {
+		t.Fatalf("decrypted with another secret: %v", err)
+	}
+
+	// cut at a chunk boundary or within a chunk
+	for _, size := range []int{0, streamHeaderSize - 1, streamHeaderSize, streamHeaderSize + chunk, streamHeaderSize + 2*chunk, len(stream) - 1} {
+		if _, err := decryptStream(stream[:size], secret); err != ErrStreamTruncated && err != ErrAuthFailed {
+			t.Fatalf("decrypted a stream cut at %d: %v", size, err)
+		}
+	}
+	if _, err := decryptStream(stream[:streamHeaderSize+chunk], secret); err != ErrStreamTruncated {
+		t.Fatalf("stream cut after a chunk: %v", err)
+	}
+
+	// swapped chunks, a tampered header and trailing data
+	swapped := append([]byte{}, stream...)
+	copy(swapped[streamHeaderSize:], stream[streamHeaderSize+chunk:streamHeaderSize+2*chunk])
+	copy(swapped[streamHeaderSize+chunk:], stream[streamHeaderSize:streamHeaderSize+chunk])
+	if _, err := decryptStream(swapped, secret); err != ErrAuthFailed {
+		t.Fatalf("decrypted swapped chunks: %v", err)
+	}
+	for _, i := range []int{envelopeHeaderSize, streamHeaderSize + 10, len(stream) - 1} {
+		tampered := append([]byte{}, stream...)
+		tampered[i] ^= 1
+		if _, err := decryptStream(tampered, secret); err != ErrAuthFailed {
+			t.Fatalf("decrypted a stream tampered at %d: %v", i, err)
+		}
+	}
+	if _, err := decryptStream(append(append([]byte{}, stream...), 0), secret); err != ErrAuthFailed {
+		t.Fatalf("decrypted a stream with trailing data: %v", err)
+	}
+	tampered := append([]byte{}, stream...)
+	tampered[len(envelopeMagic)] = EnvelopeAesGcm
+	if _, err := decryptStream(tampered, secret); err != ErrUnknownEnvelope {
+		t.Fatalf("decrypted a stream of another version: %v", err)
+	}
+
+	// nothing of a tampered chunk is returned
+	tampered = append([]byte{}, stream...)
+	tampered[streamHeaderSize+chunk+1] ^= 1
+	r, err := NewDecryptReader(bytes.NewReader(tampered), secret)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	plainText, err := ioutil.ReadAll(r)
+	if err != ErrAuthFailed || !bytes.Equal(plainText, data[:StreamChunkSize]) {
+		t.Fatalf("read %d bytes of a tampered stream: %v", len(plainText), err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != ErrAuthFailed {
+		t.Fatalf("read after an error: %v", err)
+	}
+}
+
+func TestStreamWriterClosed(t *testing.T) {
+	w, err := NewEncryptWriter(ioutil.Discard, []byte("regulation stream secret"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if _, err := w.Write([]byte("data")); err == nil {
+		t.Fatalf("wrote to a closed writer")
+	}
+	if _, err := NewEncryptWriter(ioutil.Discard, nil); err == nil {
+		t.Fatalf("created a writer without a secret")
+	}
+}
@@ -0,0 +1,372 @@
+package regulation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// Threshold regulation: n regulators share the regulator key x of Y = x*G so
+// that any t of them open a record (C1, C2) together, and fewer learn nothing.
+//
+// The key is generated without a dealer (Pedersen's DKG with Feldman
+// commitments). Each regulator i deals a random polynomial fi of degree t-1:
+// it broadcasts the commitments Cim = aim*G to its coefficients and sends
+// fi(j) privately to each regulator j, who checks it against them with
+// VerifyDealShare. The dealers whose shares do not verify are left out, and
+// with the qualified dealers Q
+//
+//	x  = sum fi(0)            (never computed)
+//	xj = sum fi(j)            CombineDealShares, the secret share of j
+//	Y  = sum Ci0              RegulatorGroupKey
+//	Yj = xj*G                 RegulatorPublicShare, from the commitments
+//
+// Y is used as a single regulator key with GenerateAndEncryptSymmetricKey. To
+// open a record, regulator j publishes Dj = xj*C1 with a DLEQ proof that
+// log_G(Yj) = log_C1(Dj), and any t verified partial decryptions give
+//
+//	x*C1 = sum lj*Dj,  M = C2 - x*C1
+//
+// with lj the Lagrange coefficients at 0.
+
+const partialDecryptionDomain = "tc-libs regulation partial decryption"
+
+var (
+	// ErrInvalidDealShare is returned when a share does not match the
+	// commitments of its dealer.
+	ErrInvalidDealShare = errors.New("deal share does not match the commitments")
+	// ErrInvalidPartialDecryption is returned when the proof of a partial
+	// decryption does not verify.
+	ErrInvalidPartialDecryption = errors.New("invalid partial decryption proof")
+	// ErrNotEnoughPartialDecryptions is returned when less than the threshold
+	// of distinct partial decryptions are combined.
+	ErrNotEnoughPartialDecryptions = errors.New("not enough partial decryptions")
+)
+
+func checkRegulatorThreshold(threshold, n int) error {
+	if threshold < 1 || threshold > n {
+		return fmt.Errorf("threshold %d is not in [1, %d]", threshold, n)
+	}
+	return nil
+}
+
+// regulatorIndex returns the regulator index i, from 1, as a scalar.
+func regulatorIndex(i int) (*ristretto.Scalar, error) {
+	if i < 1 {
+		return nil, fmt.Errorf("regulator index %d is not positive", i)
+	}
+	var buf [32]byte
+	for j := 0; j < 8; j++ {
+		buf[j] = byte(uint64(i) >> (8 * uint(j)))
+	}
+	return new(ristretto.Scalar).SetBytes(&buf), nil
+}
+
+// RegulatorDealer is the secret polynomial a regulator deals in the key
+// generation.
+type RegulatorDealer struct {
+	Index     int
+	Threshold int
+	N         int
+	coeffs    []ristretto.Scalar
+}
+
+// NewRegulatorDealer returns the dealer of regulator index, in [1, n], for a
+// key that threshold regulators can use.
+func NewRegulatorDealer(index, threshold, n int) (*RegulatorDealer, error) {
+	if err := checkRegulatorThreshold(threshold, n); err != nil {
+		return nil, err
+	}
+	if index < 1 || index > n {
+		return nil, fmt.Errorf("regulator index %d is not in [1, %d]", index, n)
+	}
+	d := &RegulatorDealer{Index: index, Threshold: threshold, N: n, coeffs: make([]ristretto.Scalar, threshold)}
+	for m := range d.coeffs {
+		d.coeffs[m].Rand()
+	}
+	return d, nil
+}
+
+// Commitments returns the commitments to the coefficients of the polynomial,
+// which the dealer broadcasts to all the regulators.
+func (d *RegulatorDealer) Commitments() [][32]byte {
+	out := make([][32]byte, len(d.coeffs))
+	var C ristretto.Point
+	for m := range d.coeffs {
+		C.ScalarMultBase(&d.coeffs[m])
+		C.BytesInto(&out[m])
+	}
+	return out
+}
+
+// ShareFor returns the share of regulator index, which the dealer sends to it
+// over a private channel.
+func (d *RegulatorDealer) ShareFor(index int) (*[32]byte, error) {
+	if index < 1 || index > d.N {
+		return nil, fmt.Errorf("regulator index %d is not in [1, %d]", index, d.N)
+	}
+	x, err := regulatorIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	var y ristretto.Scalar
+	y.Set(&d.coeffs[len(d.coeffs)-1])
+	for m := len(d.coeffs) - 2; m >= 0; m-- {
+		y.MulAdd(&y, x, &d.coeffs[m])
+	}
+	share := new([32]byte)
+	y.BytesInto(share)
+	y.SetZero()
+	return share, nil
+}
+
+// Reset zeroes the polynomial, once the shares are sent.
+func (d *RegulatorDealer) Reset() {
+	for m := range d.coeffs {
+		d.coeffs[m].SetZero()
+	}
+}
+
+// checkCommitments checks that a dealer committed to a polynomial of degree
+// threshold-1: with a higher degree, threshold regulators could not open the
+// records.
+func checkCommitments(threshold int, commitments [][32]byte) error {
+	if threshold < 1 {
+		return fmt.Errorf("threshold %d is not positive", threshold)
+	}
+	if len(commitments) != threshold {
+		return fmt.Errorf("%d commitments for threshold %d", len(commitments), threshold)
+	}
+	return nil
+}
+
+// evalCommitments returns sum index^m * commitments[m], the public value of
+// the polynomial of the commitments at index.
+func evalCommitments(index int, commitments [][32]byte) (*ristretto.Point, error) {
+	if len(commitments) == 0 {
+		return nil, errors.New("no commitments")
+	}
+	x, err := regulatorIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	var P, C ristretto.Point
+	P.SetZero()
+	for m := len(commitments) - 1; m >= 0; m-- {
+		if !C.SetBytes(&commitments[m]) {
+			return nil, fmt.Errorf("commitment %d is not a valid point in the curve. ", m)
+		}
+		P.PublicScalarMult(&P, x)
+		P.Add(&P, &C)
+	}
+	return &P, nil
+}
+
+// VerifyDealShare checks the share of regulator index against the commitments
+// of its dealer, which must be threshold, one per coefficient.
+func VerifyDealShare(index, threshold int, share *[32]byte, commitments [][32]byte) error {
+	if err := checkCommitments(threshold, commitments); err != nil {
+		return err
+	}
+	P, err := evalCommitments(index, commitments)
+	if err != nil {
+		return err
+	}
+	var y ristretto.Scalar
+	if !setCanonicalScalar(&y, share) {
+		return ErrInvalidDealShare
+	}
+	var Q ristretto.Point
+	Q.PublicScalarMultBase(&y)
+	if !P.Equals(&Q) {
+		return ErrInvalidDealShare
+	}
+	return nil
+}
+
+// CombineDealShares returns the secret share of a regulator, the sum of the
+// verified shares of the qualified dealers.
+func CombineDealShares(shares []*[32]byte) (*[32]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no deal shares")
+	}
+	var x, y ristretto.Scalar
+	x.SetZero()
+	for _, share := range shares {
+		if !setCanonicalScalar(&y, share) {
+			return nil, ErrInvalidDealShare
+		}
+		x.Add(&x, &y)
+	}
+	out := new([32]byte)
+	x.BytesInto(out)
+	x.SetZero()
+	y.SetZero()
+	return out, nil
+}
+
+// RegulatorGroupKey returns the shared regulator key Y of the commitments of
+// the qualified dealers.
+func RegulatorGroupKey(threshold int, commitments [][][32]byte) (*[32]byte, error) {
+	return RegulatorPublicShare(0, threshold, commitments)
+}
+
+// RegulatorPublicShare returns Yj = xj*G of regulator index, from the
+// commitments of the qualified dealers, to verify its partial decryptions.
+// Index 0 gives the group key.
+func RegulatorPublicShare(index, threshold int, commitments [][][32]byte) (*[32]byte, error) {
+	if len(commitments) == 0 {
+		return nil, errors.New("no dealers")
+	}
+	var Y ristretto.Point
+	Y.SetZero()
+	for i, c := range commitments {
+		if err := checkCommitments(threshold, c); err != nil {
+			return nil, fmt.Errorf("dealer %d: %v", i, err)
+		}
+		var P *ristretto.Point
+		if index == 0 {
+			P = new(ristretto.Point)
+			if !P.SetBytes(&c[0]) {
+				return nil, fmt.Errorf("commitment 0 is not a valid point in the curve. ")
+			}
+		} else {
+			var err error
+			if P, err = evalCommitments(index, c); err != nil {
+				return nil, err
+			}
+		}
+		Y.Add(&Y, P)
+	}
+	out := new([32]byte)
+	Y.BytesInto(out)
+	return out, nil
+}
+
+// PartialDecryption is the share Dj = xj*C1 of regulator Index in the opening
+// of a record, with the proof that it used its key share.
+type PartialDecryption struct {
+	Index int
+	D     [32]byte
+	Proof DLEQProof
+}
+
+// PartialDecrypt returns the partial decryption of the record (C1, C2) by
+// regulator index with its secret share x.
+func PartialDecrypt(index int, C1 *[32]byte, x *[32]byte) (*PartialDecryption, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("regulator index %d is not positive", index)
+	}
+	var C1Point ristretto.Point
+	if !C1Point.SetBytes(C1) {
+		return nil, fmt.Errorf("C1 is not a valid point in the curve. ")
+	}
+	var xScalar ristretto.Scalar
+	xScalar.SetBytes(x)
+	defer xScalar.SetZero()
+
+	var G, Y, D ristretto.Point
+	G.SetBase()
+	Y.ScalarMultBase(&xScalar)
+	D.ScalarMult(&C1Point, &xScalar)
+
+	pd := &PartialDecryption{Index: index}
+	D.BytesInto(&pd.D)
+	pd.Proof = *newDLEQProof(partialDecryptionDomain, &xScalar, &G, &C1Point, &Y, &D)
+	return pd, nil
+}
+
+// VerifyPartialDecryption checks the partial decryption of C1 against the
+// public share Y of its regulator, see RegulatorPublicShare.
+func VerifyPartialDecryption(pd *PartialDecryption, C1 *[32]byte, Y *[32]byte) error {
+	var G, C1Point, YPoint, D ristretto.Point
+	G.SetBase()
+	if !C1Point.SetBytes(C1) {
+		return fmt.Errorf("C1 is not a valid point in the curve. ")
+	}
+	if !YPoint.SetBytes(Y) {
+		return fmt.Errorf("Y is not a valid public key. ")
+	}
+	if !D.SetBytes(&pd.D) {
+		return ErrInvalidPartialDecryption
+	}
+	if !pd.Proof.verify(partialDecryptionDomain, &G, &C1Point, &YPoint, &D) {
+		return ErrInvalidPartialDecryption
+	}
+	return nil
+}
+
+// CombinePartialDecryptions returns the symmetric key of the record (C1, C2)
+// from the partial decryptions of threshold distinct regulators. publicShares
+// holds the public share of each of the n regulators, publicShares[j-1] for
+// regulator j, see RegulatorPublicShare: each partial decryption is verified
+// against the share of its own Index. The partial decryptions with an Index
+// out of [1, n], of a regulator already seen or whose proof does not verify
+// are skipped, and the first threshold remaining ones are used.
+func CombinePartialDecryptions(C1, C2 *[32]byte, pds []*PartialDecryption, publicShares [][32]byte, threshold int) (symk *[32]byte, err error) {
+	var C2Point ristretto.Point
+	if !C2Point.SetBytes(C2) {
+		return nil, fmt.Errorf("C2 is not a valid point in the curve. ")
+	}
+	if threshold < 1 {
+		return nil, ErrNotEnoughPartialDecryptions
+	}
+
+	used := make([]*PartialDecryption, 0, threshold)
+	xs := make([]*ristretto.Scalar, 0, threshold)
+	seen := make(map[int]bool, len(pds))
+	for _, pd := range pds {
+		if len(used) == threshold {
+			break
+		}
+		if pd == nil || pd.Index < 1 || pd.Index > len(publicShares) || seen[pd.Index] {
+			continue
+		}
+		if err := VerifyPartialDecryption(pd, C1, &publicShares[pd.Index-1]); err != nil {
+			if err != ErrInvalidPartialDecryption {
+				return nil, err
+			}
+			continue
+		}
+		seen[pd.Index] = true
+		x, err := regulatorIndex(pd.Index)
+		if err != nil {
+			return nil, err
+		}
+		used = append(used, pd)
+		xs = append(xs, x)
+	}
+	if len(used) < threshold {
+		return nil, ErrNotEnoughPartialDecryptions
+	}
+	pds = used
+
+	// x*C1 = sum lj*Dj, lj = prod xk / (xk - xj)
+	var xC1, D, lD ristretto.Point
+	xC1.SetZero()
+	for i, pd := range pds {
+		var num, den, d ristretto.Scalar
+		num.SetOne()
+		den.SetOne()
+		for k := range pds {
+			if k == i {
+				continue
+			}
+			num.Mul(&num, xs[k])
+			den.Mul(&den, d.Sub(xs[k], xs[i]))
+		}
+		num.Mul(&num, den.Inverse(&den))
+
+		if !D.SetBytes(&pd.D) {
+			return nil, ErrInvalidPartialDecryption
+		}
+		xC1.Add(&xC1, lD.PublicScalarMult(&D, &num))
+	}
+
+	var M ristretto.Point
+	M.Sub(&C2Point, &xC1)
+	symk = new([32]byte)
+	M.BytesInto(symk)
+	return symk, nil
+}
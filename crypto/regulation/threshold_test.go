@@ -0,0 +1,220 @@
+package regulation
+
+import (
+	"testing"
+
+	"github.com/bwesterb/go-ristretto"
+)
+
+// runDKG runs the key generation of n regulators, and returns their secret
+// shares and the commitments of the dealers.
+func runDKG(t *testing.T, threshold, n int) ([]*[32]byte, [][][32]byte) {
+	dealers := make([]*RegulatorDealer, n)
+	commitments := make([][][32]byte, n)
+	for i := range dealers {
+		var err error
+		dealers[i], err = NewRegulatorDealer(i+1, threshold, n)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		commitments[i] = dealers[i].Commitments()
+	}
+
+	xs := make([]*[32]byte, n)
+	for j := 1; j <= n; j++ {
+		shares := make([]*[32]byte, n)
+		for i, dealer := range dealers {
+			share, err := dealer.ShareFor(j)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			if err := VerifyDealShare(j, threshold, share, commitments[i]); err != nil {
+				t.Fatalf("share of dealer %d for %d: %v", i+1, j, err)
+			}
+			shares[i] = share
+		}
+		x, err := CombineDealShares(shares)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		xs[j-1] = x
+	}
+	for _, dealer := range dealers {
+		dealer.Reset()
+	}
+	return xs, commitments
+}
+
+func TestThresholdRegulation(t *testing.T) {
+	const threshold, n = 3, 5
+	xs, commitments := runDKG(t, threshold, n)
+
+	Y, err := RegulatorGroupKey(threshold, commitments)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	Ys := make([][32]byte, n)
+	for j := range Ys {
+		Yj, err := RegulatorPublicShare(j+1, threshold, commitments)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		Ys[j] = *Yj
+		var x ristretto.Scalar
+		x.SetBytes(xs[j])
+		var P ristretto.Point
+		var buf [32]byte
+		P.ScalarMultBase(&x).BytesInto(&buf)
+		if Ys[j] != buf {
+			t.Fatalf("public share %d does not match its secret share", j+1)
+		}
+	}
+
+	_, C1, C2, symk, err := GenerateAndEncryptSymmetricKey(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	pds := make([]*PartialDecryption, n)
+	for j := range pds {
+		if pds[j], err = PartialDecrypt(j+1, C1, xs[j]); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if err := VerifyPartialDecryption(pds[j], C1, &Ys[j]); err != nil {
+			t.Fatalf("partial decryption %d: %v", j+1, err)
+		}
+		// the proof is bound to the regulator
+		if err := VerifyPartialDecryption(pds[j], C1, &Ys[(j+1)%n]); err != ErrInvalidPartialDecryption {
+			t.Fatalf("partial decryption %d verified with another key: %v", j+1, err)
+		}
+	}
+
+	for _, set := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		subset := make([]*PartialDecryption, len(set))
+		for i, j := range set {
+			subset[i] = pds[j]
+		}
+		symk1, err := CombinePartialDecryptions(C1, C2, subset, Ys, threshold)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if *symk1 != *symk {
+			t.Fatalf("CombinePartialDecryptions of %v returned a wrong symk", set)
+		}
+	}
+
+	// less than the threshold of regulators learn nothing
+	symk2, err := CombinePartialDecryptions(C1, C2, pds[:threshold-1], Ys, threshold-1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if *symk2 == *symk {
+		t.Fatalf("opened with less than the threshold of regulators")
+	}
+	if _, err := CombinePartialDecryptions(C1, C2, pds[:threshold-1], Ys, threshold); err != ErrNotEnoughPartialDecryptions {
+		t.Fatalf("combined less than the threshold: %v", err)
+	}
+	if _, err := CombinePartialDecryptions(C1, C2, []*PartialDecryption{pds[0], pds[0], pds[1]}, Ys, threshold); err != ErrNotEnoughPartialDecryptions {
+		t.Fatalf("combined a partial decryption twice: %v", err)
+	}
+
+	// duplicates, unknown regulators and bad proofs are skipped before the
+	// threshold is taken
+	forged := *pds[3]
+	forged.Index = 3
+	unknown := *pds[4]
+	unknown.Index = n + 1
+	mixed := []*PartialDecryption{pds[0], pds[0], &unknown, &forged, pds[1], pds[0], pds[4]}
+	symk3, err := CombinePartialDecryptions(C1, C2, mixed, Ys, threshold)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if *symk3 != *symk {
+		t.Fatalf("CombinePartialDecryptions with skipped entries returned a wrong symk")
+	}
+	if _, err := CombinePartialDecryptions(C1, C2, mixed, Ys[:n-1], threshold); err != ErrNotEnoughPartialDecryptions {
+		t.Fatalf("combined a partial decryption of an unknown regulator: %v", err)
+	}
+}
+
+func TestThresholdRegulationCheating(t *testing.T) {
+	dealer, err := NewRegulatorDealer(1, 2, 3)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	commitments := dealer.Commitments()
+	share, err := dealer.ShareFor(2)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := VerifyDealShare(3, 2, share, commitments); err != ErrInvalidDealShare {
+		t.Fatalf("share verified for another regulator: %v", err)
+	}
+	share[0] ^= 1
+	if err := VerifyDealShare(2, 2, share, commitments); err != ErrInvalidDealShare {
+		t.Fatalf("bad share verified: %v", err)
+	}
+	if _, err := dealer.ShareFor(4); err == nil {
+		t.Fatalf("share for an unknown regulator")
+	}
+	for _, args := range [][3]int{{0, 2, 3}, {4, 2, 3}, {1, 0, 3}, {1, 4, 3}} {
+		if _, err := NewRegulatorDealer(args[0], args[1], args[2]); err == nil {
+			t.Fatalf("dealer %v", args)
+		}
+	}
+
+	// a partial decryption with another key does not verify
+	xs, commitments2 := runDKG(t, 2, 3)
+	Y1, _ := RegulatorPublicShare(1, 2, commitments2)
+	Y, _ := RegulatorGroupKey(2, commitments2)
+	_, C1, _, _, err := GenerateAndEncryptSymmetricKey(Y)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	pd, err := PartialDecrypt(1, C1, xs[1])
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := VerifyPartialDecryption(pd, C1, Y1); err != ErrInvalidPartialDecryption {
+		t.Fatalf("partial decryption with a wrong share verified: %v", err)
+	}
+	pd, _ = PartialDecrypt(1, C1, xs[0])
+	pd.D = *Y1
+	if err := VerifyPartialDecryption(pd, C1, Y1); err != ErrInvalidPartialDecryption {
+		t.Fatalf("forged partial decryption verified: %v", err)
+	}
+}
+
+func TestThresholdRegulationHighDegreeDealer(t *testing.T) {
+	// a dealer of a polynomial of degree 2 for a threshold of 2: its shares
+	// match its commitments, but 2 regulators could not open the records
+	dealer, err := NewRegulatorDealer(1, 3, 3)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	commitments := dealer.Commitments()
+	share, err := dealer.ShareFor(2)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := VerifyDealShare(2, 3, share, commitments); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err := VerifyDealShare(2, 2, share, commitments); err == nil {
+		t.Fatalf("share of a polynomial of too high a degree verified")
+	}
+	if err := VerifyDealShare(2, 4, share, commitments); err == nil {
+		t.Fatalf("share of a polynomial of too low a degree verified")
+	}
+
+	_, honest := runDKG(t, 2, 3)
+	cheating := append(honest[:2:2], commitments)
+	if _, err := RegulatorPublicShare(2, 2, cheating); err == nil {
+		t.Fatalf("public share of a polynomial of too high a degree")
+	}
+	if _, err := RegulatorGroupKey(2, cheating); err == nil {
+		t.Fatalf("group key of a polynomial of too high a degree")
+	}
+	if _, err := RegulatorGroupKey(2, honest); err != nil {
+		t.Fatalf(err.Error())
+	}
+}
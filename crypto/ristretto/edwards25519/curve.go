@@ -379,19 +379,7 @@ func (p *ExtendedPoint) ScalarMult(q *ExtendedPoint, s *[32]byte) *ExtendedPoint
 		}
 		p.SetCompleted(&cp)
 
-		t.Set(&lut[0])
-		b := int32(window[i])
-		for j := 1; j <= 16; j++ {
-			c := equal15(b, int32(-j)) | equal15(b, int32(j))
-			t.ConditionalSet(&lut[j], c)
-		}
-		var v FieldElement
-		c := negative(b)
-		v.Neg(&t.X)
-		t.X.ConditionalSet(&v, c)
-		v.Neg(&t.T)
-		t.T.ConditionalSet(&v, c)
-
+		t.selectWindow5(&lut, int32(window[i]))
 		p.Add(p, &t)
 	}
 
@@ -0,0 +1,201 @@
+package edwards25519
+
+// Below this number of points, Straus' method is faster than Pippenger's.
+const pippengerThreshold = 190
+
+// Set p to the sum of ss[i] * qs[i].  The highest three bits of the scalars
+// have to be cleared.  Returns p.
+func (p *ExtendedPoint) MultiScalarMult(qs []*ExtendedPoint, ss []*[32]byte) *ExtendedPoint {
+	// Straus' method with the signed 5-bit windows of ScalarMult: the
+	// doublings are shared by all points.
+	luts := make([][17]ExtendedPoint, len(qs))
+	windows := make([][51]int8, len(qs))
+	for j, q := range qs {
+		computeScalarWindow5(ss[j], &windows[j])
+		lut := &luts[j]
+		lut[0].SetZero()
+		lut[1].Set(q)
+		for i := 2; i < 16; i += 2 {
+			lut[i].Double(&lut[i>>1])
+			lut[i+1].Add(&lut[i], q)
+		}
+		lut[16].Double(&lut[8])
+	}
+
+	var t ExtendedPoint
+	p.SetZero()
+	for i := 50; i >= 0; i-- {
+		var pp ProjectivePoint
+		var cp CompletedPoint
+		cp.DoubleExtended(p)
+		for z := 0; z < 4; z++ {
+			pp.SetCompleted(&cp)
+			cp.DoubleProjective(&pp)
+		}
+		p.SetCompleted(&cp)
+
+		for j := range luts {
+			t.selectWindow5(&luts[j], int32(windows[j][i]))
+			p.Add(p, &t)
+		}
+	}
+	return p
+}
+
+// Set p to b * q in constant time, where lut holds 0, q, ..., 16q and
+// -16 <= b <= 16.  Returns p.
+func (p *ExtendedPoint) selectWindow5(lut *[17]ExtendedPoint, b int32) *ExtendedPoint {
+	p.Set(&lut[0])
+	for j := 1; j <= 16; j++ {
+		c := equal15(b, int32(-j)) | equal15(b, int32(j))
+		p.ConditionalSet(&lut[j], c)
+	}
+	var v FieldElement
+	c := negative(b)
+	v.Neg(&p.X)
+	p.X.ConditionalSet(&v, c)
+	v.Neg(&p.T)
+	p.T.ConditionalSet(&v, c)
+	return p
+}
+
+// Set p to the sum of ss[i] * qs[i].  The highest three bits of the scalars
+// have to be cleared.  Returns p.
+//
+// Warning: this method uses a non-constant time implementation and thus leaks
+// information about the scalars.
+func (p *ExtendedPoint) VarTimeMultiScalarMult(qs []*ExtendedPoint, ss []*[32]byte) *ExtendedPoint {
+	if len(qs) < pippengerThreshold {
+		return p.varTimeStraus(qs, ss)
+	}
+	return p.varTimePippenger(qs, ss)
+}
+
+// Straus' method with the 5-NAF of VarTimeScalarMult.
+func (p *ExtendedPoint) varTimeStraus(qs []*ExtendedPoint, ss []*[32]byte) *ExtendedPoint {
+	luts := make([][8]ExtendedPoint, len(qs))
+	nafs := make([][256]int8, len(qs))
+	top := -1
+	for j, q := range qs {
+		var dblQ ExtendedPoint
+		dblQ.Double(q)
+		lut := &luts[j]
+		lut[0].Set(q)
+		for i := 1; i < 8; i++ {
+			lut[i].Add(&lut[i-1], &dblQ)
+		}
+		computeScalar5NAF(ss[j], &nafs[j])
+		for i := 255; i > top; i-- {
+			if nafs[j][i] != 0 {
+				top = i
+				break
+			}
+		}
+	}
+
+	// Corner-case: all scalars are zero.
+	p.SetZero()
+	if top == -1 {
+		return p
+	}
+
+	var pp ProjectivePoint
+	var cp CompletedPoint
+	var t ExtendedPoint
+	pp.SetExtended(p)
+	for i := top; i >= 0; i-- {
+		cp.DoubleProjective(&pp)
+		for j := range nafs {
+			d := nafs[j][i]
+			if d == 0 {
+				continue
+			}
+			t.SetCompleted(&cp)
+			if d > 0 {
+				cp.AddExtended(&t, &luts[j][(d+1)/2-1])
+			} else {
+				cp.SubExtended(&t, &luts[j][(1-d)/2-1])
+			}
+		}
+		pp.SetCompleted(&cp)
+	}
+	p.SetCompleted(&cp)
+	return p
+}
+
+// Pippenger's bucket method with signed radix 2^c digits.
+func (p *ExtendedPoint) varTimePippenger(qs []*ExtendedPoint, ss []*[32]byte) *ExtendedPoint {
+	c := uint(6)
+	if len(qs) >= 800 {
+		c = 8
+	} else if len(qs) >= 500 {
+		c = 7
+	}
+	count := 256/int(c) + 1
+	digits := make([]int16, count*len(qs))
+	for j := range qs {
+		computeScalarRadix(ss[j], c, digits[j*count:(j+1)*count])
+	}
+
+	buckets := make([]ExtendedPoint, 1<<(c-1))
+	var running, sum ExtendedPoint
+	p.SetZero()
+	for w := count - 1; w >= 0; w-- {
+		for k := uint(0); k < c; k++ {
+			p.Double(p)
+		}
+
+		for b := range buckets {
+			buckets[b].SetZero()
+		}
+		for j, q := range qs {
+			d := digits[j*count+w]
+			if d > 0 {
+				buckets[d-1].Add(&buckets[d-1], q)
+			} else if d < 0 {
+				buckets[-d-1].Sub(&buckets[-d-1], q)
+			}
+		}
+
+		// sum of (b+1) * buckets[b]
+		running.SetZero()
+		sum.SetZero()
+		for b := len(buckets) - 1; b >= 0; b-- {
+			running.Add(&running, &buckets[b])
+			sum.Add(&sum, &running)
+		}
+		p.Add(p, &sum)
+	}
+	return p
+}
+
+// Computes the signed radix 2^c digits of s, with -2^(c-1) <= digits[i] <
+// 2^(c-1) but for the last one.  The highest three bits of s have to be
+// cleared.
+func computeScalarRadix(s *[32]byte, c uint, digits []int16) {
+	var x [5]uint64
+	for i := 0; i < 4; i++ {
+		x[i] = load8u(s[i*8 : (i+1)*8])
+	}
+	radix := int16(1) << c
+	mask := uint64(radix - 1)
+	carry := int16(0)
+	for i := range digits {
+		pos := uint(i) * c
+		var bits uint64
+		if pos < 256 {
+			idx, bitIdx := pos/64, pos%64
+			bits = x[idx] >> bitIdx
+			if bitIdx+c > 64 {
+				bits |= x[idx+1] << (64 - bitIdx)
+			}
+		}
+		d := int16(bits&mask) + carry
+		if i == len(digits)-1 {
+			digits[i] = d
+			break
+		}
+		carry = (d + radix/2) >> c
+		digits[i] = d - carry<<c
+	}
+}
@@ -0,0 +1,98 @@
+package edwards25519
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestComputeScalarRadix(t *testing.T) {
+	rnd := rand.New(rand.NewSource(37))
+	var rs, s [32]byte
+	var sbi, dbi big.Int
+	for _, c := range []uint{6, 7, 8} {
+		for i := 0; i < 1000; i++ {
+			rnd.Read(s[0:32])
+			s[31] &= 31
+			digits := make([]int16, 256/int(c)+1)
+			computeScalarRadix(&s, c, digits)
+			for j := 0; j < 32; j++ {
+				rs[j] = s[31-j]
+			}
+			sbi.SetBytes(rs[:])
+			dbi.SetUint64(0)
+			for j := len(digits) - 1; j >= 0; j-- {
+				if j < len(digits)-1 && (digits[j] < -(1<<(c-1)) || digits[j] >= 1<<(c-1)) {
+					t.Fatalf("radix 2^%d digit %d of %v out of range: %d", c, j, s, digits[j])
+				}
+				dbi.Lsh(&dbi, c)
+				dbi.Add(&dbi, big.NewInt(int64(digits[j])))
+			}
+			if dbi.Cmp(&sbi) != 0 {
+				t.Fatalf("radix 2^%d(%v) = %v  %v != %v", c, s, digits, &sbi, &dbi)
+			}
+		}
+	}
+}
+
+func randomMultiScalarMultArgs(rnd *rand.Rand, n int) ([]*ExtendedPoint, []*[32]byte) {
+	var fe FieldElement
+	var cp CompletedPoint
+	qs := make([]*ExtendedPoint, n)
+	ss := make([]*[32]byte, n)
+	for i := range qs {
+		var buf [32]byte
+		rnd.Read(buf[:])
+		fe.SetBytes(&buf)
+		cp.SetRistrettoElligator2(&fe)
+		qs[i] = new(ExtendedPoint).SetCompleted(&cp)
+		ss[i] = new([32]byte)
+		rnd.Read(ss[i][:])
+		ss[i][31] &= 31
+	}
+	return qs, ss
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	rnd := rand.New(rand.NewSource(37))
+	// Straus, and Pippenger for every window size
+	for _, n := range []int{0, 1, 2, 17, pippengerThreshold, 500, 800} {
+		qs, ss := randomMultiScalarMultArgs(rnd, n)
+		// zero scalars and repeated points
+		if n > 2 {
+			*ss[1] = [32]byte{}
+			qs[2] = qs[0]
+		}
+		var want, p1, p2, t1 ExtendedPoint
+		want.SetZero()
+		for i := range qs {
+			t1.ScalarMult(qs[i], ss[i])
+			want.Add(&want, &t1)
+		}
+		p1.VarTimeMultiScalarMult(qs, ss)
+		if p1.RistrettoEqualsI(&want) != 1 {
+			t.Fatalf("VarTimeMultiScalarMult of %d points = %v != %v", n, p1, want)
+		}
+		if n > 200 {
+			continue
+		}
+		p2.MultiScalarMult(qs, ss)
+		if p2.RistrettoEqualsI(&want) != 1 {
+			t.Fatalf("MultiScalarMult of %d points = %v != %v", n, p2, want)
+		}
+	}
+
+	// all scalars zero
+	qs, ss := randomMultiScalarMultArgs(rnd, 3)
+	for _, s := range ss {
+		*s = [32]byte{}
+	}
+	var zero, p ExtendedPoint
+	zero.SetZero()
+	if p.VarTimeMultiScalarMult(qs, ss).RistrettoEqualsI(&zero) != 1 {
+		t.Fatalf("VarTimeMultiScalarMult of zero scalars = %v", p)
+	}
+	if p.MultiScalarMult(qs, ss).RistrettoEqualsI(&zero) != 1 {
+		t.Fatalf("MultiScalarMult of zero scalars = %v", p)
+	}
+}
@@ -0,0 +1,83 @@
+package ristretto
+
+import (
+	"fmt"
+	"testing"
+)
+
+func randomPointsAndScalars(n int) ([]*Point, []*Scalar) {
+	points := make([]*Point, n)
+	scalars := make([]*Scalar, n)
+	for i := range points {
+		points[i] = new(Point).Rand()
+		scalars[i] = new(Scalar).Rand()
+	}
+	return points, scalars
+}
+
+func TestPointMultiScalarMult(t *testing.T) {
+	for _, n := range []int{1, 5, 300} {
+		points, scalars := randomPointsAndScalars(n)
+		var want, p, q Point
+		want.SetZero()
+		for i := range points {
+			want.Add(&want, q.ScalarMult(points[i], scalars[i]))
+		}
+		if !p.PublicMultiScalarMult(points, scalars).Equals(&want) {
+			t.Fatalf("PublicMultiScalarMult of %d points = %v != %v", n, p, want)
+		}
+		if !p.MultiScalarMult(points, scalars).Equals(&want) {
+			t.Fatalf("MultiScalarMult of %d points = %v != %v", n, p, want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("MultiScalarMult did not panic on lengths mismatch")
+		}
+	}()
+	points, scalars := randomPointsAndScalars(2)
+	new(Point).MultiScalarMult(points, scalars[:1])
+}
+
+var multiScalarMultSizes = []int{4, 16, 64, 256, 1024}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	for _, n := range multiScalarMultSizes {
+		points, scalars := randomPointsAndScalars(n)
+		var p, q Point
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p.SetZero()
+				for j := range points {
+					p.Add(&p, q.ScalarMult(points[j], scalars[j]))
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("straus/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p.MultiScalarMult(points, scalars)
+			}
+		})
+	}
+}
+
+func BenchmarkPublicMultiScalarMult(b *testing.B) {
+	for _, n := range multiScalarMultSizes {
+		points, scalars := randomPointsAndScalars(n)
+		var p, q Point
+		b.Run(fmt.Sprintf("naive/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p.SetZero()
+				for j := range points {
+					p.Add(&p, q.PublicScalarMult(points[j], scalars[j]))
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("multi/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p.PublicMultiScalarMult(points, scalars)
+			}
+		})
+	}
+}
@@ -160,6 +160,44 @@ func (p *Point) PublicScalarMult(q *Point, s *Scalar) *Point {
 	return p
 }
 
+// Sets p to the sum of scalars[i] * points[i].  Returns p.
+//
+// Panics if points and scalars do not have the same length.
+func (p *Point) MultiScalarMult(points []*Point, scalars []*Scalar) *Point {
+	qs, ss := multiScalarMultArgs(points, scalars)
+	p.e().MultiScalarMult(qs, ss)
+	return p
+}
+
+// Sets p to the sum of scalars[i] * points[i] assuming the scalars are *not*
+// secret.  Uses Straus' method for few points and Pippenger's for many.
+// Returns p.
+//
+// Warning: this method uses a non-constant time inmplementation and thus leaks
+// information about the scalars.  Use this function only if they are public
+// knowledge.  Panics if points and scalars do not have the same length.
+func (p *Point) PublicMultiScalarMult(points []*Point, scalars []*Scalar) *Point {
+	qs, ss := multiScalarMultArgs(points, scalars)
+	p.e().VarTimeMultiScalarMult(qs, ss)
+	return p
+}
+
+func multiScalarMultArgs(points []*Point, scalars []*Scalar) (
+	[]*edwards25519.ExtendedPoint, []*[32]byte) {
+	if len(points) != len(scalars) {
+		panic("ristretto: points and scalars do not have the same length")
+	}
+	qs := make([]*edwards25519.ExtendedPoint, len(points))
+	ss := make([]*[32]byte, len(scalars))
+	bufs := make([][32]byte, len(scalars))
+	for i := range points {
+		qs[i] = points[i].e()
+		scalars[i].BytesInto(&bufs[i])
+		ss[i] = &bufs[i]
+	}
+	return qs, ss
+}
+
 // Sets p to s * B, where B is the edwards25519 basepoint. Returns p.
 //
 // Warning: this method uses a non-constant time inmplementation and thus leaks
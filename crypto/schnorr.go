@@ -0,0 +1,289 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/common"
+	"github.com/bwesterb/go-ristretto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Schnorr signatures over the ristretto255 group.
+//
+//     r = H(nonce || x || msg), R = r*G
+//     c = H(challenge || R || P || msg)
+//     s = r + c*x
+//
+// A signature is [R || s || RistrettoMagic], the trailing byte tells it apart
+// from an ed25519 signature in VerifySignature. Schnorr signatures are linear:
+// s*G == R + c*P.
+
+const (
+	PrivKeyRistrettoSize   = 32
+	PubKeyRistrettoSize    = 32
+	SignatureRistrettoSize = 65
+	RistrettoMagic         = 0x20
+)
+
+var (
+	schnorrNonceDomain     = []byte("TC_SCHNORR_RISTRETTO255_NONCE")
+	schnorrChallengeDomain = []byte("TC_SCHNORR_RISTRETTO255_CHALLENGE")
+)
+
+func schnorrChallenge(r, pubKey, msg []byte) *ristretto.Scalar {
+	buf := make([]byte, 0, len(schnorrChallengeDomain)+64+len(msg))
+	buf = append(buf, schnorrChallengeDomain...)
+	buf = append(buf, r...)
+	buf = append(buf, pubKey...)
+	buf = append(buf, msg...)
+	return new(ristretto.Scalar).Derive(buf)
+}
+
+//-------------------------------------
+
+var _ PrivKey = PrivKeyRistretto{}
+
+// PrivKeyRistretto is a little endian ristretto255 scalar.
+type PrivKeyRistretto [PrivKeyRistrettoSize]byte
+
+// Type --
+func (privKey PrivKeyRistretto) Type() string {
+	return CryptoTypeRistretto
+}
+
+// Bytes --
+func (privKey PrivKeyRistretto) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(privKey)
+}
+
+// Raw --
+func (privKey PrivKeyRistretto) Raw() []byte {
+	return privKey[:]
+}
+
+func (privKey PrivKeyRistretto) scalar() *ristretto.Scalar {
+	buf := [32]byte(privKey)
+	return new(ristretto.Scalar).SetBytes(&buf)
+}
+
+// Sign returns a Schnorr signature of msg, the nonce is derived from the key
+// and the message so signing the same message twice gives the same signature.
+func (privKey PrivKeyRistretto) Sign(msg []byte) (Signature, error) {
+	x := privKey.scalar()
+	pubKey := privKey.PubKey().(PubKeyRistretto)
+
+	buf := make([]byte, 0, len(schnorrNonceDomain)+PrivKeyRistrettoSize+len(msg))
+	buf = append(buf, schnorrNonceDomain...)
+	buf = append(buf, privKey[:]...)
+	buf = append(buf, msg...)
+	r := new(ristretto.Scalar).Derive(buf)
+
+	var R ristretto.Point
+	R.ScalarMultBase(r)
+	var rBuf [32]byte
+	R.BytesInto(&rBuf)
+
+	c := schnorrChallenge(rBuf[:], pubKey[:], msg)
+	var s ristretto.Scalar
+	s.MulAdd(c, x, r)
+
+	var sig SignatureRistretto
+	copy(sig[:32], rBuf[:])
+	copy(sig[32:64], s.Bytes())
+	sig[64] = RistrettoMagic
+	return sig, nil
+}
+
+// PubKey --
+func (privKey PrivKeyRistretto) PubKey() PubKey {
+	var P ristretto.Point
+	P.ScalarMultBase(privKey.scalar())
+	var pubKey PubKeyRistretto
+	P.BytesInto((*[32]byte)(&pubKey))
+	return pubKey
+}
+
+// Equals - runs in constant time based on length of the keys.
+func (privKey PrivKeyRistretto) Equals(other PrivKey) bool {
+	if otherRistretto, ok := other.(PrivKeyRistretto); ok {
+		return subtle.ConstantTimeCompare(privKey[:], otherRistretto[:]) == 1
+	}
+	return false
+}
+
+// Reset does nothing: PrivKeyRistretto is an array and Reset zeroes a copy of it,
+// like for PrivKeyEd25519. Clear the key itself with *privKey = PrivKeyRistretto{}.
+func (privKey PrivKeyRistretto) Reset() {}
+
+func (privKey PrivKeyRistretto) MarshalJSON() ([]byte, error) {
+	return serEncodeFroJSON(privKey)
+}
+
+func (privKey *PrivKeyRistretto) UnmarshalJSON(input []byte) error {
+	return serDecodeForJSON(privKey, input)
+}
+
+func makePrivKeyRistretto(x *ristretto.Scalar) PrivKeyRistretto {
+	var privKey PrivKeyRistretto
+	x.BytesInto((*[32]byte)(&privKey))
+	return privKey
+}
+
+// GenPrivKeyRistretto --
+func GenPrivKeyRistretto() (PrivKeyRistretto, error) {
+	var x ristretto.Scalar
+	for {
+		x.Derive(CRandBytes(64))
+		if x.IsNonZeroI() == 1 {
+			return makePrivKeyRistretto(&x), nil
+		}
+	}
+}
+
+// GenPrivKeyRistrettoFromSecret --
+// NOTE: secret should be the output of a KDF like bcrypt,
+// if it's derived from user input.
+func GenPrivKeyRistrettoFromSecret(secret []byte) (PrivKeyRistretto, error) {
+	var x ristretto.Scalar
+	x.Derive(secret)
+	if x.IsNonZeroI() == 0 {
+		return PrivKeyRistretto{}, fmt.Errorf("invalid private key, zero")
+	}
+	return makePrivKeyRistretto(&x), nil
+}
+
+// privKeyRistrettoFromRaw returns the key of the canonical non zero scalar raw.
+func privKeyRistrettoFromRaw(raw []byte) (PrivKeyRistretto, error) {
+	if len(raw) != PrivKeyRistrettoSize {
+		return PrivKeyRistretto{}, fmt.Errorf("invalid length, need %d bytes", PrivKeyRistrettoSize)
+	}
+	var buf [32]byte
+	copy(buf[:], raw)
+	var x ristretto.Scalar
+	x.SetBytes(&buf)
+	privKey := makePrivKeyRistretto(&x)
+	if x.IsNonZeroI() == 0 || !bytes.Equal(privKey[:], raw) {
+		return PrivKeyRistretto{}, fmt.Errorf("invalid private key, not a canonical non zero scalar")
+	}
+	return privKey, nil
+}
+
+//-------------------------------------
+
+var _ PubKey = PubKeyRistretto{}
+
+// PubKeyRistretto is a compressed ristretto255 point.
+type PubKeyRistretto [PubKeyRistrettoSize]byte
+
+// Address is the Ripemd160 of the raw pubkey bytes.
+func (pubKey PubKeyRistretto) Address() Address {
+	return Address(Ripemd160(pubKey[:]))
+}
+
+// Bytes --
+func (pubKey PubKeyRistretto) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(pubKey)
+}
+
+// Raw --
+func (pubKey PubKeyRistretto) Raw() []byte {
+	return pubKey[:]
+}
+
+// VerifyBytes checks s*G - c*P == R.
+func (pubKey PubKeyRistretto) VerifyBytes(msg []byte, sig_ Signature) bool {
+	sig, ok := sig_.(SignatureRistretto)
+	if !ok || sig[64] != RistrettoMagic {
+		return false
+	}
+
+	var P, R ristretto.Point
+	pubBuf := [32]byte(pubKey)
+	if !P.SetBytes(&pubBuf) || P.Equals(new(ristretto.Point).SetZero()) {
+		return false
+	}
+	var rBuf, sBuf [32]byte
+	copy(rBuf[:], sig[:32])
+	copy(sBuf[:], sig[32:64])
+	if !R.SetBytes(&rBuf) {
+		return false
+	}
+	var s ristretto.Scalar
+	s.SetBytes(&sBuf)
+	// s must be canonical, otherwise s+l would be a second valid signature
+	if !bytes.Equal(s.Bytes(), sBuf[:]) {
+		return false
+	}
+
+	c := schnorrChallenge(rBuf[:], pubKey[:], msg)
+	var sG, cP, check ristretto.Point
+	sG.PublicScalarMultBase(&s)
+	cP.PublicScalarMult(&P, c)
+	check.Sub(&sG, &cP)
+	return check.Equals(&R)
+}
+
+func (pubKey PubKeyRistretto) String() string {
+	return fmt.Sprintf("PubKeyRistretto{%v}", hexutil.Encode(pubKey[:]))
+}
+
+// Equals --
+func (pubKey PubKeyRistretto) Equals(other PubKey) bool {
+	if otherRistretto, ok := other.(PubKeyRistretto); ok {
+		return bytes.Equal(pubKey[:], otherRistretto[:])
+	}
+	return false
+}
+
+func (pubKey PubKeyRistretto) MarshalJSON() ([]byte, error) {
+	return serEncodeFroJSON(pubKey)
+}
+
+func (pubKey *PubKeyRistretto) UnmarshalJSON(input []byte) error {
+	return serDecodeForJSON(pubKey, input)
+}
+
+//-------------------------------------
+
+var _ Signature = SignatureRistretto{}
+
+// SignatureRistretto is a Schnorr signature in the [R || s || RistrettoMagic] format.
+type SignatureRistretto [SignatureRistrettoSize]byte
+
+// Bytes --
+func (sig SignatureRistretto) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(sig)
+}
+
+// Raw --
+func (sig SignatureRistretto) Raw() []byte {
+	return sig[:]
+}
+
+// IsZero --
+func (sig SignatureRistretto) IsZero() bool { return len(sig) == 0 }
+
+func (sig SignatureRistretto) String() string {
+	return fmt.Sprintf("/%X.../", common.Fingerprint(sig[:]))
+}
+
+// Equals --
+func (sig SignatureRistretto) Equals(other Signature) bool {
+	if otherRistretto, ok := other.(SignatureRistretto); ok {
+		return subtle.ConstantTimeCompare(sig[:], otherRistretto[:]) == 1
+	}
+	return false
+}
+
+// NewSignatureRistretto --
+func NewSignatureRistretto(data []byte) (Signature, bool) {
+	if len(data) != SignatureRistrettoSize || data[64] != RistrettoMagic {
+		return SignatureRistretto{}, false
+	}
+	var sig SignatureRistretto
+	copy(sig[:], data)
+	return sig, true
+}
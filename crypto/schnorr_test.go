@@ -0,0 +1,88 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndValidateRistretto(t *testing.T) {
+	privKey, err := GenPrivKeyRistretto()
+	require.Nil(t, err)
+	pubKey := privKey.PubKey()
+
+	msg := CRandBytes(128)
+	sig, err := privKey.Sign(msg)
+	require.Nil(t, err)
+	assert.True(t, pubKey.VerifyBytes(msg, sig))
+	assert.True(t, VerifySignature(pubKey.Raw(), msg, sig.Raw()))
+
+	// Deterministic nonces.
+	sig2, err := privKey.Sign(msg)
+	require.Nil(t, err)
+	assert.True(t, sig.Equals(sig2))
+
+	// Mutate the signature, just one bit.
+	sigRistretto := sig.(SignatureRistretto)
+	sigRistretto[40] ^= byte(0x01)
+	assert.False(t, pubKey.VerifyBytes(msg, sigRistretto))
+	assert.False(t, VerifySignature(pubKey.Raw(), msg, sigRistretto.Raw()))
+
+	// The signature is not an ed25519 one.
+	assert.False(t, VerifySignature(pubKey.Raw(), msg, sig.Raw()[:64]))
+	_, err = Sender(msg, sig.Raw())
+	assert.NotNil(t, err)
+}
+
+func TestRistrettoNonCanonicalS(t *testing.T) {
+	privKey, err := GenPrivKeyRistrettoFromSecret([]byte("hello"))
+	require.Nil(t, err)
+	msg := []byte("hello")
+	sig, _ := privKey.Sign(msg)
+	sigRistretto := sig.(SignatureRistretto)
+
+	// s + l encodes the same scalar.
+	var carry uint16
+	for i := 0; i < 32; i++ {
+		v := uint16(sigRistretto[32+i]) + uint16(edL[i]) + carry
+		sigRistretto[32+i] = byte(v)
+		carry = v >> 8
+	}
+	assert.False(t, privKey.PubKey().VerifyBytes(msg, sigRistretto))
+}
+
+func TestRistrettoKeyEncoding(t *testing.T) {
+	privKey, err := GenPrivKeyRistretto()
+	require.Nil(t, err)
+	pubKey := privKey.PubKey().(PubKeyRistretto)
+
+	// The public key matches the scalar multiplication of the private key.
+	var x ristretto.Scalar
+	buf := [32]byte(privKey)
+	x.SetBytes(&buf)
+	var P ristretto.Point
+	P.ScalarMultBase(&x)
+	assert.Equal(t, pubKey[:], P.Bytes())
+
+	bz := pubKey.Bytes()
+	pubKey2, err := PubKeyFromBytes(bz)
+	require.Nil(t, err)
+	assert.True(t, pubKey.Equals(pubKey2))
+
+	privKey2, err := PrivKeyFromBytes(privKey.Bytes())
+	require.Nil(t, err)
+	assert.True(t, privKey.Equals(privKey2))
+}
+
+func BenchmarkRistrettoVerifyBytes(b *testing.B) {
+	privKey, _ := GenPrivKeyRistretto()
+	pubKey := privKey.PubKey()
+	msg := []byte("hello")
+	sig, _ := privKey.Sign(msg)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pubKey.VerifyBytes(msg, sig)
+	}
+}
@@ -0,0 +1,6 @@
+// Package secp256k1 implements the secp256k1 curve and recoverable ECDSA.
+//
+// By default it wraps the bitcoin secp256k1 C library. When cgo is disabled,
+// or with the purego build tag, a pure Go implementation with the same API and
+// the same results is used instead.
+package secp256k1
@@ -0,0 +1,404 @@
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+var (
+	ErrInvalidMsgLen       = errors.New("invalid message length, need 32 bytes")
+	ErrInvalidSignatureLen = errors.New("invalid signature length")
+	ErrInvalidRecoveryID   = errors.New("invalid signature recovery id")
+	ErrInvalidKey          = errors.New("invalid private key")
+	ErrInvalidPubkey       = errors.New("invalid public key")
+	ErrSignFailed          = errors.New("signing failed")
+	ErrRecoverFailed       = errors.New("recovery failed")
+	ErrECDHFailed          = errors.New("ecdh failed")
+)
+
+func checkSignature(sig []byte) error {
+	if len(sig) != 65 {
+		return ErrInvalidSignatureLen
+	}
+	if sig[64] >= 4 {
+		return ErrInvalidRecoveryID
+	}
+	return nil
+}
+
+// The go* functions below are the pure Go implementation of the package API.
+// They follow libsecp256k1 step by step, so both builds give the same
+// signatures and reject the same inputs.
+
+// parseScalar returns buf as an integer and reports whether it is in [1, n).
+func parseScalar(buf []byte) (*big.Int, bool) {
+	k := new(big.Int).SetBytes(buf)
+	return k, k.Sign() > 0 && k.Cmp(theCurve.N) < 0
+}
+
+func scalarBytes(k *big.Int) *[32]byte {
+	var buf [32]byte
+	math.ReadBits(k, buf[:])
+	return &buf
+}
+
+// decompressPoint returns the point of x whose y has the given parity.
+func decompressPoint(x *fieldVal, odd bool) (*projPoint, bool) {
+	var y, rhs fieldVal
+	rhs.square(x)
+	rhs.mul(&rhs, x)
+	rhs.add(&rhs, &fieldCurveB)
+	if !y.sqrt(&rhs) {
+		return nil, false
+	}
+	if y.isOdd() != odd {
+		y.neg(&y)
+	}
+	return new(projPoint).setAffine(x, &y), true
+}
+
+// parsePubkey decodes a compressed, uncompressed or hybrid public key, like
+// secp256k1_ec_pubkey_parse.
+func parsePubkey(pub []byte) (*projPoint, bool) {
+	var buf [32]byte
+	var x, y fieldVal
+	switch {
+	case len(pub) == 33 && (pub[0] == 0x02 || pub[0] == 0x03):
+		copy(buf[:], pub[1:])
+		if !x.setBytes(&buf) {
+			return nil, false
+		}
+		return decompressPoint(&x, pub[0] == 0x03)
+	case len(pub) == 65 && (pub[0] == 0x04 || pub[0] == 0x06 || pub[0] == 0x07):
+		copy(buf[:], pub[1:33])
+		if !x.setBytes(&buf) {
+			return nil, false
+		}
+		copy(buf[:], pub[33:])
+		if !y.setBytes(&buf) {
+			return nil, false
+		}
+		if pub[0] != 0x04 && y.isOdd() != (pub[0] == 0x07) {
+			return nil, false
+		}
+		var lhs, rhs fieldVal
+		lhs.square(&y)
+		rhs.square(&x)
+		rhs.mul(&rhs, &x)
+		rhs.add(&rhs, &fieldCurveB)
+		if !lhs.equal(&rhs) {
+			return nil, false
+		}
+		return new(projPoint).setAffine(&x, &y), true
+	default:
+		return nil, false
+	}
+}
+
+// serializePubkey encodes p, which must not be the point at infinity.
+func serializePubkey(p *projPoint, compressed bool) []byte {
+	x, y := p.affine()
+	var buf [32]byte
+	if compressed {
+		out := make([]byte, 33)
+		out[0] = 0x02
+		if y.isOdd() {
+			out[0] = 0x03
+		}
+		x.bytes(&buf)
+		copy(out[1:], buf[:])
+		return out
+	}
+	out := make([]byte, 65)
+	out[0] = 0x04
+	x.bytes(&buf)
+	copy(out[1:33], buf[:])
+	y.bytes(&buf)
+	copy(out[33:], buf[:])
+	return out
+}
+
+// rfc6979 is the HMAC-SHA256 DRBG of RFC 6979 section 3.2, seeded like
+// nonce_function_rfc6979 with the key and the message.
+type rfc6979 struct {
+	k, v  [32]byte
+	retry bool
+}
+
+func newRFC6979(seckey, msg []byte) *rfc6979 {
+	rng := new(rfc6979)
+	for i := range rng.v {
+		rng.v[i] = 0x01
+	}
+	rng.update(0x00, seckey, msg)
+	rng.update(0x01, seckey, msg)
+	return rng
+}
+
+func (rng *rfc6979) update(b byte, seckey, msg []byte) {
+	mac := hmac.New(sha256.New, rng.k[:])
+	mac.Write(rng.v[:])
+	mac.Write([]byte{b})
+	mac.Write(seckey)
+	mac.Write(msg)
+	mac.Sum(rng.k[:0])
+	rng.hashV()
+}
+
+func (rng *rfc6979) hashV() {
+	mac := hmac.New(sha256.New, rng.k[:])
+	mac.Write(rng.v[:])
+	mac.Sum(rng.v[:0])
+}
+
+// generate returns the next 32 bytes. libsecp256k1 restarts the DRBG and
+// skips the previous outputs for every new attempt, which is the same as
+// going on with the same DRBG.
+func (rng *rfc6979) generate() [32]byte {
+	if rng.retry {
+		mac := hmac.New(sha256.New, rng.k[:])
+		mac.Write(rng.v[:])
+		mac.Write([]byte{0x00})
+		mac.Sum(rng.k[:0])
+		rng.hashV()
+	}
+	rng.hashV()
+	rng.retry = true
+	return rng.v
+}
+
+// signWithNonce is secp256k1_ecdsa_sig_sign, it fails if s is zero. The
+// secret scalars d and the nonce only go through the constant time group and
+// scalar operations.
+func signWithNonce(d, e *scalarVal, nonce *[32]byte) ([]byte, bool) {
+	var R projPoint
+	R.scalarMult(&curveG, nonce)
+	x, y := R.affine()
+	var xBuf [32]byte
+	x.bytes(&xBuf)
+
+	// r = R.x mod n, which is public
+	var r scalarVal
+	var recid byte
+	if !r.setBytes(&xBuf) {
+		recid = 2
+	}
+	if y.isOdd() {
+		recid |= 1
+	}
+
+	// s = (e + r*d) / k
+	var k, s scalarVal
+	k.setBytes(nonce)
+	s.mul(&r, d)
+	s.add(&s, e)
+	s.mul(&s, k.inverse(&k))
+	if s.isZero() {
+		return nil, false
+	}
+
+	var rBuf, sBuf [32]byte
+	r.bytes(&rBuf)
+	s.bytes(&sBuf)
+	sInt := new(big.Int).SetBytes(sBuf[:])
+	N := theCurve.N
+	if sInt.Cmp(new(big.Int).Rsh(N, 1)) > 0 {
+		sInt.Sub(N, sInt)
+		recid ^= 1
+	}
+
+	sig := make([]byte, 65)
+	copy(sig[:32], rBuf[:])
+	math.ReadBits(sInt, sig[32:64])
+	sig[64] = recid
+	return sig, true
+}
+
+func goSign(msg []byte, seckey []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+	var buf [32]byte
+	var d, e scalarVal
+	copy(buf[:], seckey)
+	if !d.setBytes(&buf) || d.isZero() {
+		return nil, ErrInvalidKey
+	}
+	copy(buf[:], msg)
+	e.setBytes(&buf)
+
+	rng := newRFC6979(seckey, msg)
+	for {
+		nonce := rng.generate()
+		var k scalarVal
+		if !k.setBytes(&nonce) || k.isZero() {
+			continue
+		}
+		if sig, ok := signWithNonce(&d, &e, &nonce); ok {
+			return sig, nil
+		}
+	}
+}
+
+// parseCompact returns r and s of a [R || S] signature, both must be below n.
+func parseCompact(sig []byte) (r, s *big.Int, ok bool) {
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:64])
+	if r.Cmp(theCurve.N) >= 0 || s.Cmp(theCurve.N) >= 0 {
+		return nil, nil, false
+	}
+	return r, s, true
+}
+
+func goRecoverPubkey(msg []byte, sig []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+	if err := checkSignature(sig); err != nil {
+		return nil, err
+	}
+	r, s, ok := parseCompact(sig)
+	if !ok || r.Sign() == 0 || s.Sign() == 0 {
+		return nil, ErrRecoverFailed
+	}
+
+	N := theCurve.N
+	rx := new(big.Int).Set(r)
+	if sig[64]&2 != 0 {
+		if rx.Cmp(new(big.Int).Sub(theCurve.P, N)) >= 0 {
+			return nil, ErrRecoverFailed
+		}
+		rx.Add(rx, N)
+	}
+	var x fieldVal
+	x.setBytes(scalarBytes(rx))
+	R, ok := decompressPoint(&x, sig[64]&1 != 0)
+	if !ok {
+		return nil, ErrRecoverFailed
+	}
+
+	// Q = r^-1 * (s*R - e*G)
+	rInv := new(big.Int).ModInverse(r, N)
+	u1 := new(big.Int).SetBytes(msg)
+	u1.Mul(u1, rInv)
+	u1.Neg(u1)
+	u1.Mod(u1, N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, N)
+	var Q projPoint
+	Q.doubleScalarMult(scalarBytes(u1), R, scalarBytes(u2))
+	if Q.isInfinity() {
+		return nil, ErrRecoverFailed
+	}
+	return serializePubkey(&Q, false), nil
+}
+
+func goVerifySignature(pubkey, msg, signature []byte) bool {
+	if len(msg) != 32 || len(signature) != 64 || len(pubkey) == 0 {
+		return false
+	}
+	r, s, ok := parseCompact(signature)
+	if !ok {
+		return false
+	}
+	Q, ok := parsePubkey(pubkey)
+	if !ok {
+		return false
+	}
+	N := theCurve.N
+	// High s values are rejected to prevent malleability, like Sign never
+	// produces them.
+	if r.Sign() == 0 || s.Sign() == 0 || s.Cmp(new(big.Int).Rsh(N, 1)) > 0 {
+		return false
+	}
+
+	sInv := new(big.Int).ModInverse(s, N)
+	u1 := new(big.Int).SetBytes(msg)
+	u1.Mul(u1, sInv)
+	u1.Mod(u1, N)
+	u2 := new(big.Int).Mul(r, sInv)
+	u2.Mod(u2, N)
+	var R projPoint
+	R.doubleScalarMult(scalarBytes(u1), Q, scalarBytes(u2))
+	if R.isInfinity() {
+		return false
+	}
+	x, _ := R.affine()
+	var xBuf [32]byte
+	x.bytes(&xBuf)
+	rx := new(big.Int).SetBytes(xBuf[:])
+	return rx.Mod(rx, N).Cmp(r) == 0
+}
+
+func goECDH(pubkey, seckey []byte) ([]byte, error) {
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+	P, ok := parsePubkey(pubkey)
+	if !ok {
+		return nil, ErrInvalidPubkey
+	}
+	if _, ok := parseScalar(seckey); !ok {
+		return nil, ErrInvalidKey
+	}
+	var k [32]byte
+	copy(k[:], seckey)
+	var S projPoint
+	S.scalarMult(P, &k)
+	secret := sha256.Sum256(serializePubkey(&S, true))
+	return secret[:], nil
+}
+
+func goDecompressPubkey(pubkey []byte) (x, y *big.Int) {
+	if len(pubkey) != 33 {
+		return nil, nil
+	}
+	P, ok := parsePubkey(pubkey)
+	if !ok {
+		return nil, nil
+	}
+	out := serializePubkey(P, false)
+	return new(big.Int).SetBytes(out[1:33]), new(big.Int).SetBytes(out[33:])
+}
+
+func goCompressPubkey(x, y *big.Int) []byte {
+	P, ok := parsePubkey(S256().Marshal(x, y))
+	if !ok {
+		panic("secp256k1: invalid public key")
+	}
+	return serializePubkey(P, true)
+}
+
+// goScalarMult is BitCurve.ScalarMult for a 32 byte scalar, the point is not
+// checked to be on the curve.
+func goScalarMult(Bx, By *big.Int, scalar []byte) (*big.Int, *big.Int) {
+	if _, ok := parseScalar(scalar); !ok {
+		return nil, nil
+	}
+	var buf, k [32]byte
+	var x, y fieldVal
+	math.ReadBits(Bx, buf[:])
+	x.setBytes(&buf)
+	math.ReadBits(By, buf[:])
+	y.setBytes(&buf)
+	copy(k[:], scalar)
+
+	var P projPoint
+	P.scalarMult(new(projPoint).setAffine(&x, &y), &k)
+	x, y = P.affine()
+	x.bytes(&buf)
+	rx := new(big.Int).SetBytes(buf[:])
+	y.bytes(&buf)
+	ry := new(big.Int).SetBytes(buf[:])
+	for i := range k {
+		k[i] = 0
+	}
+	return rx, ry
+}
@@ -140,3 +140,32 @@ int secp256k1_ext_scalar_mul(const secp256k1_context* ctx, unsigned char *point,
 	secp256k1_scalar_clear(&s);
 	return ret;
 }
+
+// secp256k1_ext_ecdh computes the ECDH secret of a serialized public key and
+// a private key.
+//
+// Returns: 1: the secret was computed
+//          0: the public key is invalid
+//         -1: the private key is invalid (zero or overflow)
+// Args:    ctx:        pointer to a context object (cannot be NULL)
+//  Out:    result:     the 32-byte secret
+//  In:     pubkeydata: the public key, compressed or not
+//          pubkeylen:  length of pubkeydata
+//          seckey:     the 32-byte private key
+static int secp256k1_ext_ecdh(
+	const secp256k1_context* ctx,
+	unsigned char *result,
+	const unsigned char *pubkeydata,
+	size_t pubkeylen,
+	const unsigned char *seckey
+) {
+	secp256k1_pubkey pubkey;
+
+	if (!secp256k1_ec_pubkey_parse(ctx, &pubkey, pubkeydata, pubkeylen)) {
+		return 0;
+	}
+	if (!secp256k1_ecdh(ctx, result, &pubkey, seckey)) {
+		return -1;
+	}
+	return 1;
+}
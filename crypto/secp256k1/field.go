@@ -0,0 +1,216 @@
+package secp256k1
+
+import "math/bits"
+
+// fieldVal is an element of the field of integers modulo
+// p = 2^256 - 2^32 - 977, as four little endian 64 bit limbs. All operations
+// keep the value fully reduced and run in constant time.
+type fieldVal [4]uint64
+
+// fieldC is 2^256 - p.
+const fieldC = 0x1000003D1
+
+var fieldP = fieldVal{0xFFFFFFFEFFFFFC2F, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+
+// setBytes sets f to the big endian buf mod p and reports whether buf was
+// already reduced.
+func (f *fieldVal) setBytes(buf *[32]byte) bool {
+	for i := 0; i < 4; i++ {
+		j := 32 - 8*i
+		f[i] = uint64(buf[j-1]) | uint64(buf[j-2])<<8 | uint64(buf[j-3])<<16 | uint64(buf[j-4])<<24 |
+			uint64(buf[j-5])<<32 | uint64(buf[j-6])<<40 | uint64(buf[j-7])<<48 | uint64(buf[j-8])<<56
+	}
+	return f.reduce() == 0
+}
+
+// bytes returns the big endian encoding of f.
+func (f *fieldVal) bytes(buf *[32]byte) {
+	for i := 0; i < 4; i++ {
+		j := 32 - 8*i
+		for k := 0; k < 8; k++ {
+			buf[j-1-k] = byte(f[i] >> (8 * uint(k)))
+		}
+	}
+}
+
+// reduce subtracts p from f if f >= p, it returns 1 if it did.
+func (f *fieldVal) reduce() uint64 {
+	var t fieldVal
+	var carry uint64
+	t[0], carry = bits.Add64(f[0], fieldC, 0)
+	t[1], carry = bits.Add64(f[1], 0, carry)
+	t[2], carry = bits.Add64(f[2], 0, carry)
+	t[3], carry = bits.Add64(f[3], 0, carry)
+	f.cmov(&t, carry)
+	return carry
+}
+
+// cmov sets f to a if cond is 1, cond must be 0 or 1.
+func (f *fieldVal) cmov(a *fieldVal, cond uint64) {
+	mask := -cond
+	for i := range f {
+		f[i] ^= mask & (f[i] ^ a[i])
+	}
+}
+
+func (f *fieldVal) setInt(v uint64) *fieldVal {
+	*f = fieldVal{v}
+	return f
+}
+
+func (f *fieldVal) isZero() bool {
+	return f[0]|f[1]|f[2]|f[3] == 0
+}
+
+func (f *fieldVal) isOdd() bool {
+	return f[0]&1 == 1
+}
+
+func (f *fieldVal) equal(a *fieldVal) bool {
+	return (f[0]^a[0])|(f[1]^a[1])|(f[2]^a[2])|(f[3]^a[3]) == 0
+}
+
+// add sets f = a + b.
+func (f *fieldVal) add(a, b *fieldVal) *fieldVal {
+	var s fieldVal
+	var c1, c2 uint64
+	s[0], c1 = bits.Add64(a[0], b[0], 0)
+	s[1], c1 = bits.Add64(a[1], b[1], c1)
+	s[2], c1 = bits.Add64(a[2], b[2], c1)
+	s[3], c1 = bits.Add64(a[3], b[3], c1)
+
+	// a + b < 2p, so one subtraction of p is enough. When the sum overflows,
+	// s + 2^256 - p cannot overflow again.
+	var t fieldVal
+	t[0], c2 = bits.Add64(s[0], fieldC, 0)
+	t[1], c2 = bits.Add64(s[1], 0, c2)
+	t[2], c2 = bits.Add64(s[2], 0, c2)
+	t[3], c2 = bits.Add64(s[3], 0, c2)
+	s.cmov(&t, c1|c2)
+	*f = s
+	return f
+}
+
+// sub sets f = a - b.
+func (f *fieldVal) sub(a, b *fieldVal) *fieldVal {
+	var s fieldVal
+	var borrow uint64
+	s[0], borrow = bits.Sub64(a[0], b[0], 0)
+	s[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	s[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	s[3], borrow = bits.Sub64(a[3], b[3], borrow)
+
+	// On borrow s = a - b + 2^256, subtracting 2^256 - p gives a - b + p.
+	c := fieldC & -borrow
+	s[0], borrow = bits.Sub64(s[0], c, 0)
+	s[1], borrow = bits.Sub64(s[1], 0, borrow)
+	s[2], borrow = bits.Sub64(s[2], 0, borrow)
+	s[3], _ = bits.Sub64(s[3], 0, borrow)
+	*f = s
+	return f
+}
+
+// neg sets f = -a.
+func (f *fieldVal) neg(a *fieldVal) *fieldVal {
+	var zero fieldVal
+	return f.sub(&zero, a)
+}
+
+// mul sets f = a * b.
+func (f *fieldVal) mul(a, b *fieldVal) *fieldVal {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c uint64
+			lo, c = bits.Add64(lo, t[i+j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[i+j] = lo
+			carry = hi
+		}
+		t[i+4] = carry
+	}
+	f.reduceWide(&t)
+	return f
+}
+
+// square sets f = a * a.
+func (f *fieldVal) square(a *fieldVal) *fieldVal {
+	return f.mul(a, a)
+}
+
+// reduceWide sets f to the 512 bit t mod p, using 2^256 = 2^256 - p mod p.
+func (f *fieldVal) reduceWide(t *[8]uint64) {
+	var r [5]uint64
+	var carry uint64
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(t[4+i], fieldC)
+		var c uint64
+		lo, c = bits.Add64(lo, t[i], 0)
+		hi += c
+		lo, c = bits.Add64(lo, carry, 0)
+		hi += c
+		r[i] = lo
+		carry = hi
+	}
+	r[4] = carry
+
+	// r[4] < 2^34, fold it once more.
+	hi, lo := bits.Mul64(r[4], fieldC)
+	var c uint64
+	r[0], c = bits.Add64(r[0], lo, 0)
+	r[1], c = bits.Add64(r[1], hi, c)
+	r[2], c = bits.Add64(r[2], 0, c)
+	r[3], c = bits.Add64(r[3], 0, c)
+
+	// If that overflowed, the low limbs are tiny and adding 2^256 - p again
+	// cannot overflow.
+	r[0], c = bits.Add64(r[0], fieldC&-c, 0)
+	r[1], c = bits.Add64(r[1], 0, c)
+	r[2], c = bits.Add64(r[2], 0, c)
+	r[3], _ = bits.Add64(r[3], 0, c)
+
+	*f = fieldVal{r[0], r[1], r[2], r[3]}
+	f.reduce()
+}
+
+// pow sets f = a^e, e is public.
+func (f *fieldVal) pow(a *fieldVal, e *fieldVal) *fieldVal {
+	var r fieldVal
+	r.setInt(1)
+	base := *a
+	for i := 255; i >= 0; i-- {
+		r.square(&r)
+		if (e[i/64]>>(uint(i)%64))&1 == 1 {
+			r.mul(&r, &base)
+		}
+	}
+	*f = r
+	return f
+}
+
+var (
+	fieldPMinus2     = fieldVal{0xFFFFFFFEFFFFFC2D, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+	fieldPPlus1Div4  = fieldVal{0xFFFFFFFFBFFFFF0C, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0x3FFFFFFFFFFFFFFF}
+	fieldCurveB      = fieldVal{7}
+	fieldCurveB3     = fieldVal{21}
+	fieldOrderAsElem = fieldVal{0xBFD25E8CD0364141, 0xBAAEDCE6AF48A03B, 0xFFFFFFFFFFFFFFFE, 0xFFFFFFFFFFFFFFFF}
+)
+
+// inverse sets f = 1/a, the inverse of zero is zero.
+func (f *fieldVal) inverse(a *fieldVal) *fieldVal {
+	return f.pow(a, &fieldPMinus2)
+}
+
+// sqrt sets f to a square root of a and reports whether a is a square.
+func (f *fieldVal) sqrt(a *fieldVal) bool {
+	var r, check fieldVal
+	r.pow(a, &fieldPPlus1Div4)
+	check.square(&r)
+	ok := check.equal(a)
+	*f = r
+	return ok
+}
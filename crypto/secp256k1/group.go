@@ -0,0 +1,171 @@
+package secp256k1
+
+import "crypto/subtle"
+
+// The pure Go group operations use the complete projective formulas of Renes,
+// Costello and Batina ("Complete addition formulas for prime order elliptic
+// curves", algorithms 7 and 9 for a = 0). They have no special cases, which
+// keeps the scalar multiplication free of secret dependent branches.
+
+// projPoint is the point (X/Z, Y/Z), the point at infinity is (0, 1, 0).
+type projPoint struct {
+	x, y, z fieldVal
+}
+
+// curveG is the base point of SEC 2 section 2.4.1.
+var curveG = projPoint{
+	x: fieldVal{0x59F2815B16F81798, 0x029BFCDB2DCE28D9, 0x55A06295CE870B07, 0x79BE667EF9DCBBAC},
+	y: fieldVal{0x9C47D08FFB10D4B8, 0xFD17B448A6855419, 0x5DA4FBFC0E1108A8, 0x483ADA7726A3C465},
+	z: fieldVal{1},
+}
+
+func (p *projPoint) setInfinity() *projPoint {
+	p.x = fieldVal{}
+	p.y.setInt(1)
+	p.z = fieldVal{}
+	return p
+}
+
+func (p *projPoint) isInfinity() bool {
+	return p.z.isZero()
+}
+
+// setAffine sets p to (x, y) without checking it is on the curve.
+func (p *projPoint) setAffine(x, y *fieldVal) *projPoint {
+	p.x = *x
+	p.y = *y
+	p.z.setInt(1)
+	return p
+}
+
+// affine returns the affine coordinates of p, which must not be the point at
+// infinity.
+func (p *projPoint) affine() (x, y fieldVal) {
+	var zInv fieldVal
+	zInv.inverse(&p.z)
+	x.mul(&p.x, &zInv)
+	y.mul(&p.y, &zInv)
+	return
+}
+
+// add sets p = a + b.
+func (p *projPoint) add(a, b *projPoint) *projPoint {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldVal
+	t0.mul(&a.x, &b.x)
+	t1.mul(&a.y, &b.y)
+	t2.mul(&a.z, &b.z)
+	t3.add(&a.x, &a.y)
+	t4.add(&b.x, &b.y)
+	t3.mul(&t3, &t4)
+	t4.add(&t0, &t1)
+	t3.sub(&t3, &t4)
+	t4.add(&a.y, &a.z)
+	x3.add(&b.y, &b.z)
+	t4.mul(&t4, &x3)
+	x3.add(&t1, &t2)
+	t4.sub(&t4, &x3)
+	x3.add(&a.x, &a.z)
+	y3.add(&b.x, &b.z)
+	x3.mul(&x3, &y3)
+	y3.add(&t0, &t2)
+	y3.sub(&x3, &y3)
+	x3.add(&t0, &t0)
+	t0.add(&x3, &t0)
+	t2.mul(&fieldCurveB3, &t2)
+	z3.add(&t1, &t2)
+	t1.sub(&t1, &t2)
+	y3.mul(&fieldCurveB3, &y3)
+	x3.mul(&t4, &y3)
+	t2.mul(&t3, &t1)
+	x3.sub(&t2, &x3)
+	y3.mul(&y3, &t0)
+	t1.mul(&t1, &z3)
+	y3.add(&t1, &y3)
+	t0.mul(&t0, &t3)
+	z3.mul(&z3, &t4)
+	z3.add(&z3, &t0)
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// double sets p = 2 * a.
+func (p *projPoint) double(a *projPoint) *projPoint {
+	var t0, t1, t2, x3, y3, z3 fieldVal
+	t0.square(&a.y)
+	z3.add(&t0, &t0)
+	z3.add(&z3, &z3)
+	z3.add(&z3, &z3)
+	t1.mul(&a.y, &a.z)
+	t2.square(&a.z)
+	t2.mul(&fieldCurveB3, &t2)
+	x3.mul(&t2, &z3)
+	y3.add(&t0, &t2)
+	z3.mul(&t1, &z3)
+	t1.add(&t2, &t2)
+	t2.add(&t1, &t2)
+	t0.sub(&t0, &t2)
+	y3.mul(&t0, &y3)
+	y3.add(&x3, &y3)
+	t1.mul(&a.x, &a.y)
+	x3.mul(&t0, &t1)
+	x3.add(&x3, &x3)
+	p.x, p.y, p.z = x3, y3, z3
+	return p
+}
+
+// neg sets p = -a.
+func (p *projPoint) neg(a *projPoint) *projPoint {
+	p.x = a.x
+	p.y.neg(&a.y)
+	p.z = a.z
+	return p
+}
+
+// cmov sets p to a if cond is 1, cond must be 0 or 1.
+func (p *projPoint) cmov(a *projPoint, cond uint64) {
+	p.x.cmov(&a.x, cond)
+	p.y.cmov(&a.y, cond)
+	p.z.cmov(&a.z, cond)
+}
+
+// scalarMult sets p = k * a, k is a big endian scalar. It runs in constant
+// time with a fixed 4 bit window.
+func (p *projPoint) scalarMult(a *projPoint, k *[32]byte) *projPoint {
+	var table [16]projPoint
+	table[0].setInfinity()
+	table[1] = *a
+	for i := 2; i < 16; i++ {
+		table[i].add(&table[i-1], a)
+	}
+
+	var r, t projPoint
+	r.setInfinity()
+	for i := 0; i < 64; i++ {
+		if i > 0 {
+			r.double(&r)
+			r.double(&r)
+			r.double(&r)
+			r.double(&r)
+		}
+		w := k[i/2]
+		if i%2 == 0 {
+			w >>= 4
+		}
+		w &= 0x0F
+		t.setInfinity()
+		for j := 1; j < 16; j++ {
+			t.cmov(&table[j], uint64(subtle.ConstantTimeByteEq(w, byte(j))))
+		}
+		r.add(&r, &t)
+	}
+	*p = r
+	return p
+}
+
+// doubleScalarMult sets p = u1*G + u2*a, only for public scalars.
+func (p *projPoint) doubleScalarMult(u1 *[32]byte, a *projPoint, u2 *[32]byte) *projPoint {
+	var r1, r2 projPoint
+	r1.scalarMult(&curveG, u1)
+	r2.scalarMult(a, u2)
+	return p.add(&r1, &r2)
+}
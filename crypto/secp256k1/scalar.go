@@ -0,0 +1,148 @@
+package secp256k1
+
+import "math/bits"
+
+// scalarVal is an integer modulo the group order
+// n = 2^256 - 0x14551231950B75FC4402DA1732FC9BEBF, as four little endian 64
+// bit limbs. It holds the secret scalars of a signature, all operations keep
+// the value fully reduced and run in constant time.
+type scalarVal [4]uint64
+
+var (
+	scalarN       = scalarVal{0xBFD25E8CD0364141, 0xBAAEDCE6AF48A03B, 0xFFFFFFFFFFFFFFFE, 0xFFFFFFFFFFFFFFFF}
+	scalarNMinus2 = scalarVal{0xBFD25E8CD036413F, 0xBAAEDCE6AF48A03B, 0xFFFFFFFFFFFFFFFE, 0xFFFFFFFFFFFFFFFF}
+	// scalarR2 is 2^512 mod n, to enter the Montgomery domain.
+	scalarR2 = scalarVal{0x896CF21467D7D140, 0x741496C20E7CF878, 0xE697F5E45BCD07C6, 0x9D671CD581C69BC5}
+)
+
+// scalarNInv is -1/n mod 2^64.
+const scalarNInv = 0x4B0DFF665588B13F
+
+// setBytes sets s to the big endian buf mod n and reports whether buf was
+// already reduced.
+func (s *scalarVal) setBytes(buf *[32]byte) bool {
+	for i := 0; i < 4; i++ {
+		j := 32 - 8*i
+		s[i] = uint64(buf[j-1]) | uint64(buf[j-2])<<8 | uint64(buf[j-3])<<16 | uint64(buf[j-4])<<24 |
+			uint64(buf[j-5])<<32 | uint64(buf[j-6])<<40 | uint64(buf[j-7])<<48 | uint64(buf[j-8])<<56
+	}
+	return s.reduce(0) == 0
+}
+
+// bytes returns the big endian encoding of s.
+func (s *scalarVal) bytes(buf *[32]byte) {
+	for i := 0; i < 4; i++ {
+		j := 32 - 8*i
+		for k := 0; k < 8; k++ {
+			buf[j-1-k] = byte(s[i] >> (8 * uint(k)))
+		}
+	}
+}
+
+// reduce subtracts n from carry*2^256 + s if it is at least n, it returns 1
+// if it did. carry*2^256 + s must be below 2n.
+func (s *scalarVal) reduce(carry uint64) uint64 {
+	var t scalarVal
+	var borrow uint64
+	t[0], borrow = bits.Sub64(s[0], scalarN[0], 0)
+	t[1], borrow = bits.Sub64(s[1], scalarN[1], borrow)
+	t[2], borrow = bits.Sub64(s[2], scalarN[2], borrow)
+	t[3], borrow = bits.Sub64(s[3], scalarN[3], borrow)
+	_, borrow = bits.Sub64(carry, 0, borrow)
+	s.cmov(&t, borrow^1)
+	return borrow ^ 1
+}
+
+// cmov sets s to a if cond is 1, cond must be 0 or 1.
+func (s *scalarVal) cmov(a *scalarVal, cond uint64) {
+	mask := -cond
+	for i := range s {
+		s[i] ^= mask & (s[i] ^ a[i])
+	}
+}
+
+func (s *scalarVal) isZero() bool {
+	return s[0]|s[1]|s[2]|s[3] == 0
+}
+
+// add sets s = a + b.
+func (s *scalarVal) add(a, b *scalarVal) *scalarVal {
+	var carry uint64
+	s[0], carry = bits.Add64(a[0], b[0], 0)
+	s[1], carry = bits.Add64(a[1], b[1], carry)
+	s[2], carry = bits.Add64(a[2], b[2], carry)
+	s[3], carry = bits.Add64(a[3], b[3], carry)
+	s.reduce(carry)
+	return s
+}
+
+// montMul sets s = a * b / 2^256, with the word by word Montgomery
+// reduction.
+func (s *scalarVal) montMul(a, b *scalarVal) *scalarVal {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += a * b[i]
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			var c uint64
+			lo, c = bits.Add64(lo, t[j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[j] = lo
+			carry = hi
+		}
+		t[4], t[5] = bits.Add64(t[4], carry, 0)
+
+		// t = (t + m*n) / 2^64, with m such that t + m*n = 0 mod 2^64
+		m := t[0] * scalarNInv
+		hi, lo := bits.Mul64(m, scalarN[0])
+		_, c := bits.Add64(lo, t[0], 0)
+		carry = hi + c
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, scalarN[j])
+			lo, c = bits.Add64(lo, t[j], 0)
+			hi += c
+			lo, c = bits.Add64(lo, carry, 0)
+			hi += c
+			t[j-1] = lo
+			carry = hi
+		}
+		t[3], c = bits.Add64(t[4], carry, 0)
+		t[4] = t[5] + c
+	}
+
+	// t < 2n
+	*s = scalarVal{t[0], t[1], t[2], t[3]}
+	s.reduce(t[4])
+	return s
+}
+
+// mul sets s = a * b.
+func (s *scalarVal) mul(a, b *scalarVal) *scalarVal {
+	s.montMul(a, b)
+	return s.montMul(s, &scalarR2)
+}
+
+// inverse sets s = 1/a, the inverse of zero is zero. It is a^(n-2), with a
+// fixed 4 bit window over the public exponent.
+func (s *scalarVal) inverse(a *scalarVal) *scalarVal {
+	var table [16]scalarVal
+	table[0].montMul(&scalarR2, &scalarVal{1})
+	table[1].montMul(a, &scalarR2)
+	for i := 2; i < 16; i++ {
+		table[i].montMul(&table[i-1], &table[1])
+	}
+
+	r := table[0]
+	for i := 63; i >= 0; i-- {
+		r.montMul(&r, &r)
+		r.montMul(&r, &r)
+		r.montMul(&r, &r)
+		r.montMul(&r, &r)
+		w := (scalarNMinus2[i/16] >> (4 * uint(i%16))) & 0x0F
+		r.montMul(&r, &table[w])
+	}
+	return s.montMul(&r, &scalarVal{1})
+}
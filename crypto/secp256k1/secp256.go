@@ -14,7 +14,8 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package secp256k1 wraps the bitcoin secp256k1 C library.
+// +build cgo,!purego
+
 package secp256k1
 
 /*
@@ -28,6 +29,7 @@ package secp256k1
 #define NDEBUG
 #include "./libsecp256k1/src/secp256k1.c"
 #include "./libsecp256k1/src/modules/recovery/main_impl.h"
+#include "./libsecp256k1/src/modules/ecdh/main_impl.h"
 #include "ext.h"
 
 typedef void (*callbackFunc) (const char* msg, void* data);
@@ -37,9 +39,10 @@ extern void secp256k1GoPanicError(const char* msg, void* data);
 import "C"
 
 import (
-	"errors"
 	"math/big"
 	"unsafe"
+
+	"github.com/ethereum/go-ethereum/common/math"
 )
 
 var context *C.secp256k1_context
@@ -51,16 +54,6 @@ func init() {
 	C.secp256k1_context_set_error_callback(context, C.callbackFunc(C.secp256k1GoPanicError), nil)
 }
 
-var (
-	ErrInvalidMsgLen       = errors.New("invalid message length, need 32 bytes")
-	ErrInvalidSignatureLen = errors.New("invalid signature length")
-	ErrInvalidRecoveryID   = errors.New("invalid signature recovery id")
-	ErrInvalidKey          = errors.New("invalid private key")
-	ErrInvalidPubkey       = errors.New("invalid public key")
-	ErrSignFailed          = errors.New("signing failed")
-	ErrRecoverFailed       = errors.New("recovery failed")
-)
-
 // Sign creates a recoverable ECDSA signature.
 // The produced signature is in the 65-byte [R || S || V] format where V is 0 or 1.
 //
@@ -133,6 +126,32 @@ func VerifySignature(pubkey, msg, signature []byte) bool {
 	return C.secp256k1_ext_ecdsa_verify(context, sigdata, msgdata, keydata, C.size_t(len(pubkey))) != 0
 }
 
+// ECDH computes the Diffie-Hellman secret of a public key, compressed or not,
+// and a private key. The secret is the SHA256 of the shared point in the
+// compressed format.
+func ECDH(pubkey, seckey []byte) ([]byte, error) {
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+	if len(pubkey) == 0 {
+		return nil, ErrInvalidPubkey
+	}
+	var (
+		secret     = make([]byte, 32)
+		secretdata = (*C.uchar)(unsafe.Pointer(&secret[0]))
+		pubkeydata = (*C.uchar)(unsafe.Pointer(&pubkey[0]))
+		seckeydata = (*C.uchar)(unsafe.Pointer(&seckey[0]))
+	)
+	switch C.secp256k1_ext_ecdh(context, secretdata, pubkeydata, C.size_t(len(pubkey)), seckeydata) {
+	case 1:
+		return secret, nil
+	case 0:
+		return nil, ErrInvalidPubkey
+	default:
+		return nil, ErrInvalidKey
+	}
+}
+
 // DecompressPubkey parses a public key in the 33-byte compressed format.
 // It returns non-nil coordinates if the public key is valid.
 func DecompressPubkey(pubkey []byte) (x, y *big.Int) {
@@ -168,12 +187,36 @@ func CompressPubkey(x, y *big.Int) []byte {
 	return out
 }
 
-func checkSignature(sig []byte) error {
-	if len(sig) != 65 {
-		return ErrInvalidSignatureLen
+func (BitCurve *BitCurve) ScalarMult(Bx, By *big.Int, scalar []byte) (*big.Int, *big.Int) {
+	// Ensure scalar is exactly 32 bytes. We pad always, even if
+	// scalar is 32 bytes long, to avoid a timing side channel.
+	if len(scalar) > 32 {
+		panic("can't handle scalars > 256 bits")
+	}
+	// NOTE: potential timing issue
+	padded := make([]byte, 32)
+	copy(padded[32-len(scalar):], scalar)
+	scalar = padded
+
+	// Do the multiplication in C, updating point.
+	point := make([]byte, 64)
+	math.ReadBits(Bx, point[:32])
+	math.ReadBits(By, point[32:])
+	pointPtr := (*C.uchar)(unsafe.Pointer(&point[0]))
+	scalarPtr := (*C.uchar)(unsafe.Pointer(&scalar[0]))
+	res := C.secp256k1_ext_scalar_mul(context, pointPtr, scalarPtr)
+
+	// Unpack the result and clear temporaries.
+	x := new(big.Int).SetBytes(point[:32])
+	y := new(big.Int).SetBytes(point[32:])
+	for i := range point {
+		point[i] = 0
 	}
-	if sig[64] >= 4 {
-		return ErrInvalidRecoveryID
+	for i := range padded {
+		scalar[i] = 0
+	}
+	if res != 1 {
+		return nil, nil
 	}
-	return nil
+	return x, y
 }
@@ -0,0 +1,225 @@
+// +build cgo,!purego
+
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto/randentropy"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// The tests in this file check the pure Go implementation against libsecp256k1
+// on random inputs.
+
+const diffTestCount = 300
+
+func randBytes(n int) []byte {
+	return randentropy.GetEntropyCSPRNG(n)
+}
+
+// randScalarEdge returns a random 32 byte value, sometimes close to n or zero.
+func randScalarEdge() []byte {
+	b := randBytes(32)
+	switch b[0] % 8 {
+	case 0:
+		return make([]byte, 32)
+	case 1:
+		return math.PaddedBigBytes(theCurve.N, 32)
+	case 2:
+		k := new(big.Int).Sub(theCurve.N, big.NewInt(int64(b[1]%4)+1))
+		return math.PaddedBigBytes(k, 32)
+	case 3:
+		return bytes.Repeat([]byte{0xff}, 32)
+	}
+	return b
+}
+
+func TestDiffSign(t *testing.T) {
+	for i := 0; i < diffTestCount; i++ {
+		_, seckey := generateKeyPair()
+		if i%10 == 0 {
+			seckey = randScalarEdge()
+		}
+		msg := randBytes(32)
+		if i%7 == 0 {
+			msg = randScalarEdge()
+		}
+		sig, err := Sign(msg, seckey)
+		goSig, goErr := goSign(msg, seckey)
+		if err != goErr || !bytes.Equal(sig, goSig) {
+			t.Fatalf("sign mismatch\nkey %x\nmsg %x\ncgo %x %v\ngo  %x %v", seckey, msg, sig, err, goSig, goErr)
+		}
+	}
+	for _, n := range []int{0, 31, 33} {
+		_, err := Sign(randBytes(n), randBytes(32))
+		_, goErr := goSign(randBytes(n), randBytes(32))
+		if err != goErr {
+			t.Errorf("msg length %d: cgo %v, go %v", n, err, goErr)
+		}
+		_, err = Sign(randBytes(32), randBytes(n))
+		_, goErr = goSign(randBytes(32), randBytes(n))
+		if err != goErr {
+			t.Errorf("key length %d: cgo %v, go %v", n, err, goErr)
+		}
+	}
+}
+
+func TestDiffRecoverPubkey(t *testing.T) {
+	for i := 0; i < diffTestCount; i++ {
+		msg := randBytes(32)
+		var sig []byte
+		switch i % 3 {
+		case 0:
+			_, seckey := generateKeyPair()
+			sig, _ = Sign(msg, seckey)
+		case 1:
+			sig = randSig()
+		case 2:
+			sig = append(append(randScalarEdge(), randScalarEdge()...), byte(i%5))
+		}
+		pub, err := RecoverPubkey(msg, sig)
+		goPub, goErr := goRecoverPubkey(msg, sig)
+		if err != goErr || !bytes.Equal(pub, goPub) {
+			t.Fatalf("recover mismatch\nmsg %x\nsig %x\ncgo %x %v\ngo  %x %v", msg, sig, pub, err, goPub, goErr)
+		}
+	}
+
+	// r + n is only a valid x coordinate for small r.
+	sig := make([]byte, 65)
+	sig[31], sig[63], sig[64] = 5, 1, 2
+	msg := randBytes(32)
+	for sig[31] < 40 {
+		pub, err := RecoverPubkey(msg, sig)
+		goPub, goErr := goRecoverPubkey(msg, sig)
+		if err != goErr || !bytes.Equal(pub, goPub) {
+			t.Fatalf("recover mismatch\nsig %x\ncgo %x %v\ngo  %x %v", sig, pub, err, goPub, goErr)
+		}
+		sig[31]++
+	}
+}
+
+func TestDiffVerifySignature(t *testing.T) {
+	for i := 0; i < diffTestCount; i++ {
+		pubkey, seckey := generateKeyPair()
+		msg := randBytes(32)
+		sig, _ := Sign(msg, seckey)
+		sig = sig[:64]
+		switch i % 6 {
+		case 1:
+			sig[randBytes(1)[0]%64] ^= 0x01
+		case 2:
+			msg[randBytes(1)[0]%32] ^= 0x01
+		case 3:
+			// high s
+			s := new(big.Int).SetBytes(sig[32:])
+			copy(sig[32:], math.PaddedBigBytes(s.Sub(theCurve.N, s), 32))
+		case 4:
+			x, y := elliptic.Unmarshal(S256(), pubkey)
+			pubkey = CompressPubkey(x, y)
+		case 5:
+			pubkey = append([]byte{}, pubkey...)
+			pubkey[0] = 0x06 | pubkey[64]&1
+			if randBytes(1)[0]%2 == 0 {
+				pubkey[0] ^= 1
+			}
+		}
+		if VerifySignature(pubkey, msg, sig) != goVerifySignature(pubkey, msg, sig) {
+			t.Fatalf("verify mismatch\npub %x\nmsg %x\nsig %x", pubkey, msg, sig)
+		}
+	}
+
+	for i := 0; i < diffTestCount; i++ {
+		pubkey := randBytes(1 + int(randBytes(1)[0]%70))
+		pubkey[0] = []byte{0x02, 0x03, 0x04, 0x06, 0x07}[i%5]
+		msg := randBytes(32)
+		sig := randSig()[:64]
+		if VerifySignature(pubkey, msg, sig) != goVerifySignature(pubkey, msg, sig) {
+			t.Fatalf("verify mismatch\npub %x\nmsg %x\nsig %x", pubkey, msg, sig)
+		}
+	}
+}
+
+func TestDiffECDH(t *testing.T) {
+	for i := 0; i < diffTestCount; i++ {
+		pubkey, _ := generateKeyPair()
+		_, seckey := generateKeyPair()
+		switch i % 4 {
+		case 1:
+			x, y := elliptic.Unmarshal(S256(), pubkey)
+			pubkey = CompressPubkey(x, y)
+		case 2:
+			seckey = randScalarEdge()
+		case 3:
+			pubkey = append([]byte{}, pubkey...)
+			pubkey[1+randBytes(1)[0]%64] ^= 0x01
+		}
+		secret, err := ECDH(pubkey, seckey)
+		goSecret, goErr := goECDH(pubkey, seckey)
+		if err != goErr || !bytes.Equal(secret, goSecret) {
+			t.Fatalf("ecdh mismatch\npub %x\nkey %x\ncgo %x %v\ngo  %x %v", pubkey, seckey, secret, err, goSecret, goErr)
+		}
+	}
+}
+
+func TestDiffCompressPubkey(t *testing.T) {
+	for i := 0; i < diffTestCount; i++ {
+		pubkey, _ := generateKeyPair()
+		x, y := elliptic.Unmarshal(S256(), pubkey)
+		compressed := CompressPubkey(x, y)
+		if goCompressed := goCompressPubkey(x, y); !bytes.Equal(compressed, goCompressed) {
+			t.Fatalf("compress mismatch\npub %x\ncgo %x\ngo  %x", pubkey, compressed, goCompressed)
+		}
+		if i%2 == 1 {
+			compressed = randBytes(33)
+			compressed[0] = 0x02 | compressed[0]&1
+		}
+		x1, y1 := DecompressPubkey(compressed)
+		x2, y2 := goDecompressPubkey(compressed)
+		if (x1 == nil) != (x2 == nil) || x1 != nil && (x1.Cmp(x2) != 0 || y1.Cmp(y2) != 0) {
+			t.Fatalf("decompress mismatch\npub %x\ncgo %x %x\ngo  %x %x", compressed, x1, y1, x2, y2)
+		}
+	}
+}
+
+func TestDiffScalarMult(t *testing.T) {
+	for i := 0; i < diffTestCount; i++ {
+		key, err := ecdsa.GenerateKey(S256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalar := randBytes(32)
+		if i%5 == 0 {
+			scalar = randScalarEdge()
+		}
+		x1, y1 := S256().ScalarMult(key.X, key.Y, scalar)
+		x2, y2 := goScalarMult(key.X, key.Y, scalar)
+		if (x1 == nil) != (x2 == nil) || x1 != nil && (x1.Cmp(x2) != 0 || y1.Cmp(y2) != 0) {
+			t.Fatalf("scalar mult mismatch\nscalar %x\ncgo %x %x\ngo  %x %x", scalar, x1, y1, x2, y2)
+		}
+	}
+}
+
+func BenchmarkGoSign(b *testing.B) {
+	_, seckey := generateKeyPair()
+	msg := randBytes(32)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		goSign(msg, seckey)
+	}
+}
+
+func BenchmarkGoRecover(b *testing.B) {
+	msg := randBytes(32)
+	_, seckey := generateKeyPair()
+	sig, _ := Sign(msg, seckey)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		goRecoverPubkey(msg, sig)
+	}
+}
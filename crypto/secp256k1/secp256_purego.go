@@ -0,0 +1,60 @@
+// +build !cgo purego
+
+package secp256k1
+
+import "math/big"
+
+// Sign creates a recoverable ECDSA signature.
+// The produced signature is in the 65-byte [R || S || V] format where V is 0 or 1.
+//
+// The caller is responsible for ensuring that msg cannot be chosen
+// directly by an attacker. It is usually preferable to use a cryptographic
+// hash function on any input before handing it to this function.
+func Sign(msg []byte, seckey []byte) ([]byte, error) {
+	return goSign(msg, seckey)
+}
+
+// RecoverPubkey returns the the public key of the signer.
+// msg must be the 32-byte hash of the message to be signed.
+// sig must be a 65-byte compact ECDSA signature containing the
+// recovery id as the last element.
+func RecoverPubkey(msg []byte, sig []byte) ([]byte, error) {
+	return goRecoverPubkey(msg, sig)
+}
+
+// VerifySignature checks that the given pubkey created signature over message.
+// The signature should be in [R || S] format.
+func VerifySignature(pubkey, msg, signature []byte) bool {
+	return goVerifySignature(pubkey, msg, signature)
+}
+
+// ECDH computes the Diffie-Hellman secret of a public key, compressed or not,
+// and a private key. The secret is the SHA256 of the shared point in the
+// compressed format.
+func ECDH(pubkey, seckey []byte) ([]byte, error) {
+	return goECDH(pubkey, seckey)
+}
+
+// DecompressPubkey parses a public key in the 33-byte compressed format.
+// It returns non-nil coordinates if the public key is valid.
+func DecompressPubkey(pubkey []byte) (x, y *big.Int) {
+	return goDecompressPubkey(pubkey)
+}
+
+// CompressPubkey encodes a public key to 33-byte compressed format.
+func CompressPubkey(x, y *big.Int) []byte {
+	return goCompressPubkey(x, y)
+}
+
+func (BitCurve *BitCurve) ScalarMult(Bx, By *big.Int, scalar []byte) (*big.Int, *big.Int) {
+	if len(scalar) > 32 {
+		panic("can't handle scalars > 256 bits")
+	}
+	padded := make([]byte, 32)
+	copy(padded[32-len(scalar):], scalar)
+	x, y := goScalarMult(Bx, By, padded)
+	for i := range padded {
+		padded[i] = 0
+	}
+	return x, y
+}
@@ -21,7 +21,9 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"math/big"
 	"testing"
 
 	"github.com/XunleiBlockchain/tc-libs/crypto/randentropy"
@@ -111,6 +113,37 @@ func TestSignAndRecover(t *testing.T) {
 	}
 }
 
+func TestECDH(t *testing.T) {
+	pubkey1, seckey1 := generateKeyPair()
+	pubkey2, seckey2 := generateKeyPair()
+	secret1, err := ECDH(pubkey2, seckey1)
+	if err != nil {
+		t.Fatalf("ecdh error: %s", err)
+	}
+	x, y := elliptic.Unmarshal(S256(), pubkey1)
+	secret2, err := ECDH(CompressPubkey(x, y), seckey2)
+	if err != nil {
+		t.Fatalf("ecdh error: %s", err)
+	}
+	if !bytes.Equal(secret1, secret2) {
+		t.Errorf("secret mismatch: %x != %x", secret1, secret2)
+	}
+
+	// SHA256 of the compressed shared point
+	sx, sy := S256().ScalarMult(x, y, seckey2)
+	want := sha256.Sum256(CompressPubkey(sx, sy))
+	if !bytes.Equal(secret1, want[:]) {
+		t.Errorf("secret mismatch: %x != %x", secret1, want)
+	}
+
+	if _, err := ECDH(pubkey1[:64], seckey1); err != ErrInvalidPubkey {
+		t.Errorf("expected ErrInvalidPubkey, got %v", err)
+	}
+	if _, err := ECDH(pubkey1, make([]byte, 32)); err != ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
 func TestSignDeterministic(t *testing.T) {
 	_, seckey := generateKeyPair()
 	msg := make([]byte, 32)
@@ -217,6 +250,34 @@ func TestRecoverSanity(t *testing.T) {
 	}
 }
 
+func TestScalarInverse(t *testing.T) {
+	N := S256().N
+	inputs := [][]byte{{1}, {2}, new(big.Int).Sub(N, big.NewInt(1)).Bytes()}
+	for i := 0; i < TestCount; i++ {
+		inputs = append(inputs, randentropy.GetEntropyCSPRNG(32))
+	}
+	for _, in := range inputs {
+		var buf [32]byte
+		copy(buf[32-len(in):], in)
+		var a, inv, one scalarVal
+		a.setBytes(&buf)
+		if a.isZero() {
+			continue
+		}
+		inv.inverse(&a)
+		a.bytes(&buf)
+		want := new(big.Int).ModInverse(new(big.Int).SetBytes(buf[:]), N)
+		inv.bytes(&buf)
+		if new(big.Int).SetBytes(buf[:]).Cmp(want) != 0 {
+			t.Fatalf("inverse of %x: have %x, want %x", in, buf, want)
+		}
+		one.mul(&a, &inv)
+		if one != (scalarVal{1}) {
+			t.Fatalf("a * 1/a = %x", one)
+		}
+	}
+}
+
 func BenchmarkSign(b *testing.B) {
 	_, seckey := generateKeyPair()
 	msg := randentropy.GetEntropyCSPRNG(32)
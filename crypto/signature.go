@@ -167,3 +167,69 @@ func NewSignatureGM(data []byte) (Signature, bool) {
 	copy(sig[:], data[:SignatureGMSize])
 	return sig, true
 }
+
+// ------------------------------
+
+const SignatureP256Size = 65
+const P256Magic = 0x10
+
+var _ Signature = SignatureP256{}
+
+// SignatureP256 is a recoverable P-256 signature in the [R || S || V] format.
+type SignatureP256 [SignatureP256Size]byte
+
+// Bytes --
+func (sig SignatureP256) Bytes() []byte {
+	return bal.MustEncodeToBytesWithType(sig)
+}
+
+// Raw --
+func (sig SignatureP256) Raw() []byte {
+	return sig[:]
+}
+
+// IsZero --
+func (sig SignatureP256) IsZero() bool {
+	return len(sig) == 0
+}
+
+// Equals --
+func (sig SignatureP256) Equals(other Signature) bool {
+	if otherSig, ok := other.(SignatureP256); ok {
+		return bytes.Equal(sig[:], otherSig[:])
+	}
+	return false
+}
+
+func makeSignatureP256(r, s *big.Int, v byte) Signature {
+	var ss SignatureP256
+	rb := r.Bytes()
+	sb := s.Bytes()
+
+	copy(ss[32-len(rb):32], rb)
+	copy(ss[64-len(sb):64], sb)
+	ss[64] = P256Magic + v
+	return ss
+}
+
+// ParseSignatureP256 --
+func ParseSignatureP256(sig []byte) (r, s *big.Int, v byte, ok bool) {
+	if len(sig) != SignatureP256Size || (sig[64] != P256Magic && sig[64] != P256Magic+1) {
+		return nil, nil, 0, false
+	}
+
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:64])
+	v = sig[64] - P256Magic
+	return r, s, v, true
+}
+
+// NewSignatureP256 --
+func NewSignatureP256(data []byte) (Signature, bool) {
+	if len(data) != SignatureP256Size {
+		return SignatureP256{}, false
+	}
+	var sig SignatureP256
+	copy(sig[:], data)
+	return sig, true
+}
@@ -0,0 +1,250 @@
+package crypto
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto/secp256k1"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// The secp256k1 and gm signatures have three forms:
+//
+//	recoverable  [R || S || V], 65 bytes, the form of the Signature types
+//	compact      [R || S], 64 bytes
+//	DER          SEQUENCE { r INTEGER, s INTEGER } of SEC 1 and GM/T 0009
+//
+// The compact and DER forms have no recovery id, so it is found again from the
+// signed hash and the public key.
+//
+// Secp256k1 signatures are only valid with a low S, S <= N/2, and (R, N-S)
+// is the same signature, so the other S is normalized when converting.
+// SM2 signatures are not malleable this way and are converted as they are.
+// Note that the SM2 signatures of this package sign the SM3 hash of the ZA of
+// an empty ID without the public key, followed by the message, which outside
+// verifiers have to use too.
+
+var (
+	// ErrInvalidDERSignature is returned when a signature is not strict DER.
+	ErrInvalidDERSignature = errors.New("invalid DER signature")
+	// ErrInvalidSignatureValues is returned when R or S is not in [1, N-1].
+	ErrInvalidSignatureValues = errors.New("invalid signature values")
+	// ErrSignatureNotRecoverable is returned when no recovery id gives the
+	// public key of a signature.
+	ErrSignatureNotRecoverable = errors.New("signature does not recover the public key")
+)
+
+// MarshalDERSignature returns the DER encoding of the signature (r, s).
+func MarshalDERSignature(r, s *big.Int) []byte {
+	rb, sb := derInteger(r), derInteger(s)
+	out := make([]byte, 0, 6+len(rb)+len(sb))
+	out = append(out, 0x30, byte(4+len(rb)+len(sb)))
+	out = append(out, 0x02, byte(len(rb)))
+	out = append(out, rb...)
+	out = append(out, 0x02, byte(len(sb)))
+	return append(out, sb...)
+}
+
+// derInteger returns the minimal big endian two's complement encoding of a
+// positive integer.
+func derInteger(x *big.Int) []byte {
+	b := x.Bytes()
+	if len(b) == 0 || b[0]&0x80 != 0 {
+		b = append([]byte{0x00}, b...)
+	}
+	return b
+}
+
+// ParseDERSignature parses a signature of a curve of at most 256 bits in
+// strict DER: minimal lengths and integers, positive integers and no trailing
+// data, like BIP66.
+func ParseDERSignature(der []byte) (r, s *big.Int, err error) {
+	// 0x30 len 0x02 rlen r 0x02 slen s, r and s take 1 to 33 bytes.
+	if len(der) < 8 || len(der) > 72 || der[0] != 0x30 || int(der[1]) != len(der)-2 {
+		return nil, nil, ErrInvalidDERSignature
+	}
+	rest := der[2:]
+	ints := make([]*big.Int, 2)
+	for i := range ints {
+		if len(rest) < 3 || rest[0] != 0x02 {
+			return nil, nil, ErrInvalidDERSignature
+		}
+		n := int(rest[1])
+		if n == 0 || n > 33 || n > len(rest)-2 {
+			return nil, nil, ErrInvalidDERSignature
+		}
+		b := rest[2 : 2+n]
+		if b[0]&0x80 != 0 {
+			// negative
+			return nil, nil, ErrInvalidDERSignature
+		}
+		if n > 1 && b[0] == 0x00 && b[1]&0x80 == 0 {
+			// not minimal
+			return nil, nil, ErrInvalidDERSignature
+		}
+		ints[i] = new(big.Int).SetBytes(b)
+		rest = rest[2+n:]
+	}
+	if len(rest) != 0 {
+		return nil, nil, ErrInvalidDERSignature
+	}
+	return ints[0], ints[1], nil
+}
+
+func checkSignatureValues(r, s, N *big.Int) error {
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(N) >= 0 || s.Cmp(N) >= 0 {
+		return ErrInvalidSignatureValues
+	}
+	return nil
+}
+
+// parseCompactSignature splits a 64 bytes [R || S] signature.
+func parseCompactSignature(compact []byte) (r, s *big.Int, err error) {
+	if len(compact) != 64 {
+		return nil, nil, errors.New("invalid compact signature length, need 64 bytes")
+	}
+	return new(big.Int).SetBytes(compact[:32]), new(big.Int).SetBytes(compact[32:]), nil
+}
+
+func compactSignature(r, s *big.Int) []byte {
+	out := make([]byte, 64)
+	math.ReadBits(r, out[:32])
+	math.ReadBits(s, out[32:])
+	return out
+}
+
+//-------------------------------------
+
+// Compact returns the 64 bytes [R || S] form of sig, or nil if sig is not a
+// 65 bytes signature.
+func (sig SignatureSecp256k1) Compact() []byte {
+	if len(sig) != 65 {
+		return nil
+	}
+	return append([]byte{}, sig[:64]...)
+}
+
+// DER returns the DER form of sig, or nil if sig is not a 65 bytes signature.
+func (sig SignatureSecp256k1) DER() []byte {
+	if len(sig) != 65 {
+		return nil
+	}
+	return MarshalDERSignature(new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]))
+}
+
+// IsLowS reports whether S <= N/2, which is required by VerifyBytes. It is
+// false if sig is not a 65 bytes signature.
+func (sig SignatureSecp256k1) IsLowS() bool {
+	if len(sig) != 65 {
+		return false
+	}
+	return new(big.Int).SetBytes(sig[32:64]).Cmp(secp256k1halfN) <= 0
+}
+
+// Normalize returns sig with a low S and the matching recovery id, or nil if
+// sig is not a 65 bytes signature.
+func (sig SignatureSecp256k1) Normalize() SignatureSecp256k1 {
+	if len(sig) != 65 {
+		return nil
+	}
+	out := append(SignatureSecp256k1{}, sig...)
+	if !sig.IsLowS() {
+		s := new(big.Int).SetBytes(sig[32:64])
+		math.ReadBits(s.Sub(secp256k1N, s), out[32:64])
+		out[64] ^= 1
+	}
+	return out
+}
+
+// SignatureSecp256k1FromCompact returns the recoverable signature of the 64
+// bytes [R || S] signature compact of hash by pubKey. A high S is normalized.
+func SignatureSecp256k1FromCompact(compact, hash []byte, pubKey PubKey) (SignatureSecp256k1, error) {
+	r, s, err := parseCompactSignature(compact)
+	if err != nil {
+		return nil, err
+	}
+	return makeRecoverableSecp256k1(r, s, hash, pubKey)
+}
+
+// SignatureSecp256k1FromDER returns the recoverable signature of the DER
+// signature der of hash by pubKey. A high S is normalized.
+func SignatureSecp256k1FromDER(der, hash []byte, pubKey PubKey) (SignatureSecp256k1, error) {
+	r, s, err := ParseDERSignature(der)
+	if err != nil {
+		return nil, err
+	}
+	return makeRecoverableSecp256k1(r, s, hash, pubKey)
+}
+
+func makeRecoverableSecp256k1(r, s *big.Int, hash []byte, pubKey PubKey) (SignatureSecp256k1, error) {
+	if err := checkSignatureValues(r, s, secp256k1N); err != nil {
+		return nil, err
+	}
+	if s.Cmp(secp256k1halfN) > 0 {
+		s = new(big.Int).Sub(secp256k1N, s)
+	}
+	sig := make(SignatureSecp256k1, 65)
+	copy(sig, compactSignature(r, s))
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		raw, err := secp256k1.RecoverPubkey(hash, sig)
+		if err == nil && pubKey.Equals(&PubKeySecp256k1{Data: raw}) {
+			return sig, nil
+		}
+	}
+	return nil, ErrSignatureNotRecoverable
+}
+
+//-------------------------------------
+
+// Compact returns the 64 bytes [R || S] form of sig, or nil for the zero
+// value.
+func (sig SignatureGM) Compact() []byte {
+	if sig == (SignatureGM{}) {
+		return nil
+	}
+	return append([]byte{}, sig[:64]...)
+}
+
+// DER returns the DER form of sig, the SM2Signature of GM/T 0009, or nil for
+// the zero value.
+func (sig SignatureGM) DER() []byte {
+	if sig == (SignatureGM{}) {
+		return nil
+	}
+	return MarshalDERSignature(new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]))
+}
+
+// SignatureGMFromCompact returns the recoverable signature of the 64 bytes
+// [R || S] signature compact of msg by pubKey.
+func SignatureGMFromCompact(compact, msg []byte, pubKey PubKey) (SignatureGM, error) {
+	r, s, err := parseCompactSignature(compact)
+	if err != nil {
+		return SignatureGM{}, err
+	}
+	return makeRecoverableGM(r, s, msg, pubKey)
+}
+
+// SignatureGMFromDER returns the recoverable signature of the DER signature
+// der of msg by pubKey.
+func SignatureGMFromDER(der, msg []byte, pubKey PubKey) (SignatureGM, error) {
+	r, s, err := ParseDERSignature(der)
+	if err != nil {
+		return SignatureGM{}, err
+	}
+	return makeRecoverableGM(r, s, msg, pubKey)
+}
+
+func makeRecoverableGM(r, s *big.Int, msg []byte, pubKey PubKey) (SignatureGM, error) {
+	if err := checkSignatureValues(r, s, sm2.P256Sm2().Params().N); err != nil {
+		return SignatureGM{}, err
+	}
+	for v := int64(0); v < 2; v++ {
+		pk, ok := sm2.Ecrecover(msg, r, s, big.NewInt(v))
+		if ok && pubKey.Equals(makePubKeyGM(pk)) {
+			return makeSignatureGM(r, s, big.NewInt(v)).(SignatureGM), nil
+		}
+	}
+	return SignatureGM{}, ErrSignatureNotRecoverable
+}
@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureSecp256k1OpenSSL(t *testing.T) {
+	privKey, err := ParsePrivKeyPEM([]byte(opensslKeys[1].privKey), nil)
+	require.Nil(t, err)
+	pubKey := privKey.PubKey()
+	// openssl pkeyutl -sign of the SHA-256 of "tc-libs DER signature".
+	hash := mustHex(t, "c251fb50622e085d38edc8ba0b1453a0822a1aa725aef15804562766adbc38b9")
+	for _, der := range []string{
+		"3046022100af5cde8357d19056a2a2aef1e90b91f0b4141aedbc7f26e3965d4c606f2d397a022100ca094ec19a2c8f305f1a9499f93f59db9001da0bfc16bbf88c2a52e1fe177c47",
+		"3045022100b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30022043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118",
+	} {
+		sig, err := SignatureSecp256k1FromDER(mustHex(t, der), hash, pubKey)
+		require.Nil(t, err, der)
+		assert.True(t, sig.IsLowS())
+		assert.True(t, pubKey.VerifyBytes(hash, sig))
+		recovered, err := Ecrecover(hash, sig)
+		require.Nil(t, err)
+		assert.Equal(t, pubKey.Raw(), recovered)
+
+		// The high S signature comes back normalized.
+		r, s, err := ParseDERSignature(sig.DER())
+		require.Nil(t, err)
+		r0, s0, err := ParseDERSignature(mustHex(t, der))
+		require.Nil(t, err)
+		assert.Equal(t, r0, r)
+		assert.True(t, s.Cmp(s0) == 0 || s.Cmp(new(big.Int).Sub(secp256k1N, s0)) == 0)
+	}
+}
+
+func TestSignatureSecp256k1Forms(t *testing.T) {
+	privKey, err := GenPrivKeySecp256k1()
+	require.Nil(t, err)
+	for i := 0; i < 20; i++ {
+		hash := Keccak256(CRandBytes(32))
+		signed, err := privKey.Sign(hash)
+		require.Nil(t, err)
+		sig := signed.(SignatureSecp256k1)
+
+		fromCompact, err := SignatureSecp256k1FromCompact(sig.Compact(), hash, privKey.PubKey())
+		require.Nil(t, err)
+		assert.Equal(t, sig, fromCompact)
+		fromDER, err := SignatureSecp256k1FromDER(sig.DER(), hash, privKey.PubKey())
+		require.Nil(t, err)
+		assert.Equal(t, sig, fromDER)
+
+		high := append(SignatureSecp256k1{}, sig...)
+		s := new(big.Int).SetBytes(sig[32:64])
+		copy(high[32:64], math.PaddedBigBytes(new(big.Int).Sub(secp256k1N, s), 32))
+		high[64] ^= 1
+		assert.False(t, high.IsLowS())
+		assert.False(t, privKey.PubKey().VerifyBytes(hash, high))
+		assert.Equal(t, sig, high.Normalize())
+		fromHigh, err := SignatureSecp256k1FromCompact(high.Compact(), hash, privKey.PubKey())
+		require.Nil(t, err)
+		assert.Equal(t, sig, fromHigh)
+	}
+}
+
+func TestSignatureGMForms(t *testing.T) {
+	privKey, err := GenPrivKeyGM()
+	require.Nil(t, err)
+	other, err := GenPrivKeyGM()
+	require.Nil(t, err)
+	for i := 0; i < 10; i++ {
+		msg := CRandBytes(40)
+		signed, err := privKey.Sign(msg)
+		require.Nil(t, err)
+		sig := signed.(SignatureGM)
+
+		fromCompact, err := SignatureGMFromCompact(sig.Compact(), msg, privKey.PubKey())
+		require.Nil(t, err)
+		assert.Equal(t, sig, fromCompact)
+		fromDER, err := SignatureGMFromDER(sig.DER(), msg, privKey.PubKey())
+		require.Nil(t, err)
+		assert.Equal(t, sig, fromDER)
+		assert.True(t, privKey.PubKey().VerifyBytes(msg, fromDER))
+
+		_, err = SignatureGMFromDER(sig.DER(), msg, other.PubKey())
+		assert.Equal(t, ErrSignatureNotRecoverable, err)
+	}
+}
+
+func TestParseDERSignatureStrict(t *testing.T) {
+	valid := "3045022100b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30022043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118"
+	r, s, err := ParseDERSignature(mustHex(t, valid))
+	require.Nil(t, err)
+	assert.Equal(t, valid, hex.EncodeToString(MarshalDERSignature(r, s)))
+
+	for _, der := range []string{
+		"",
+		// trailing byte
+		valid + "00",
+		// wrong sequence length
+		"3044022100b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30022043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118",
+		// long form length
+		"308145022100b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30022043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118",
+		// r not minimal
+		"304602220000b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30022043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118",
+		// r negative
+		"30440220b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30022043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118",
+		// s is not an integer
+		"3045022100b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa30042043146c0e2070a92bff4fdc399818b70a55ba87c12ad963403ef238885d6b6118",
+		// empty s
+		"3025022100b7a2c5fbb2729c67868eeea3b0a85328fc44acd24a2fbbbd410a0841a68eaa300200",
+	} {
+		_, _, err := ParseDERSignature(mustHex(t, der))
+		assert.Equal(t, ErrInvalidDERSignature, err, der)
+	}
+
+	privKey, err := GenPrivKeySecp256k1()
+	require.Nil(t, err)
+	hash := Keccak256([]byte("zero"))
+	_, err = SignatureSecp256k1FromDER(MarshalDERSignature(big.NewInt(0), big.NewInt(1)), hash, privKey.PubKey())
+	assert.Equal(t, ErrInvalidSignatureValues, err)
+	_, err = SignatureSecp256k1FromCompact(make([]byte, 63), hash, privKey.PubKey())
+	assert.NotNil(t, err)
+}
+
+func TestSignatureFormsMalformed(t *testing.T) {
+	for _, sig := range []SignatureSecp256k1{nil, {}, make(SignatureSecp256k1, 64)} {
+		assert.Nil(t, sig.Compact())
+		assert.Nil(t, sig.DER())
+		assert.False(t, sig.IsLowS())
+		assert.Nil(t, sig.Normalize())
+	}
+	var sig SignatureGM
+	assert.Nil(t, sig.Compact())
+	assert.Nil(t, sig.DER())
+}
@@ -73,6 +73,29 @@ func TestSigAndValidateGM(t *testing.T) {
 	}
 }
 
+func TestSigAndValidateP256(t *testing.T) {
+	privKey, err := GenPrivKeyP256()
+	if err != nil {
+		t.Fatalf("GenPrivKeyP256 fail: %s", err)
+	}
+	pubKey := privKey.PubKey()
+
+	msg := CRandBytes(32)
+	sig, err := privKey.Sign(msg)
+	require.Nil(t, err)
+
+	assert.True(t, pubKey.VerifyBytes(msg, sig))
+	assert.True(t, VerifySignature(pubKey.Raw(), msg, sig.Raw()))
+
+	// Mutate the signature, just one bit.
+	sigP256 := sig.(SignatureP256)
+	sigP256[3] ^= byte(0x01)
+	sig = sigP256
+
+	assert.False(t, pubKey.VerifyBytes(msg, sig))
+	assert.False(t, VerifySignature(pubKey.Raw(), msg, sig.Raw()))
+}
+
 var (
 	testmsg     = hexutil.MustDecode("0xce0677bb30baa8cf067c88db9811f4333d131bf8bcf12fe7065d211dce971008")
 	testsig     = hexutil.MustDecode("0x90f27b8b488db00b00606796d2987f6a5f59ae62ea05effe84fef5b8b0e549984a691139ad57a3f0b906637673aa2f63d1f55cb1a69199d4009eea23ceaddc9301")
@@ -0,0 +1,64 @@
+package sss
+
+// Arithmetic in GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+// The multiplication does not branch nor index tables on its operands, so the
+// shares of a secret byte do not leak through timing.
+
+func gfAdd(a, b byte) byte {
+	return a ^ b
+}
+
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= a & -(b & 1)
+		// reduce by the polynomial when the high bit is shifted out
+		a = a<<1 ^ 0x1b&-(a>>7)
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns a^-1 = a^254, and 0 for 0.
+func gfInv(a byte) byte {
+	b := gfMul(a, a) // a^2
+	c := gfMul(a, b) // a^3
+	b = gfMul(c, c)  // a^6
+	b = gfMul(b, b)  // a^12
+	c = gfMul(b, c)  // a^15
+	b = gfMul(b, b)  // a^24
+	b = gfMul(b, b)  // a^48
+	b = gfMul(b, c)  // a^63
+	b = gfMul(b, b)  // a^126
+	b = gfMul(a, b)  // a^127
+	return gfMul(b, b)
+}
+
+// gfEval returns the value at x of the polynomial of coefficients coeffs,
+// lowest degree first.
+func gfEval(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfAdd(gfMul(y, x), coeffs[i])
+	}
+	return y
+}
+
+// gfLagrangeAtZero returns the Lagrange coefficients at 0 of the points xs,
+// which must be distinct and non zero.
+func gfLagrangeAtZero(xs []byte) []byte {
+	ls := make([]byte, len(xs))
+	for i, xi := range xs {
+		num, den := byte(1), byte(1)
+		for j, xj := range xs {
+			if i == j {
+				continue
+			}
+			// (0 - xj) / (xi - xj), subtraction is addition
+			num = gfMul(num, xj)
+			den = gfMul(den, gfAdd(xi, xj))
+		}
+		ls[i] = gfMul(num, gfInv(den))
+	}
+	return ls
+}
@@ -0,0 +1,260 @@
+package sss
+
+import (
+	"bytes"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+	"github.com/bwesterb/go-ristretto"
+)
+
+// Pedersen's verifiable secret sharing over ristretto255. The raw key is cut
+// in chunks of 31 bytes, each a scalar a0 shared with its own polynomial
+//
+//	f(x) = a0 + a1*x + ... + a(k-1)*x^(k-1)
+//
+// along with a random blinding polynomial g(x) = b0 + b1*x + ..., and the
+// coefficients are committed to as Cm = am*B + bm*H, where H is a generator
+// of unknown discrete log to B. The share (f(i), g(i)) of index i is checked
+// with
+//
+//	f(i)*B + g(i)*H == C0 + i*C1 + ... + i^(k-1)*C(k-1)
+//
+// The commitments are hiding: a0*B alone, as in Feldman's scheme, would be a
+// deterministic function of the key bytes and could be checked against any
+// guess of a chunk, like a last chunk holding a single byte of the key.
+
+// chunkSize is the number of bytes of the key in a scalar, 31 bytes are
+// always less than the group order.
+const chunkSize = 31
+
+// shareChunkSize is the size of the share of a chunk, f(i) || g(i).
+const shareChunkSize = 64
+
+// blindingBase is the generator H of the blinding polynomials, derived from a
+// fixed string so that nobody knows its discrete log to B.
+var blindingBase = new(ristretto.Point).DeriveDalek([]byte("tc-libs sss pedersen blinding base"))
+
+func chunkCount(size int) int {
+	return (size + chunkSize - 1) / chunkSize
+}
+
+// Commitment is the Pedersen commitment to a verifiable split, it is public
+// and is given to every custodian with its share.
+type Commitment struct {
+	KeyType   string
+	Threshold uint8
+	Size      uint16
+	// Points are the commitments to the coefficients of the polynomials of
+	// the chunks, Threshold points per chunk, lowest degree first.
+	Points [][32]byte
+}
+
+// Bytes returns the bal encoding of the commitment.
+func (c *Commitment) Bytes() []byte {
+	return bal.MustEncodeToBytes(c)
+}
+
+// CommitmentFromBytes decodes a commitment encoded by Bytes.
+func CommitmentFromBytes(bz []byte) (*Commitment, error) {
+	c := new(Commitment)
+	if err := bal.DecodeBytes(bz, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SplitVerifiable splits privKey into n shares like Split, any k of which give
+// it back, and returns the commitment to check them with.
+func SplitVerifiable(privKey crypto.PrivKey, n, k int) ([]*Share, *Commitment, error) {
+	if err := checkThreshold(n, k); err != nil {
+		return nil, nil, err
+	}
+	raw, err := rawKey(privKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chunks := chunkCount(len(raw))
+	commitment := &Commitment{
+		KeyType:   privKey.Type(),
+		Threshold: uint8(k),
+		Size:      uint16(len(raw)),
+		Points:    make([][32]byte, chunks*k),
+	}
+	shares := make([]*Share, n)
+	xs := make([]ristretto.Scalar, n)
+	for i := range shares {
+		shares[i] = &Share{
+			KeyType:    privKey.Type(),
+			Threshold:  uint8(k),
+			Index:      uint8(i + 1),
+			Verifiable: true,
+			Size:       uint16(len(raw)),
+			Data:       make([]byte, chunks*shareChunkSize),
+		}
+		xs[i].Set(indexScalar(shares[i].Index))
+	}
+
+	coeffs := make([]ristretto.Scalar, k)
+	blinding := make([]ristretto.Scalar, k)
+	defer func() {
+		for m := range coeffs {
+			coeffs[m].SetZero()
+			blinding[m].SetZero()
+		}
+	}()
+	var buf [32]byte
+	defer zero(buf[:])
+	for c := 0; c < chunks; c++ {
+		zero(buf[:])
+		copy(buf[:chunkSize], raw[c*chunkSize:])
+		coeffs[0].SetBytes(&buf)
+		for m := 1; m < k; m++ {
+			coeffs[m].Rand()
+		}
+		for m := range blinding {
+			blinding[m].Rand()
+		}
+
+		var C, D ristretto.Point
+		for m := range coeffs {
+			C.ScalarMultBase(&coeffs[m])
+			C.Add(&C, D.ScalarMult(blindingBase, &blinding[m]))
+			C.BytesInto(&commitment.Points[c*k+m])
+		}
+
+		for i, share := range shares {
+			data := share.Data[c*shareChunkSize:]
+			y := evalPoly(coeffs, &xs[i])
+			y.BytesInto(&buf)
+			copy(data, buf[:])
+			y.SetZero()
+			evalPoly(blinding, &xs[i]).BytesInto(&buf)
+			copy(data[32:], buf[:])
+		}
+	}
+	return shares, commitment, nil
+}
+
+// evalPoly returns the value of the polynomial of coeffs at x.
+func evalPoly(coeffs []ristretto.Scalar, x *ristretto.Scalar) *ristretto.Scalar {
+	y := new(ristretto.Scalar).Set(&coeffs[len(coeffs)-1])
+	for m := len(coeffs) - 2; m >= 0; m-- {
+		y.MulAdd(y, x, &coeffs[m])
+	}
+	return y
+}
+
+// Verify checks share against the commitment of its split.
+func (share *Share) Verify(c *Commitment) error {
+	if err := share.check(); err != nil {
+		return err
+	}
+	if !share.Verifiable || share.KeyType != c.KeyType || share.Threshold != c.Threshold || share.Size != c.Size {
+		return ErrShareMismatch
+	}
+	k := int(c.Threshold)
+	if len(c.Points) != chunkCount(int(c.Size))*k {
+		return ErrInvalidShare
+	}
+
+	var x ristretto.Scalar
+	x.Set(indexScalar(share.Index))
+	for chunk := 0; chunk < len(c.Points)/k; chunk++ {
+		y, ok := shareScalar(share, chunk*shareChunkSize)
+		if !ok {
+			return ErrInvalidShare
+		}
+		z, ok := shareScalar(share, chunk*shareChunkSize+32)
+		if !ok {
+			return ErrInvalidShare
+		}
+		var lhs, rhs, C ristretto.Point
+		lhs.PublicScalarMultBase(y)
+		lhs.Add(&lhs, C.PublicScalarMult(blindingBase, z))
+
+		// Horner's rule on the commitments
+		rhs.SetZero()
+		for m := k - 1; m >= 0; m-- {
+			if !C.SetBytes(&c.Points[chunk*k+m]) {
+				return ErrInvalidShare
+			}
+			rhs.PublicScalarMult(&rhs, &x)
+			rhs.Add(&rhs, &C)
+		}
+		if !lhs.Equals(&rhs) {
+			return ErrInvalidShare
+		}
+	}
+	return nil
+}
+
+// indexScalar returns the index i of a share as a scalar.
+func indexScalar(i uint8) *ristretto.Scalar {
+	var buf [32]byte
+	buf[0] = i
+	return new(ristretto.Scalar).SetBytes(&buf)
+}
+
+// shareScalar returns the scalar at offset in the data of share, if it is
+// canonical.
+func shareScalar(share *Share, offset int) (*ristretto.Scalar, bool) {
+	var buf [32]byte
+	copy(buf[:], share.Data[offset:])
+	y := new(ristretto.Scalar).SetBytes(&buf)
+	return y, bytes.Equal(y.Bytes(), buf[:])
+}
+
+func combineScalars(shares []*Share) ([]byte, error) {
+	// Lagrange coefficients at 0: prod xj / (xj - xi)
+	ls := make([]ristretto.Scalar, len(shares))
+	for i, si := range shares {
+		var xi, num, den, xj, d ristretto.Scalar
+		xi.Set(indexScalar(si.Index))
+		num.SetOne()
+		den.SetOne()
+		for j, sj := range shares {
+			if i == j {
+				continue
+			}
+			xj.Set(indexScalar(sj.Index))
+			num.Mul(&num, &xj)
+			den.Mul(&den, d.Sub(&xj, &xi))
+		}
+		ls[i].Mul(&num, den.Inverse(&den))
+	}
+
+	size := int(shares[0].Size)
+	raw := make([]byte, chunkCount(size)*chunkSize)
+	var a0, t ristretto.Scalar
+	var buf [32]byte
+	defer zero(buf[:])
+	for c := 0; c < chunkCount(size); c++ {
+		a0.SetZero()
+		for i, share := range shares {
+			y, ok := shareScalar(share, c*shareChunkSize)
+			if !ok {
+				return nil, ErrInvalidShare
+			}
+			a0.Add(&a0, t.Mul(&ls[i], y))
+			y.SetZero()
+		}
+		a0.BytesInto(&buf)
+		if buf[chunkSize] != 0 {
+			zero(raw)
+			return nil, ErrInvalidShare
+		}
+		copy(raw[c*chunkSize:], buf[:chunkSize])
+	}
+	a0.SetZero()
+	t.SetZero()
+
+	for _, b := range raw[size:] {
+		if b != 0 {
+			zero(raw)
+			return nil, ErrInvalidShare
+		}
+	}
+	return raw[:size], nil
+}
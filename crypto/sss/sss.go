@@ -0,0 +1,213 @@
+// Package sss splits private keys into shares with Shamir's secret sharing,
+// so that any threshold of them gives the key back and fewer tell nothing
+// about it:
+//
+//	shares, err := sss.Split(privKey, 5, 3)
+//	privKey, err := sss.Combine(shares[1:4])
+//
+// Each byte of the raw key is shared over GF(256). With SplitVerifiable the
+// key is shared over the scalars of ristretto255 instead, along with hiding
+// Pedersen commitments to the polynomials, and each custodian can check its
+// share with Share.Verify.
+package sss
+
+import (
+	"errors"
+
+	"github.com/XunleiBlockchain/tc-libs/bal"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+)
+
+// MaxShares is the maximum number of shares of a key.
+const MaxShares = 255
+
+var (
+	// ErrInvalidThreshold is returned when the threshold is not in [2, n] or
+	// n is more than MaxShares.
+	ErrInvalidThreshold = errors.New("threshold must be at least 2 and at most the number of shares")
+	// ErrNotEnoughShares is returned when less shares than the threshold are
+	// combined.
+	ErrNotEnoughShares = errors.New("not enough shares")
+	// ErrShareMismatch is returned when shares of different splits are
+	// combined, or a share is checked against the commitment of another split.
+	ErrShareMismatch = errors.New("shares do not belong to the same split")
+	// ErrDuplicateShare is returned when two shares have the same index.
+	ErrDuplicateShare = errors.New("duplicate share")
+	// ErrInvalidShare is returned when a share is malformed or does not match
+	// its commitment.
+	ErrInvalidShare = errors.New("invalid share")
+)
+
+// Share is the share of a private key held by a custodian.
+type Share struct {
+	// KeyType is the type of the shared key, see crypto.PrivKey.Type.
+	KeyType   string
+	Threshold uint8
+	// Index is the point of the share, in [1, 255].
+	Index uint8
+	// Verifiable is set on the shares of SplitVerifiable.
+	Verifiable bool
+	// Size is the length of the raw key.
+	Size uint16
+	// Data is the share of each byte of the raw key, or of each chunk of it
+	// and of its blinding polynomial for a verifiable share.
+	Data []byte
+}
+
+// Bytes returns the bal encoding of the share.
+func (share *Share) Bytes() []byte {
+	return bal.MustEncodeToBytes(share)
+}
+
+// ShareFromBytes decodes a share encoded by Bytes.
+func ShareFromBytes(bz []byte) (*Share, error) {
+	share := new(Share)
+	if err := bal.DecodeBytes(bz, share); err != nil {
+		return nil, err
+	}
+	if err := share.check(); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// Reset zeroes the share data.
+func (share *Share) Reset() {
+	for i := range share.Data {
+		share.Data[i] = 0
+	}
+}
+
+func (share *Share) dataSize() int {
+	if share.Verifiable {
+		return chunkCount(int(share.Size)) * shareChunkSize
+	}
+	return int(share.Size)
+}
+
+func (share *Share) check() error {
+	if share.Index == 0 || share.Threshold < 2 || share.Size == 0 || len(share.Data) != share.dataSize() {
+		return ErrInvalidShare
+	}
+	return nil
+}
+
+// sameSplit reports whether share and other can be combined.
+func (share *Share) sameSplit(other *Share) bool {
+	return share.KeyType == other.KeyType && share.Threshold == other.Threshold &&
+		share.Verifiable == other.Verifiable && share.Size == other.Size
+}
+
+func checkThreshold(n, k int) error {
+	if k < 2 || k > n || n > MaxShares {
+		return ErrInvalidThreshold
+	}
+	return nil
+}
+
+func rawKey(privKey crypto.PrivKey) ([]byte, error) {
+	raw := privKey.Raw()
+	if len(raw) == 0 || len(raw) > 0xffff {
+		return nil, errors.New("invalid raw private key length")
+	}
+	return raw, nil
+}
+
+// Split splits privKey into n shares, any k of which give it back.
+func Split(privKey crypto.PrivKey, n, k int) ([]*Share, error) {
+	if err := checkThreshold(n, k); err != nil {
+		return nil, err
+	}
+	raw, err := rawKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]*Share, n)
+	for i := range shares {
+		shares[i] = &Share{
+			KeyType:   privKey.Type(),
+			Threshold: uint8(k),
+			Index:     uint8(i + 1),
+			Size:      uint16(len(raw)),
+			Data:      make([]byte, len(raw)),
+		}
+	}
+
+	coeffs := make([]byte, k)
+	defer zero(coeffs)
+	for j, b := range raw {
+		coeffs[0] = b
+		copy(coeffs[1:], crypto.CRandBytes(k-1))
+		for _, share := range shares {
+			share.Data[j] = gfEval(coeffs, share.Index)
+		}
+	}
+	return shares, nil
+}
+
+// Combine returns the private key of the shares, it needs at least the
+// threshold of shares of the same split and only uses the first of them.
+func Combine(shares []*Share) (crypto.PrivKey, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+	first := shares[0]
+	if err := first.check(); err != nil {
+		return nil, err
+	}
+	if len(shares) < int(first.Threshold) {
+		return nil, ErrNotEnoughShares
+	}
+	shares = shares[:first.Threshold]
+
+	seen := make(map[uint8]bool, len(shares))
+	for _, share := range shares {
+		if err := share.check(); err != nil {
+			return nil, err
+		}
+		if !share.sameSplit(first) {
+			return nil, ErrShareMismatch
+		}
+		if seen[share.Index] {
+			return nil, ErrDuplicateShare
+		}
+		seen[share.Index] = true
+	}
+
+	var raw []byte
+	if first.Verifiable {
+		var err error
+		if raw, err = combineScalars(shares); err != nil {
+			return nil, err
+		}
+	} else {
+		raw = combineBytes(shares)
+	}
+	defer zero(raw)
+	return crypto.PrivKeyFromRaw(raw, first.KeyType)
+}
+
+func combineBytes(shares []*Share) []byte {
+	xs := make([]byte, len(shares))
+	for i, share := range shares {
+		xs[i] = share.Index
+	}
+	ls := gfLagrangeAtZero(xs)
+
+	raw := make([]byte, shares[0].Size)
+	for j := range raw {
+		var b byte
+		for i, share := range shares {
+			b = gfAdd(b, gfMul(ls[i], share.Data[j]))
+		}
+		raw[j] = b
+	}
+	return raw
+}
+
+func zero(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
@@ -0,0 +1,172 @@
+package sss
+
+import (
+	"testing"
+
+	"github.com/XunleiBlockchain/tc-libs/crypto"
+	"github.com/bwesterb/go-ristretto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGF256(t *testing.T) {
+	// FIPS 197 section 4.2
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+	assert.Equal(t, byte(0xfe), gfMul(0x57, 0x13))
+	assert.Equal(t, byte(0), gfInv(0))
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))), a)
+	}
+}
+
+func genKeys(t *testing.T) []crypto.PrivKey {
+	var keys []crypto.PrivKey
+	for _, name := range crypto.Algorithms() {
+		privKey, err := crypto.GeneratePrivKeyFromSecret(crypto.CRandBytes(32), name)
+		require.Nil(t, err, name)
+		keys = append(keys, privKey)
+	}
+	return keys
+}
+
+// subsets returns the subsets of k of the n first indexes.
+func subsets(n, k int) [][]int {
+	if k == 0 {
+		return [][]int{nil}
+	}
+	var out [][]int
+	for i := k - 1; i < n; i++ {
+		for _, s := range subsets(i, k-1) {
+			out = append(out, append(s, i))
+		}
+	}
+	return out
+}
+
+func pick(shares []*Share, idx []int) []*Share {
+	out := make([]*Share, len(idx))
+	for i, j := range idx {
+		out[i] = shares[j]
+	}
+	return out
+}
+
+func TestSplitCombine(t *testing.T) {
+	for _, privKey := range genKeys(t) {
+		shares, err := Split(privKey, 5, 3)
+		require.Nil(t, err, privKey.Type())
+		require.Len(t, shares, 5)
+		for _, idx := range subsets(5, 3) {
+			combined, err := Combine(pick(shares, idx))
+			require.Nil(t, err, privKey.Type())
+			assert.Equal(t, privKey.Type(), combined.Type())
+			assert.True(t, privKey.Equals(combined), privKey.Type())
+		}
+
+		// more shares than needed
+		combined, err := Combine(shares)
+		require.Nil(t, err)
+		assert.True(t, privKey.Equals(combined))
+
+		// the threshold minus one shares do not give the key
+		_, err = Combine(shares[:2])
+		assert.Equal(t, ErrNotEnoughShares, err)
+	}
+}
+
+func TestSplitVerifiable(t *testing.T) {
+	for _, privKey := range genKeys(t) {
+		shares, commitment, err := SplitVerifiable(privKey, 4, 2)
+		require.Nil(t, err, privKey.Type())
+		commitment, err = CommitmentFromBytes(commitment.Bytes())
+		require.Nil(t, err)
+		for _, share := range shares {
+			assert.Nil(t, share.Verify(commitment), privKey.Type())
+		}
+		for _, idx := range subsets(4, 2) {
+			combined, err := Combine(pick(shares, idx))
+			require.Nil(t, err, privKey.Type())
+			assert.True(t, privKey.Equals(combined), privKey.Type())
+		}
+
+		// a corrupted share is caught by its custodian
+		bad := *shares[0]
+		bad.Data = append([]byte{}, bad.Data...)
+		bad.Data[0] ^= 1
+		assert.Equal(t, ErrInvalidShare, bad.Verify(commitment))
+		bad.Index = 2
+		bad.Data = shares[0].Data
+		assert.Equal(t, ErrInvalidShare, bad.Verify(commitment))
+
+		// and so is a forged commitment
+		forged := *commitment
+		forged.Points = append([][32]byte{}, commitment.Points...)
+		forged.Points[1] = forged.Points[0]
+		assert.Equal(t, ErrInvalidShare, shares[0].Verify(&forged))
+	}
+}
+
+func TestSplitVerifiableHiding(t *testing.T) {
+	privKey, err := crypto.GenPrivKeySecp256k1()
+	require.Nil(t, err)
+	raw := privKey.Raw()
+
+	// the second chunk holds the last byte of the key alone, its commitment
+	// does not give it away
+	_, c1, err := SplitVerifiable(privKey, 3, 2)
+	require.Nil(t, err)
+	_, c2, err := SplitVerifiable(privKey, 3, 2)
+	require.Nil(t, err)
+	require.Len(t, c1.Points, 4)
+	var buf [32]byte
+	buf[0] = raw[31]
+	var s ristretto.Scalar
+	var P ristretto.Point
+	P.ScalarMultBase(s.SetBytes(&buf))
+	assert.NotEqual(t, P.Bytes(), c1.Points[2][:])
+	assert.NotEqual(t, c1.Points[0], c2.Points[0])
+	assert.NotEqual(t, c1.Points[2], c2.Points[2])
+}
+
+func TestShareErrors(t *testing.T) {
+	privKey, err := crypto.GenPrivKeySecp256k1()
+	require.Nil(t, err)
+
+	for _, nk := range [][2]int{{3, 1}, {3, 4}, {256, 2}, {0, 0}} {
+		_, err = Split(privKey, nk[0], nk[1])
+		assert.Equal(t, ErrInvalidThreshold, err)
+		_, _, err = SplitVerifiable(privKey, nk[0], nk[1])
+		assert.Equal(t, ErrInvalidThreshold, err)
+	}
+
+	shares, err := Split(privKey, 3, 2)
+	require.Nil(t, err)
+	decoded, err := ShareFromBytes(shares[1].Bytes())
+	require.Nil(t, err)
+	assert.Equal(t, shares[1], decoded)
+
+	_, err = Combine(nil)
+	assert.Equal(t, ErrNotEnoughShares, err)
+	_, err = Combine([]*Share{shares[0], shares[0]})
+	assert.Equal(t, ErrDuplicateShare, err)
+
+	other, err := crypto.GenPrivKeyGM()
+	require.Nil(t, err)
+	otherShares, err := Split(other, 3, 2)
+	require.Nil(t, err)
+	_, err = Combine([]*Share{shares[0], otherShares[1]})
+	assert.Equal(t, ErrShareMismatch, err)
+
+	verifiable, commitment, err := SplitVerifiable(privKey, 3, 2)
+	require.Nil(t, err)
+	_, err = Combine([]*Share{shares[0], verifiable[1]})
+	assert.Equal(t, ErrShareMismatch, err)
+	assert.Equal(t, ErrShareMismatch, shares[0].Verify(commitment))
+
+	truncated := *shares[0]
+	truncated.Data = truncated.Data[1:]
+	_, err = Combine([]*Share{&truncated, shares[1]})
+	assert.Equal(t, ErrInvalidShare, err)
+	_, err = ShareFromBytes(truncated.Bytes())
+	assert.Equal(t, ErrInvalidShare, err)
+}
@@ -0,0 +1,211 @@
+package crypto
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/bwesterb/go-ristretto"
+	"github.com/bwesterb/go-ristretto/edwards25519"
+)
+
+// ECVRF-EDWARDS25519-SHA512-TAI verifiable random function of RFC 9381, on
+// ed25519 node keys. The proof pi of an input alpha is made with the private
+// key, and anyone knowing the public key can check it and get the output
+// beta, which is unique for a key and an input:
+//
+//	beta, pi, err := crypto.VRFProve(privKey, alpha)
+//	beta, ok := crypto.VRFVerify(privKey.PubKey(), alpha, pi)
+
+const (
+	// VRFProofSize is the size of a proof, [Gamma || c || s].
+	VRFProofSize = 80
+	// VRFOutputSize is the size of the output beta.
+	VRFOutputSize = 64
+
+	vrfSuite = 0x03
+	vrfCLen  = 16
+)
+
+// vrfEncodeToCurve hashes alpha to a point of the prime order subgroup with
+// the try and increment method.
+func vrfEncodeToCurve(pubKey, alpha []byte) (*edwards25519.ExtendedPoint, bool) {
+	var h edwards25519.ExtendedPoint
+	var hash [64]byte
+	var buf [32]byte
+	for ctr := 0; ctr < 256; ctr++ {
+		d := sha512.New()
+		d.Write([]byte{vrfSuite, 0x01})
+		d.Write(pubKey)
+		d.Write(alpha)
+		d.Write([]byte{byte(ctr), 0x00})
+		d.Sum(hash[:0])
+		copy(buf[:], hash[:32])
+		if decodeEdPointCanonical(&h, &buf) {
+			vrfClearCofactor(&h)
+			return &h, true
+		}
+	}
+	return nil, false
+}
+
+func vrfClearCofactor(p *edwards25519.ExtendedPoint) {
+	p.Double(p)
+	p.Double(p)
+	p.Double(p)
+}
+
+func vrfChallenge(points ...*edwards25519.ExtendedPoint) []byte {
+	var buf [32]byte
+	var hash [64]byte
+	d := sha512.New()
+	d.Write([]byte{vrfSuite, 0x02})
+	for _, p := range points {
+		encodeEdPoint(&buf, p)
+		d.Write(buf[:])
+	}
+	d.Write([]byte{0x00})
+	d.Sum(hash[:0])
+	return hash[:vrfCLen]
+}
+
+func vrfProofToHash(gamma *edwards25519.ExtendedPoint) []byte {
+	var g edwards25519.ExtendedPoint
+	g.Set(gamma)
+	vrfClearCofactor(&g)
+	var buf [32]byte
+	encodeEdPoint(&buf, &g)
+	d := sha512.New()
+	d.Write([]byte{vrfSuite, 0x03})
+	d.Write(buf[:])
+	d.Write([]byte{0x00})
+	return d.Sum(nil)
+}
+
+// reduceScalar sets s to the little endian buf mod l.
+func reduceScalar(s *ristretto.Scalar, buf []byte) *ristretto.Scalar {
+	var wide [64]byte
+	copy(wide[:], buf)
+	return s.SetReduced(&wide)
+}
+
+// VRFProve returns the output and the proof of alpha, only ed25519 keys are
+// supported.
+func VRFProve(privKey PrivKey, alpha []byte) (beta, pi []byte, err error) {
+	edKey, ok := privKey.(PrivKeyEd25519)
+	if !ok {
+		return nil, nil, fmt.Errorf("vrf: unsupported private key type %s", privKey.Type())
+	}
+	pubKey := edKey[32:]
+
+	// x is the secret scalar of RFC 8032, the nonce prefix is the second half
+	// of the same hash.
+	digest := sha512.Sum512(edKey[:32])
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+	var x ristretto.Scalar
+	reduceScalar(&x, digest[:32])
+
+	H, ok := vrfEncodeToCurve(pubKey, alpha)
+	if !ok {
+		return nil, nil, fmt.Errorf("vrf: failed to hash to curve")
+	}
+	var hBuf [32]byte
+	encodeEdPoint(&hBuf, H)
+
+	var xBuf [32]byte
+	x.BytesInto(&xBuf)
+	var gamma, Y edwards25519.ExtendedPoint
+	gamma.ScalarMult(H, &xBuf)
+	Y.ScalarMult(&edBase, &xBuf)
+
+	var kHash [64]byte
+	d := sha512.New()
+	d.Write(digest[32:])
+	d.Write(hBuf[:])
+	d.Sum(kHash[:0])
+	var k ristretto.Scalar
+	k.SetReduced(&kHash)
+	var kBuf [32]byte
+	k.BytesInto(&kBuf)
+
+	var U, V edwards25519.ExtendedPoint
+	U.ScalarMult(&edBase, &kBuf)
+	V.ScalarMult(H, &kBuf)
+
+	cBytes := vrfChallenge(&Y, H, &gamma, &U, &V)
+	var c, s ristretto.Scalar
+	reduceScalar(&c, cBytes)
+	s.MulAdd(&c, &x, &k)
+
+	var gBuf, sBuf [32]byte
+	encodeEdPoint(&gBuf, &gamma)
+	s.BytesInto(&sBuf)
+	pi = make([]byte, 0, VRFProofSize)
+	pi = append(pi, gBuf[:]...)
+	pi = append(pi, cBytes...)
+	pi = append(pi, sBuf[:]...)
+	return vrfProofToHash(&gamma), pi, nil
+}
+
+// VRFVerify checks the proof pi of alpha and returns the output beta, only
+// ed25519 keys are supported.
+func VRFVerify(pubKey PubKey, alpha, pi []byte) (beta []byte, ok bool) {
+	edKey, isEd := pubKey.(PubKeyEd25519)
+	if !isEd || len(pi) != VRFProofSize {
+		return nil, false
+	}
+
+	var Y, gamma edwards25519.ExtendedPoint
+	if !decodeEdPointCanonical(&Y, (*[32]byte)(&edKey)) || isSmallOrderEdPoint(&Y) {
+		return nil, false
+	}
+	var gBuf, sBuf [32]byte
+	copy(gBuf[:], pi[:32])
+	copy(sBuf[:], pi[48:])
+	if !decodeEdPointCanonical(&gamma, &gBuf) || !scalarIsCanonical(&sBuf) {
+		return nil, false
+	}
+	var c, s ristretto.Scalar
+	reduceScalar(&c, pi[32:48])
+	s.SetBytes(&sBuf)
+
+	H, ok := vrfEncodeToCurve(edKey[:], alpha)
+	if !ok {
+		return nil, false
+	}
+
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	var negC ristretto.Scalar
+	negC.Neg(&c)
+	var U, V edwards25519.ExtendedPoint
+	edVarTimeMultiScalarMult(&U, []*edwards25519.ExtendedPoint{&edBase, &Y}, []ristretto.Scalar{s, negC})
+	edVarTimeMultiScalarMult(&V, []*edwards25519.ExtendedPoint{H, &gamma}, []ristretto.Scalar{s, negC})
+
+	if subtle.ConstantTimeCompare(vrfChallenge(&Y, H, &gamma, &U, &V), pi[32:48]) != 1 {
+		return nil, false
+	}
+	return vrfProofToHash(&gamma), true
+}
+
+// encodeEdPoint encodes p in the RFC 8032 format.
+func encodeEdPoint(buf *[32]byte, p *edwards25519.ExtendedPoint) {
+	var zInv, x, y edwards25519.FieldElement
+	zInv.Inverse(&p.Z)
+	x.Mul(&p.X, &zInv)
+	y.Mul(&p.Y, &zInv)
+	y.BytesInto(buf)
+	buf[31] |= byte(x.IsNegativeI() << 7)
+}
+
+// decodeEdPointCanonical is decodeEdPoint which also rejects y >= p.
+func decodeEdPointCanonical(p *edwards25519.ExtendedPoint, buf *[32]byte) bool {
+	if !decodeEdPoint(p, buf) {
+		return false
+	}
+	var enc [32]byte
+	p.Y.BytesInto(&enc)
+	enc[31] |= buf[31] & 0x80
+	return enc == *buf
+}
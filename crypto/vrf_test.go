@@ -0,0 +1,106 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 9381 appendix B.3, ECVRF-EDWARDS25519-SHA512-TAI.
+var vrfTestVectors = []struct {
+	sk, pk, alpha, pi, beta string
+}{
+	{
+		sk:    "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pk:    "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		alpha: "",
+		pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+	{
+		sk:    "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+		pk:    "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+		alpha: "72",
+		pi:    "f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+		beta:  "eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+	},
+	{
+		sk:    "c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+		pk:    "fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+		alpha: "af82",
+		pi:    "9bc0f79119cc5604bf02d23b4caede71393cedfbb191434dd016d30177ccbf8096bb474e53895c362d8628ee9f9ea3c0e52c7a5c691b6c18c9979866568add7a2d41b00b05081ed0f58ee5e31b3a970e",
+		beta:  "645427e5d00c62a23fb703732fa5d892940935942101e456ecca7bb217c61c452118fec1219202a0edcf038bb6373241578be7217ba85a2687f7a0310b2df19f",
+	},
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	require.Nil(t, err)
+	return bz
+}
+
+func TestVRFTestVectors(t *testing.T) {
+	for i, tv := range vrfTestVectors {
+		var privKey PrivKeyEd25519
+		copy(privKey[:32], mustHex(t, tv.sk))
+		copy(privKey[32:], mustHex(t, tv.pk))
+		pubKey := privKey.PubKey()
+		assert.Equal(t, tv.pk, hex.EncodeToString(pubKey.Raw()), "vector %d", i)
+
+		alpha := mustHex(t, tv.alpha)
+		beta, pi, err := VRFProve(privKey, alpha)
+		require.Nil(t, err)
+		assert.Equal(t, tv.pi, hex.EncodeToString(pi), "vector %d", i)
+		assert.Equal(t, tv.beta, hex.EncodeToString(beta), "vector %d", i)
+
+		beta, ok := VRFVerify(pubKey, alpha, mustHex(t, tv.pi))
+		assert.True(t, ok, "vector %d", i)
+		assert.Equal(t, tv.beta, hex.EncodeToString(beta), "vector %d", i)
+	}
+}
+
+func TestVRFProveVerify(t *testing.T) {
+	privKey, err := GenerateNodeKey()
+	require.Nil(t, err)
+	pubKey := privKey.PubKey()
+	alpha := []byte("round 42")
+
+	beta, pi, err := VRFProve(privKey, alpha)
+	require.Nil(t, err)
+	assert.Len(t, pi, VRFProofSize)
+	assert.Len(t, beta, VRFOutputSize)
+
+	beta2, ok := VRFVerify(pubKey, alpha, pi)
+	assert.True(t, ok)
+	assert.Equal(t, beta, beta2)
+
+	// Proving again gives the same output.
+	beta3, pi3, err := VRFProve(privKey, alpha)
+	require.Nil(t, err)
+	assert.Equal(t, beta, beta3)
+	assert.Equal(t, pi, pi3)
+
+	_, ok = VRFVerify(pubKey, []byte("round 43"), pi)
+	assert.False(t, ok)
+	otherKey, err := GenPrivKeyEd25519()
+	require.Nil(t, err)
+	_, ok = VRFVerify(otherKey.PubKey(), alpha, pi)
+	assert.False(t, ok)
+	for _, i := range []int{0, 40, 70} {
+		bad := append([]byte(nil), pi...)
+		bad[i] ^= 0x01
+		_, ok = VRFVerify(pubKey, alpha, bad)
+		assert.False(t, ok, "byte %d", i)
+	}
+	_, ok = VRFVerify(pubKey, alpha, pi[:VRFProofSize-1])
+	assert.False(t, ok)
+
+	secpKey, err := GenPrivKeySecp256k1()
+	require.Nil(t, err)
+	_, _, err = VRFProve(secpKey, alpha)
+	assert.NotNil(t, err)
+	_, ok = VRFVerify(secpKey.PubKey(), alpha, pi)
+	assert.False(t, ok)
+}
@@ -8,7 +8,7 @@ import (
 
 	"github.com/XunleiBlockchain/tc-libs/bal"
 	"github.com/XunleiBlockchain/tc-libs/common"
-	"golang.org/x/crypto/sha3"
+	"github.com/XunleiBlockchain/tc-libs/crypto"
 )
 
 var (
@@ -42,8 +42,9 @@ type STDSigner interface {
 
 var big8 = big.NewInt(8)
 
+// BalHash returns the local hash of the bal encoding of x.
 func BalHash(x interface{}) (h common.Hash) {
-	hw := sha3.NewLegacyKeccak256()
+	hw := crypto.LocalHashSuite().New()
 	bal.Encode(hw, x)
 	hw.Sum(h[:0])
 	return h